@@ -0,0 +1,2 @@
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS rentals_field_period_excl;
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS rentals_period_check;
@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE "rentals"
+ADD CONSTRAINT rentals_period_check
+CHECK (end_date > start_date) NOT VALID;
+
+ALTER TABLE "rentals"
+ADD CONSTRAINT rentals_field_period_excl
+EXCLUDE USING gist (
+    field_id WITH =,
+    tstzrange(start_date, end_date, '[)') WITH &&
+) WHERE (deleted_at IS NULL);
+
+COMMENT ON CONSTRAINT rentals_field_period_excl ON "rentals" IS 'Запрет пересечения аренд одной площадки';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS field_schedule_exceptions;
+DROP TABLE IF EXISTS field_schedules;
@@ -0,0 +1,46 @@
+CREATE TABLE "field_schedules" (
+    "id" bigserial PRIMARY KEY,
+    "field_id" INT NOT NULL,
+    "weekday" smallint NOT NULL,
+    "open_time" time NOT NULL,
+    "close_time" time NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (field_id) REFERENCES fields(id) ON DELETE CASCADE,
+    CHECK (weekday BETWEEN 1 AND 7),
+    CHECK (close_time > open_time)
+);
+
+CREATE UNIQUE INDEX unique_field_schedules_weekday ON field_schedules (field_id, weekday);
+
+COMMENT ON COLUMN "field_schedules"."field_id" IS 'Идентификатор площадки';
+COMMENT ON COLUMN "field_schedules"."weekday" IS 'День недели (1 - понедельник, 7 - воскресенье)';
+COMMENT ON COLUMN "field_schedules"."open_time" IS 'Время открытия';
+COMMENT ON COLUMN "field_schedules"."close_time" IS 'Время закрытия';
+COMMENT ON COLUMN "field_schedules"."created_at" IS 'Дата создания';
+COMMENT ON COLUMN "field_schedules"."updated_at" IS 'Дата изменения';
+
+CREATE TABLE "field_schedule_exceptions" (
+    "id" bigserial PRIMARY KEY,
+    "field_id" INT NOT NULL,
+    "date" date NOT NULL,
+    "is_closed" bool NOT NULL DEFAULT true,
+    "open_time" time,
+    "close_time" time,
+    "comment" varchar,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (field_id) REFERENCES fields(id) ON DELETE CASCADE,
+    CHECK (is_closed OR (open_time IS NOT NULL AND close_time IS NOT NULL AND close_time > open_time))
+);
+
+CREATE UNIQUE INDEX unique_field_schedule_exceptions_date ON field_schedule_exceptions (field_id, date);
+
+COMMENT ON COLUMN "field_schedule_exceptions"."field_id" IS 'Идентификатор площадки';
+COMMENT ON COLUMN "field_schedule_exceptions"."date" IS 'Дата исключения';
+COMMENT ON COLUMN "field_schedule_exceptions"."is_closed" IS 'Площадка закрыта весь день';
+COMMENT ON COLUMN "field_schedule_exceptions"."open_time" IS 'Время открытия';
+COMMENT ON COLUMN "field_schedule_exceptions"."close_time" IS 'Время закрытия';
+COMMENT ON COLUMN "field_schedule_exceptions"."comment" IS 'Комментарий (праздник, обслуживание)';
+COMMENT ON COLUMN "field_schedule_exceptions"."created_at" IS 'Дата создания';
+COMMENT ON COLUMN "field_schedule_exceptions"."updated_at" IS 'Дата изменения';
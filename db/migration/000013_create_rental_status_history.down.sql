@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS rental_status_history;
+
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS rentals_field_period_excl;
+ALTER TABLE "rentals"
+ADD CONSTRAINT rentals_field_period_excl
+EXCLUDE USING gist (
+    field_id WITH =,
+    tstzrange(start_date, end_date, '[)') WITH &&
+) WHERE (deleted_at IS NULL);
+
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS rentals_status_check;
+COMMENT ON COLUMN "rentals"."status" IS 'Статус аренды';
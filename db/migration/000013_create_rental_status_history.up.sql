@@ -0,0 +1,40 @@
+UPDATE "rentals" SET "status" = 1 WHERE "status" NOT BETWEEN 1 AND 6;
+
+ALTER TABLE "rentals"
+ADD CONSTRAINT rentals_status_check
+CHECK (status BETWEEN 1 AND 6);
+
+COMMENT ON COLUMN "rentals"."status" IS 'Статус аренды (1 - ожидает, 2 - подтверждена, 3 - отклонена, 4 - завершена, 5 - отменена, 6 - неявка)';
+
+-- Отклоненные и отмененные аренды не занимают площадку
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS rentals_field_period_excl;
+ALTER TABLE "rentals"
+ADD CONSTRAINT rentals_field_period_excl
+EXCLUDE USING gist (
+    field_id WITH =,
+    tstzrange(start_date, end_date, '[)') WITH &&
+) WHERE (deleted_at IS NULL AND status NOT IN (3, 5));
+
+CREATE TABLE "rental_status_history" (
+    "id" bigserial PRIMARY KEY,
+    "rental_id" INT NOT NULL,
+    "from_status" smallint,
+    "to_status" smallint NOT NULL,
+    "user_id" INT,
+    "comment" varchar,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (rental_id) REFERENCES rentals(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rental_status_history_rental ON rental_status_history (rental_id);
+
+COMMENT ON COLUMN "rental_status_history"."rental_id" IS 'Идентификатор аренды';
+COMMENT ON COLUMN "rental_status_history"."from_status" IS 'Предыдущий статус';
+COMMENT ON COLUMN "rental_status_history"."to_status" IS 'Новый статус';
+COMMENT ON COLUMN "rental_status_history"."user_id" IS 'Пользователь, изменивший статус';
+COMMENT ON COLUMN "rental_status_history"."comment" IS 'Комментарий';
+COMMENT ON COLUMN "rental_status_history"."created_at" IS 'Дата изменения статуса';
+
+INSERT INTO rental_status_history (rental_id, from_status, to_status, user_id, created_at)
+SELECT id, NULL, status, user_id, created_at FROM rentals;
@@ -0,0 +1,2 @@
+ALTER TABLE "rentals" DROP COLUMN "series_id";
+DROP TABLE IF EXISTS rental_series;
@@ -0,0 +1,35 @@
+CREATE TABLE "rental_series" (
+    "id" bigserial PRIMARY KEY,
+    "field_id" INT,
+    "team_id" INT,
+    "user_id" INT,
+    "frequency" varchar NOT NULL,
+    "until" timestamptz,
+    "count" INT,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    "deleted_at" timestamptz,
+    FOREIGN KEY (field_id) REFERENCES fields(id),
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    CHECK (frequency IN ('weekly', 'biweekly'))
+);
+
+COMMENT ON COLUMN "rental_series"."field_id" IS 'Идентификатор площадки';
+COMMENT ON COLUMN "rental_series"."team_id" IS 'Идентификатор команды';
+COMMENT ON COLUMN "rental_series"."user_id" IS 'Идентификатор пользователя';
+COMMENT ON COLUMN "rental_series"."frequency" IS 'Частота повторения (weekly, biweekly)';
+COMMENT ON COLUMN "rental_series"."until" IS 'Дата окончания серии';
+COMMENT ON COLUMN "rental_series"."count" IS 'Количество повторений';
+COMMENT ON COLUMN "rental_series"."created_at" IS 'Дата создания';
+COMMENT ON COLUMN "rental_series"."updated_at" IS 'Дата изменения';
+COMMENT ON COLUMN "rental_series"."deleted_at" IS 'Дата удаления';
+
+ALTER TABLE "rentals" ADD COLUMN "series_id" INT;
+COMMENT ON COLUMN "rentals"."series_id" IS 'Серия повторяющихся аренд';
+
+ALTER TABLE "rentals"
+ADD CONSTRAINT fk_rentals_series
+FOREIGN KEY (series_id) REFERENCES rental_series(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_rentals_series ON rentals (series_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_fields_lat_lon;
+
+ALTER TABLE "fields" DROP CONSTRAINT IF EXISTS "fields_coordinates_check";
+ALTER TABLE "fields" DROP COLUMN IF EXISTS "lon";
+ALTER TABLE "fields" DROP COLUMN IF EXISTS "lat";
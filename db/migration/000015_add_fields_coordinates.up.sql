@@ -0,0 +1,20 @@
+ALTER TABLE "fields" ADD COLUMN "lat" double precision;
+ALTER TABLE "fields" ADD COLUMN "lon" double precision;
+
+ALTER TABLE "fields" ADD CONSTRAINT "fields_coordinates_check" CHECK (
+    (lat IS NULL AND lon IS NULL)
+    OR (lat BETWEEN -90 AND 90 AND lon BETWEEN -180 AND 180)
+);
+
+-- Переносим координаты, сохраненные в location в виде {"lat": ..., "lon": ...}
+UPDATE "fields"
+SET lat = (location ->> 'lat')::double precision,
+    lon = (location ->> 'lon')::double precision
+WHERE jsonb_typeof(location) = 'object'
+  AND location ->> 'lat' ~ '^-?[0-9]+(\.[0-9]+)?$'
+  AND location ->> 'lon' ~ '^-?[0-9]+(\.[0-9]+)?$';
+
+CREATE INDEX IF NOT EXISTS idx_fields_lat_lon ON fields (lat, lon) WHERE lat IS NOT NULL AND lon IS NOT NULL;
+
+COMMENT ON COLUMN "fields"."lat" IS 'Широта';
+COMMENT ON COLUMN "fields"."lon" IS 'Долгота';
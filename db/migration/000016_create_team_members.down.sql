@@ -0,0 +1,7 @@
+ALTER TABLE "teams" ADD COLUMN "participant_count" INT;
+COMMENT ON COLUMN "teams"."participant_count" IS 'Кол-во участников';
+
+UPDATE "teams" t SET participant_count = (SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id);
+
+DROP TABLE IF EXISTS team_invitations;
+DROP TABLE IF EXISTS team_members;
@@ -0,0 +1,68 @@
+CREATE TABLE "team_members" (
+    "id" bigserial PRIMARY KEY,
+    "team_id" INT NOT NULL,
+    "user_id" INT NOT NULL,
+    "role" varchar NOT NULL DEFAULT 'player',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (role IN ('captain', 'player', 'reserve'))
+);
+
+CREATE UNIQUE INDEX unique_team_members_user ON team_members (team_id, user_id);
+-- В команде может быть только один капитан
+CREATE UNIQUE INDEX unique_team_members_captain ON team_members (team_id) WHERE role = 'captain';
+CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members (user_id);
+
+COMMENT ON COLUMN "team_members"."team_id" IS 'Идентификатор команды';
+COMMENT ON COLUMN "team_members"."user_id" IS 'Идентификатор пользователя';
+COMMENT ON COLUMN "team_members"."role" IS 'Роль в команде (captain, player, reserve)';
+COMMENT ON COLUMN "team_members"."created_at" IS 'Дата вступления';
+COMMENT ON COLUMN "team_members"."updated_at" IS 'Дата изменения';
+
+-- Ответственный за команду становится её капитаном
+INSERT INTO team_members (team_id, user_id, role, created_at)
+SELECT id, responsible_id, 'captain', created_at FROM teams WHERE responsible_id IS NOT NULL;
+
+CREATE TABLE "team_invitations" (
+    "id" bigserial PRIMARY KEY,
+    "team_id" INT NOT NULL,
+    "email" varchar,
+    "phone" varchar,
+    "role" varchar NOT NULL DEFAULT 'player',
+    "status" smallint NOT NULL DEFAULT 1,
+    "invited_by" INT,
+    "user_id" INT,
+    "expires_at" timestamptz NOT NULL,
+    "responded_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (email IS NOT NULL OR phone IS NOT NULL),
+    CHECK (role IN ('player', 'reserve')),
+    CHECK (status BETWEEN 1 AND 5)
+);
+
+-- Повторное приглашение возможно только после ответа на предыдущее
+CREATE UNIQUE INDEX unique_team_invitations_email ON team_invitations (team_id, LOWER(email)) WHERE status = 1 AND email IS NOT NULL;
+CREATE UNIQUE INDEX unique_team_invitations_phone ON team_invitations (team_id, phone) WHERE status = 1 AND phone IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_team_invitations_email ON team_invitations (LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_team_invitations_phone ON team_invitations (phone);
+
+COMMENT ON COLUMN "team_invitations"."team_id" IS 'Идентификатор команды';
+COMMENT ON COLUMN "team_invitations"."email" IS 'Email приглашенного';
+COMMENT ON COLUMN "team_invitations"."phone" IS 'Телефон приглашенного';
+COMMENT ON COLUMN "team_invitations"."role" IS 'Роль в команде после принятия приглашения';
+COMMENT ON COLUMN "team_invitations"."status" IS 'Статус (1 - ожидает, 2 - принято, 3 - отклонено, 4 - истекло, 5 - отозвано)';
+COMMENT ON COLUMN "team_invitations"."invited_by" IS 'Пригласивший пользователь';
+COMMENT ON COLUMN "team_invitations"."user_id" IS 'Пользователь, ответивший на приглашение';
+COMMENT ON COLUMN "team_invitations"."expires_at" IS 'Срок действия приглашения';
+COMMENT ON COLUMN "team_invitations"."responded_at" IS 'Дата ответа';
+COMMENT ON COLUMN "team_invitations"."created_at" IS 'Дата создания';
+COMMENT ON COLUMN "team_invitations"."updated_at" IS 'Дата изменения';
+
+-- Количество участников вычисляется по составу команды
+ALTER TABLE "teams" DROP COLUMN "participant_count";
@@ -0,0 +1,27 @@
+CREATE TABLE "refresh_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "family_id" varchar NOT NULL,
+    "token_hash" varchar NOT NULL,
+    "user_agent" varchar,
+    "ip" varchar,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX unique_refresh_tokens_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+COMMENT ON COLUMN "refresh_tokens"."user_id" IS 'Идентификатор пользователя';
+COMMENT ON COLUMN "refresh_tokens"."family_id" IS 'Идентификатор сессии, общий для всех токенов цепочки ротации';
+COMMENT ON COLUMN "refresh_tokens"."token_hash" IS 'SHA-256 хеш токена';
+COMMENT ON COLUMN "refresh_tokens"."user_agent" IS 'User-Agent устройства';
+COMMENT ON COLUMN "refresh_tokens"."ip" IS 'IP-адрес клиента';
+COMMENT ON COLUMN "refresh_tokens"."expires_at" IS 'Срок действия';
+COMMENT ON COLUMN "refresh_tokens"."used_at" IS 'Дата обмена на новую пару токенов';
+COMMENT ON COLUMN "refresh_tokens"."revoked_at" IS 'Дата отзыва';
+COMMENT ON COLUMN "refresh_tokens"."created_at" IS 'Дата создания';
@@ -0,0 +1,60 @@
+CREATE TABLE "permissions" (
+    "id" serial PRIMARY KEY,
+    "code" varchar NOT NULL,
+    "description" varchar NOT NULL
+);
+
+CREATE UNIQUE INDEX unique_permissions_code ON permissions (code);
+
+COMMENT ON COLUMN "permissions"."code" IS 'Код права';
+COMMENT ON COLUMN "permissions"."description" IS 'Описание';
+
+CREATE TABLE "role_permissions" (
+    "role_id" INT NOT NULL,
+    "permission_id" INT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+COMMENT ON COLUMN "role_permissions"."role_id" IS 'Идентификатор роли';
+COMMENT ON COLUMN "role_permissions"."permission_id" IS 'Идентификатор права';
+
+INSERT INTO permissions (code, description) VALUES
+    ('fields.create', 'Создание площадок'),
+    ('fields.update.own', 'Редактирование своих площадок'),
+    ('fields.update.any', 'Редактирование любых площадок'),
+    ('fields.delete.any', 'Удаление площадок'),
+    ('rentals.create', 'Бронирование площадок'),
+    ('rentals.approve.own', 'Управление арендами своих площадок'),
+    ('rentals.approve.any', 'Управление арендами любых площадок'),
+    ('rentals.cancel.own', 'Отмена своих аренд'),
+    ('rentals.delete.own', 'Удаление своих аренд'),
+    ('rentals.delete.any', 'Удаление любых аренд'),
+    ('teams.create', 'Создание команд'),
+    ('teams.update.own', 'Редактирование своих команд и их состава'),
+    ('teams.update.any', 'Редактирование любых команд и их состава'),
+    ('teams.delete.own', 'Удаление своих команд'),
+    ('teams.delete.any', 'Удаление любых команд'),
+    ('users.view', 'Просмотр пользователей'),
+    ('users.manage', 'Управление пользователями и назначение ролей'),
+    ('roles.manage', 'Управление правами ролей');
+
+-- Клиент и капитан команды работают только со своими объектами
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id IN (1, 2) AND p.code IN (
+    'fields.create', 'fields.update.own',
+    'rentals.create', 'rentals.approve.own', 'rentals.cancel.own', 'rentals.delete.own',
+    'teams.create', 'teams.update.own', 'teams.delete.own'
+);
+
+-- Администратор получает все права, кроме управления правами ролей
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id = 11 AND p.code <> 'roles.manage';
+
+-- Супер-администратор получает все права
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id = 10;
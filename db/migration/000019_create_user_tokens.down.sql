@@ -0,0 +1,5 @@
+ALTER TABLE users ALTER COLUMN status SET DEFAULT 1;
+
+COMMENT ON COLUMN "users"."status" IS 'Статус';
+
+DROP TABLE IF EXISTS user_tokens;
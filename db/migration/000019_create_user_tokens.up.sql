@@ -0,0 +1,29 @@
+CREATE TABLE "user_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "type" varchar NOT NULL,
+    "token_hash" varchar NOT NULL,
+    "email" varchar NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT user_tokens_type_check CHECK (type IN ('password_reset', 'email_verification'))
+);
+
+CREATE UNIQUE INDEX unique_user_tokens_hash ON user_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_type ON user_tokens (user_id, type);
+
+COMMENT ON COLUMN "user_tokens"."user_id" IS 'Идентификатор пользователя';
+COMMENT ON COLUMN "user_tokens"."type" IS 'Назначение токена: password_reset, email_verification';
+COMMENT ON COLUMN "user_tokens"."token_hash" IS 'SHA-256 хеш токена';
+COMMENT ON COLUMN "user_tokens"."email" IS 'Email, на который отправлен токен';
+COMMENT ON COLUMN "user_tokens"."expires_at" IS 'Срок действия';
+COMMENT ON COLUMN "user_tokens"."used_at" IS 'Дата использования';
+COMMENT ON COLUMN "user_tokens"."created_at" IS 'Дата создания';
+
+-- Новые пользователи не подтверждены, пока не перейдут по ссылке из письма.
+-- Уже зарегистрированные пользователи остаются в статусе 1 (подтвержден).
+ALTER TABLE users ALTER COLUMN status SET DEFAULT 0;
+
+COMMENT ON COLUMN "users"."status" IS 'Статус: 0 - email не подтвержден, 1 - подтвержден';
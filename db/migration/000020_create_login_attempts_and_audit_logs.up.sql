@@ -0,0 +1,32 @@
+CREATE TABLE "login_attempts" (
+    "key" varchar PRIMARY KEY,
+    "failures" INT NOT NULL DEFAULT 0,
+    "last_failure_at" timestamptz NOT NULL,
+    "locked_until" timestamptz
+);
+
+COMMENT ON COLUMN "login_attempts"."key" IS 'Счетчик: account:<email> или ip:<адрес>';
+COMMENT ON COLUMN "login_attempts"."failures" IS 'Количество неудачных попыток входа подряд';
+COMMENT ON COLUMN "login_attempts"."last_failure_at" IS 'Дата последней неудачной попытки';
+COMMENT ON COLUMN "login_attempts"."locked_until" IS 'До какого момента попытки входа запрещены';
+
+CREATE TABLE "audit_logs" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" INT,
+    "event" varchar NOT NULL,
+    "ip" varchar,
+    "user_agent" varchar,
+    "data" jsonb NOT NULL DEFAULT '{}'::jsonb,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_event_created_at ON audit_logs (event, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs (user_id);
+
+COMMENT ON COLUMN "audit_logs"."user_id" IS 'Пользователь, к которому относится событие';
+COMMENT ON COLUMN "audit_logs"."event" IS 'Событие';
+COMMENT ON COLUMN "audit_logs"."ip" IS 'IP-адрес клиента';
+COMMENT ON COLUMN "audit_logs"."user_agent" IS 'User-Agent клиента';
+COMMENT ON COLUMN "audit_logs"."data" IS 'Подробности события';
+COMMENT ON COLUMN "audit_logs"."created_at" IS 'Дата события';
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_rentals_deleted_at;
+DROP INDEX IF EXISTS idx_fields_deleted_at;
+DROP INDEX IF EXISTS idx_teams_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+DROP INDEX IF EXISTS unique_fields_slug;
+DROP INDEX IF EXISTS unique_fields_name;
+CREATE UNIQUE INDEX unique_fields_name ON fields (name, city);
+
+DROP INDEX IF EXISTS unique_team_name;
+CREATE UNIQUE INDEX unique_team_name ON teams (name, city);
+
+DROP INDEX IF EXISTS users_phone_unique;
+DROP INDEX IF EXISTS users_email_unique;
+CREATE UNIQUE INDEX users_phone_unique ON users (phone);
+CREATE UNIQUE INDEX users_email_unique ON users (email);
@@ -0,0 +1,19 @@
+-- Уникальность проверяется только среди неудаленных записей,
+-- чтобы email, телефон и названия удаленных записей можно было использовать повторно
+DROP INDEX IF EXISTS users_email_unique;
+DROP INDEX IF EXISTS users_phone_unique;
+CREATE UNIQUE INDEX users_email_unique ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_phone_unique ON users (phone) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS unique_team_name;
+CREATE UNIQUE INDEX unique_team_name ON teams (name, city) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS unique_fields_name;
+CREATE UNIQUE INDEX unique_fields_name ON fields (name, city) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX unique_fields_slug ON fields (slug) WHERE deleted_at IS NULL;
+
+-- Индексы для очистки удаленных записей
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_teams_deleted_at ON teams (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_fields_deleted_at ON fields (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rentals_deleted_at ON rentals (deleted_at) WHERE deleted_at IS NOT NULL;
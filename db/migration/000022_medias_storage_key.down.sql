@@ -0,0 +1,3 @@
+UPDATE medias SET path = 'public/upload/' || path WHERE path NOT LIKE '%/%';
+
+COMMENT ON COLUMN "medias"."path" IS 'Путь к файлу';
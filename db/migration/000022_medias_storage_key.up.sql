@@ -0,0 +1,5 @@
+-- В path хранится ключ файла в хранилище вместо пути на диске.
+-- Загруженные ранее файлы лежат в каталоге public/upload под своим именем.
+UPDATE medias SET path = name WHERE path LIKE '%public/upload/%';
+
+COMMENT ON COLUMN "medias"."path" IS 'Ключ файла в хранилище';
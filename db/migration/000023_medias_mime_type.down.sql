@@ -0,0 +1 @@
+ALTER TABLE medias DROP COLUMN IF EXISTS mime_type;
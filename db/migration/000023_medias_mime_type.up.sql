@@ -0,0 +1,20 @@
+ALTER TABLE medias ADD COLUMN mime_type varchar;
+
+-- Для загруженных ранее файлов тип определяется по расширению.
+-- Файлы других типов отдаются как двоичные данные.
+UPDATE medias SET mime_type = CASE lower(ext)
+    WHEN 'jpg' THEN 'image/jpeg'
+    WHEN 'jpeg' THEN 'image/jpeg'
+    WHEN 'png' THEN 'image/png'
+    WHEN 'gif' THEN 'image/gif'
+    WHEN 'webp' THEN 'image/webp'
+    WHEN 'mp4' THEN 'video/mp4'
+    WHEN 'webm' THEN 'video/webm'
+    WHEN 'mov' THEN 'video/quicktime'
+    ELSE 'application/octet-stream'
+END;
+
+ALTER TABLE medias ALTER COLUMN mime_type SET DEFAULT 'application/octet-stream';
+ALTER TABLE medias ALTER COLUMN mime_type SET NOT NULL;
+
+COMMENT ON COLUMN "medias"."mime_type" IS 'Тип содержимого';
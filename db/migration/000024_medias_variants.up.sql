@@ -0,0 +1,3 @@
+ALTER TABLE medias ADD COLUMN variants jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN "medias"."variants" IS 'Производные изображения: имя размера -> ключ файла, тип, ширина, высота, размер';
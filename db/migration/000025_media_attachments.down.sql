@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code IN ('media.delete.own', 'media.delete.any');
+DROP TABLE IF EXISTS media_attachments;
+ALTER TABLE medias DROP COLUMN IF EXISTS detached_at;
+ALTER TABLE medias DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,58 @@
+ALTER TABLE medias ADD COLUMN "user_id" bigint REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE medias ADD COLUMN "detached_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_medias_user_id ON medias (user_id);
+
+COMMENT ON COLUMN "medias"."user_id" IS 'Пользователь, загрузивший файл';
+COMMENT ON COLUMN "medias"."detached_at" IS 'Дата, когда файл перестал использоваться';
+
+CREATE TABLE "media_attachments" (
+    "id" bigserial PRIMARY KEY,
+    "media_id" bigint NOT NULL,
+    "entity_type" varchar NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "role" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    FOREIGN KEY (media_id) REFERENCES medias(id) ON DELETE CASCADE,
+    CHECK (entity_type IN ('field', 'team', 'user')),
+    CHECK (role IN ('logo', 'gallery'))
+);
+
+CREATE UNIQUE INDEX unique_media_attachments ON media_attachments (media_id, entity_type, entity_id, role);
+CREATE INDEX IF NOT EXISTS idx_media_attachments_entity ON media_attachments (entity_type, entity_id);
+
+COMMENT ON COLUMN "media_attachments"."media_id" IS 'Идентификатор медиафайла';
+COMMENT ON COLUMN "media_attachments"."entity_type" IS 'Тип объекта: field, team, user';
+COMMENT ON COLUMN "media_attachments"."entity_id" IS 'Идентификатор объекта';
+COMMENT ON COLUMN "media_attachments"."role" IS 'Назначение файла: logo, gallery';
+
+-- Привязки уже загруженных файлов восстанавливаются по логотипам и медиа площадок, команд и пользователей
+INSERT INTO media_attachments (media_id, entity_type, entity_id, role)
+SELECT m.id, a.entity_type, a.entity_id, a.role
+FROM (
+    SELECT 'field' AS entity_type, id AS entity_id, 'logo' AS role, logo AS name FROM fields WHERE logo IS NOT NULL
+    UNION ALL
+    SELECT 'field', id, 'gallery', jsonb_array_elements_text(media) FROM fields WHERE jsonb_typeof(media) = 'array'
+    UNION ALL
+    SELECT 'team', id, 'logo', logo FROM teams WHERE logo IS NOT NULL
+    UNION ALL
+    SELECT 'team', id, 'gallery', jsonb_array_elements_text(media) FROM teams WHERE jsonb_typeof(media) = 'array'
+    UNION ALL
+    SELECT 'user', id, 'logo', logo FROM users WHERE logo IS NOT NULL
+    UNION ALL
+    SELECT 'user', id, 'gallery', jsonb_array_elements_text(media) FROM users WHERE jsonb_typeof(media) = 'array'
+) a
+JOIN medias m ON m.name = a.name
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (code, description) VALUES
+    ('media.delete.own', 'Удаление своих медиафайлов'),
+    ('media.delete.any', 'Удаление любых медиафайлов');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id IN (1, 2) AND p.code = 'media.delete.own';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id IN (10, 11) AND p.code IN ('media.delete.own', 'media.delete.any');
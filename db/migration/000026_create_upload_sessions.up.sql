@@ -0,0 +1,36 @@
+CREATE TABLE "upload_sessions" (
+    "id" varchar PRIMARY KEY,
+    "user_id" bigint NOT NULL,
+    "usage" varchar NOT NULL,
+    "file_name" varchar NOT NULL,
+    "size" bigint NOT NULL,
+    "received" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    "expires_at" timestamptz NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (received <= size)
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions (expires_at);
+
+COMMENT ON COLUMN "upload_sessions"."user_id" IS 'Пользователь, загружающий файл';
+COMMENT ON COLUMN "upload_sessions"."usage" IS 'Назначение файла: logo, gallery';
+COMMENT ON COLUMN "upload_sessions"."file_name" IS 'Имя файла у клиента';
+COMMENT ON COLUMN "upload_sessions"."size" IS 'Размер файла';
+COMMENT ON COLUMN "upload_sessions"."received" IS 'Количество полученных байт';
+COMMENT ON COLUMN "upload_sessions"."expires_at" IS 'Дата, после которой незавершенная загрузка удаляется';
+
+CREATE TABLE "upload_chunks" (
+    "session_id" varchar NOT NULL,
+    "start" bigint NOT NULL,
+    "size" bigint NOT NULL,
+    "key" varchar NOT NULL,
+    PRIMARY KEY (session_id, start),
+    FOREIGN KEY (session_id) REFERENCES upload_sessions(id) ON DELETE CASCADE
+);
+
+COMMENT ON COLUMN "upload_chunks"."session_id" IS 'Идентификатор загрузки';
+COMMENT ON COLUMN "upload_chunks"."start" IS 'Смещение части от начала файла';
+COMMENT ON COLUMN "upload_chunks"."size" IS 'Размер части';
+COMMENT ON COLUMN "upload_chunks"."key" IS 'Ключ части в хранилище';
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN "status_before_block" smallint;
+
+COMMENT ON COLUMN "users"."status_before_block" IS 'Статус до блокировки, восстанавливается при разблокировке';
+
+-- Для уже заблокированных пользователей статус определяется по использованной ссылке подтверждения текущего email
+UPDATE users u SET status_before_block = CASE WHEN EXISTS (
+    SELECT 1 FROM user_tokens t
+    WHERE t.user_id = u.id AND t.type = 'email_verification' AND t.used_at IS NOT NULL AND t.email = u.email
+) THEN 1 ELSE 0 END
+WHERE u.status = 2;
@@ -0,0 +1,3 @@
+ALTER TABLE "upload_sessions" ADD COLUMN "completing" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "upload_sessions"."completing" IS 'Загрузку завершает или отменяет запрос, другие запросы её не меняют';
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE code IN ('media.attach.own', 'media.attach.any');
@@ -0,0 +1,11 @@
+INSERT INTO permissions (code, description) VALUES
+    ('media.attach.own', 'Привязка своих медиафайлов к площадкам и командам'),
+    ('media.attach.any', 'Привязка любых медиафайлов к площадкам и командам');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id IN (1, 2) AND p.code = 'media.attach.own';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.id IN (10, 11) AND p.code IN ('media.attach.own', 'media.attach.any');
@@ -0,0 +1,2 @@
+ALTER TABLE "upload_sessions" ADD COLUMN IF NOT EXISTS "completing" boolean NOT NULL DEFAULT false;
+ALTER TABLE "upload_sessions" DROP COLUMN IF EXISTS "completing_at";
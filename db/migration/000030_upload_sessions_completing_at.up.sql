@@ -0,0 +1,4 @@
+ALTER TABLE "upload_sessions" ADD COLUMN "completing_at" timestamptz;
+ALTER TABLE "upload_sessions" DROP COLUMN IF EXISTS "completing";
+
+COMMENT ON COLUMN "upload_sessions"."completing_at" IS 'Время, когда запрос начал завершать или отменять загрузку. Захват старше UPLOAD_COMPLETE_TIMEOUT считается снятым';
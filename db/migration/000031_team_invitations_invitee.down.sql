@@ -0,0 +1 @@
+ALTER TABLE "team_invitations" DROP COLUMN IF EXISTS "invitee_id";
@@ -0,0 +1,14 @@
+ALTER TABLE "team_invitations" ADD COLUMN "invitee_id" INT REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_team_invitations_invitee ON team_invitations (invitee_id);
+
+COMMENT ON COLUMN "team_invitations"."invitee_id" IS 'Приглашенный пользователь. Заполняется для приглашений по телефону: телефон не подтверждается, поэтому приглашение адресуется пользователю, найденному по телефону при создании';
+
+-- Приглашения по телефону адресуются пользователю, которому телефон принадлежит сейчас
+UPDATE team_invitations i SET invitee_id = u.id
+FROM users u
+WHERE i.email IS NULL AND i.phone = u.phone AND u.deleted_at IS NULL;
+
+-- Ожидающие приглашения на незарегистрированные телефоны принять некому
+UPDATE team_invitations SET status = 5, updated_at = now()
+WHERE status = 1 AND email IS NULL AND invitee_id IS NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE "login_attempts" ADD COLUMN "expires_at" timestamptz;
+
+-- Для существующих счетчиков берется наибольший срок обнуления
+UPDATE login_attempts SET expires_at = GREATEST(locked_until, last_failure_at + interval '24 hours');
+
+ALTER TABLE "login_attempts" ALTER COLUMN "expires_at" SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_expires_at ON login_attempts (expires_at);
+
+COMMENT ON COLUMN "login_attempts"."expires_at" IS 'Когда счетчик устаревает и удаляется: блокировка снята и прошел срок обнуления после последней попытки';
@@ -93,7 +93,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.TokenPairResponse"
                         }
                     },
                     "422": {
@@ -105,6 +105,80 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/auth/email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторная отправка ссылки для подтверждения email текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Аутентификация"
+                ],
+                "summary": "Повторная отправка письма для подтверждения email",
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/email/verify": {
+            "post": {
+                "description": "Подтверждение email по токену из письма",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Аутентификация"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "verifyEmail",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/auth/info": {
             "get": {
                 "security": [
@@ -153,7 +227,7 @@ const docTemplate = `{
         },
         "/api/auth/login": {
             "post": {
-                "description": "Аутентификация пользователя по email и паролю с возвратом JWT токена",
+                "description": "Аутентификация пользователя по email и паролю. Открывает новую сессию и возвращает пару токенов",
                 "consumes": [
                     "application/json"
                 ],
@@ -177,9 +251,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT токен",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.TokenPairResponse"
                         }
                     },
                     "400": {
@@ -193,36 +267,36 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/auth/refresh": {
+        "/api/auth/logout": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновление JWT токена для аутентифицированного пользователя",
+                "description": "Завершение текущей сессии: токен обновления и токены доступа сессии становятся недействительными",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Аутентификация"
                 ],
-                "summary": "Обновление JWT токена",
+                "summary": "Выход",
                 "responses": {
-                    "200": {
-                        "description": "Новый JWT токен",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "No"
                         }
                     },
                     "401": {
@@ -234,171 +308,264 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/fields": {
-            "get": {
-                "description": "Получить список всех площадок",
-                "consumes": [
+        "/api/auth/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Завершение всех сессий текущего пользователя",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Площадки"
+                    "Аутентификация"
                 ],
-                "summary": "Получить все площадки",
+                "summary": "Выход со всех устройств",
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.FieldView"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "type": "Bad"
+                            "type": "No"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "Internal"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/auth/password/forgot": {
             "post": {
-                "description": "Создать новую спортивную площадку с предоставленными данными",
+                "description": "Отправка на email ссылки для установки нового пароля. Ответ не зависит от того, зарегистрирован ли email.\nКоличество писем ограничено для одного email и для одного IP-адреса",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Площадки"
+                    "Аутентификация"
                 ],
-                "summary": "Создать новую площадку",
+                "summary": "Запрос на восстановление пароля",
                 "parameters": [
                     {
-                        "description": "Данные для создания новой площадки",
-                        "name": "createField",
+                        "description": "Email пользователя",
+                        "name": "forgotPassword",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateFieldRequest"
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.FieldView"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
-                            "type": "Unprocessable"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/fields/{id}": {
-            "put": {
-                "description": "Обновить существующую спортивную площадку с предоставленными данными",
+        "/api/auth/password/reset": {
+            "post": {
+                "description": "Установка нового пароля по токену из письма. Все сессии пользователя завершаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Площадки"
+                    "Аутентификация"
                 ],
-                "summary": "Обновить существующую площадку",
+                "summary": "Установка нового пароля",
                 "parameters": [
                     {
-                        "description": "Данные для обновления площадки",
-                        "name": "updateField",
+                        "description": "Токен и новый пароль",
+                        "name": "resetPassword",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateFieldRequest"
+                            "$ref": "#/definitions/models.ResetPasswordRequest"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID площадки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.FieldView"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "type": "Not"
+                            "type": "No"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "Unprocessable"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удалить спортивную площадку по её идентификатору",
+            }
+        },
+        "/api/auth/refresh": {
+            "post": {
+                "description": "Обмен токена обновления на новую пару токенов. Каждый токен обновления можно использовать только один раз",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Площадки"
+                    "Аутентификация"
                 ],
-                "summary": "Удалить площадку по ID",
+                "summary": "Обновление пары токенов",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID площадки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Токен обновления",
+                        "name": "refreshToken",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Площадка удалена",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.TokenPairResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "Not"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/fields/{slug}": {
+        "/api/fields": {
             "get": {
-                "description": "Получить информацию о площадке по её slug",
+                "description": "Получить список площадок с пагинацией, фильтрами и поиском",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
                     "Площадки"
                 ],
-                "summary": "Получить площадку по slug",
+                "summary": "Получить список площадок",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество элементов на странице",
+                        "name": "per_page",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Slug площадки",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
+                        "description": "Поиск по названию, описанию и адресу",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Город",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Подходит для инвалидов",
+                        "name": "for_disabled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие парковки",
+                        "name": "parking",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие туалета",
+                        "name": "toilet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие раздевалки",
+                        "name": "dressing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие цифрового табло",
+                        "name": "display",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальное количество мест",
+                        "name": "min_places",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная площадь",
+                        "name": "min_square",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.FieldView"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FieldView"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -407,58 +574,2104 @@ const docTemplate = `{
                             "type": "Bad"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "Internal"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создать новую спортивную площадку с предоставленными данными",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Создать новую площадку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания новой площадки",
+                        "name": "createField",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateFieldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "Unsupported"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "Unprocessable"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fields/nearby": {
+            "get": {
+                "description": "Получить площадки в радиусе от точки, отсортированные по расстоянию. Поддерживает те же фильтры, что и список площадок",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Поиск площадок рядом",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Широта",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота",
+                        "name": "lon",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус поиска, км (по умолчанию 5, не более 100)",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество элементов на странице",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поиск по названию, описанию и адресу",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Город",
+                        "name": "city",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FieldView"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "Internal"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fields/{slug}": {
+            "get": {
+                "description": "Получить информацию о площадке по её slug",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Получить площадку по slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновить существующую спортивную площадку с предоставленными данными",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Обновить существующую площадку",
+                "parameters": [
+                    {
+                        "description": "Данные для обновления площадки",
+                        "name": "updateField",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateFieldRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "Unsupported"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "Unprocessable"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Мягкое удаление спортивной площадки вместе с её арендами",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Удалить площадку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Площадка удалена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fields/{slug}/availability": {
+            "get": {
+                "description": "Получение свободных, занятых и закрытых по расписанию интервалов площадки за период",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Календарь занятости площадки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (2006-01-02 15:04:05), по умолчанию начало текущего дня",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окончание периода (2006-01-02 15:04:05), по умолчанию через 7 дней",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Длительность слота, например 30m или 1h (по умолчанию 60m)",
+                        "name": "slot",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldAvailability"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fields/{slug}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстановить площадку, удаленную мягким удалением, вместе с её арендами. Невозможно, если slug или время аренд уже заняты",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Восстановить площадку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fields/{slug}/schedule": {
+            "get": {
+                "description": "Получение часов работы площадки по дням недели и исключений",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Расписание площадки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Полная замена часов работы площадки и исключений. Дни недели, которых нет в расписании, считаются выходными.",
+                "tags": [
+                    "Площадки"
+                ],
+                "summary": "Изменение расписания площадки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug площадки",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Расписание площадки",
+                        "name": "updateFieldSchedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateFieldScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FieldSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/invitations": {
+            "get": {
+                "description": "Без параметров возвращает приглашения текущему пользователю: по телефону и на подтвержденный email. С параметром team_id - все приглашения команды (для капитана)",
+                "tags": [
+                    "Приглашения"
+                ],
+                "summary": "Список приглашений в команды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус приглашения (pending, accepted, declined, expired, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamInvitationView"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/invitations/{id}": {
+            "delete": {
+                "description": "Отзыв приглашения капитаном команды",
+                "tags": [
+                    "Приглашения"
+                ],
+                "summary": "Отзыв приглашения в команду",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приглашения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamInvitationView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/invitations/{id}/accept": {
+            "post": {
+                "description": "Принятие приглашения, отправленного текущему пользователю по телефону или на подтвержденный email",
+                "tags": [
+                    "Приглашения"
+                ],
+                "summary": "Принятие приглашения в команду",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приглашения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamInvitationView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/invitations/{id}/decline": {
+            "post": {
+                "description": "Отклонение приглашения, отправленного текущему пользователю по телефону или на подтвержденный email",
+                "tags": [
+                    "Приглашения"
+                ],
+                "summary": "Отклонение приглашения в команду",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID приглашения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamInvitationView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/preloader": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загрузка медиафайла. Тип файла определяется по содержимому, размер ограничен в зависимости от назначения.\nФайл принадлежит загрузившему его пользователю.",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Загрузить медиафайл",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Загруженный файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Назначение файла: logo - логотип (только изображения), gallery - галерея (изображения и видео, по умолчанию)",
+                        "name": "usage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Media"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/uploads": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание загрузки большого файла, например видео матча. Части отправляются методом PATCH,\nполученный объем можно узнать методом HEAD, после последней части загрузка завершается методом POST .../complete.\nНезавершенная загрузка сохраняется UPLOAD_SESSION_TTL после получения последней части",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Начать загрузку медиафайла по частям",
+                "parameters": [
+                    {
+                        "description": "Имя и размер файла",
+                        "name": "createUpload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUploadSessionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Назначение файла: logo - логотип, gallery - галерея (по умолчанию)",
+                        "name": "usage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/uploads/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет незавершенную загрузку и полученные части",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Отменить загрузку по частям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает в заголовке Upload-Offset количество полученных байт, с него клиент продолжает загрузку",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Узнать состояние загрузки по частям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заголовки Upload-Offset и Upload-Length",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет тело запроса к загружаемому файлу. Заголовок Upload-Offset должен совпадать с количеством\nуже полученных байт, иначе ответ 409 с актуальным Upload-Offset. Размер части ограничен UPLOAD_CHUNK_MAX_SIZE_MB",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Отправить часть файла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение части от начала файла",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/uploads/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Собирает полученные части в медиафайл. Тип и размер файла проверяются так же, как при обычной загрузке",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Завершить загрузку по частям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID загрузки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Media"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/{file}": {
+            "get": {
+                "description": "Открытие медиафайла или его уменьшенной копии",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Открыть медиафайл",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя файла",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Размер копии изображения, например thumb, medium, large",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Media"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление медиафайла вместе с его производными изображениями. Удалить можно только свой файл,\nкоторый не используется как логотип или медиа площадки, команды или пользователя",
+                "tags": [
+                    "Медиафайлы"
+                ],
+                "summary": "Удалить медиафайл",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя файла",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Media deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение списка всех прав, которые можно назначить ролям",
+                "tags": [
+                    "Роли"
+                ],
+                "summary": "Список прав",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Permission"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals": {
+            "get": {
+                "description": "Получение списка аренд с фильтрацией, поиском и сортировкой",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Возвращает список всех аренд",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество элементов на странице",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поиск по комментарию, названию команды и площадки",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификаторы площадок через запятую",
+                        "name": "field_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификаторы команд через запятую",
+                        "name": "team_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, заканчивающиеся после даты (2006-01-02 15:04:05)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начинающиеся до даты (2006-01-02 15:04:05)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статусы аренды через запятую (номер или название)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: id, start_date, end_date, created_at; префикс - для обратного порядка",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RentalView"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "Internal"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создание новой аренды. Если передано правило повторения, создается серия аренд (models.RentalSeriesView)",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Создание новой аренды",
+                "parameters": [
+                    {
+                        "description": "Данные для создания новой аренды",
+                        "name": "createRental",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRentalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "Unprocessable"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/series/{id}": {
+            "get": {
+                "description": "Получение серии аренд со всеми её арендами",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Возвращает серию повторяющихся аренд",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID серии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalSeriesView"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Отмена всех предстоящих аренд серии одной транзакцией: при ошибке ни одна аренда не отменяется.\nОтдельную аренду серии можно отменить через /api/rentals/{id}/cancel",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Отмена серии аренд",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID серии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отмены",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalSeriesView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}": {
+            "get": {
+                "description": "Получение информации об аренде по идентификатору",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Возвращает информацию об аренде по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Мягкое удаление аренды по идентификатору",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Удаляет аренду по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/cancel": {
+            "post": {
+                "description": "Отмена аренды её автором или ответственным за площадку",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Отмена аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отмены",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/complete": {
+            "post": {
+                "description": "Отметка о том, что аренда состоялась",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Завершение аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/confirm": {
+            "post": {
+                "description": "Подтверждение аренды ответственным за площадку",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Подтверждение аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/history": {
+            "get": {
+                "description": "Получение истории изменения статусов аренды",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "История статусов аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RentalStatusHistory"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/no-show": {
+            "post": {
+                "description": "Отметка о том, что команда не пришла на аренду",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Неявка на аренду",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/reject": {
+            "post": {
+                "description": "Отклонение аренды ответственным за площадку",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Отклонение аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отказа",
+                        "name": "changeRentalStatus",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeRentalStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rentals/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстановление аренды, удаленной мягким удалением. Невозможно, если время уже занято другой арендой",
+                "tags": [
+                    "Аренда"
+                ],
+                "summary": "Восстановление удаленной аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID аренды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentalView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение списка ролей с их правами",
+                "tags": [
+                    "Роли"
+                ],
+                "summary": "Список ролей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Role"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/roles/{id}/permissions": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Замена списка прав роли",
+                "tags": [
+                    "Роли"
+                ],
+                "summary": "Изменение прав роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Коды прав",
+                        "name": "updateRolePermissions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRolePermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teams": {
+            "get": {
+                "description": "Получение списка всех команд",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Возвращает список всех команд",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "Internal"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создание новой команды",
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Создание новой команды",
+                "parameters": [
+                    {
+                        "description": "Данные для создания новой команды",
+                        "name": "createTeam",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTeamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamView"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "Unprocessable"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teams/{id}": {
+            "get": {
+                "description": "Получение информации о команде по идентификатору",
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Возвращает информацию о команде по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Bad"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновление существующей команды",
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Обновление существующей команды",
+                "parameters": [
+                    {
+                        "description": "Данные для обновления команды",
+                        "name": "updateTeam",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTeamRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "Unsupported"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "Unprocessable"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Мягкое удаление команды по идентификатору. Ожидающие приглашения в команду отзываются",
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Удаляет команду по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
                             "type": "Not"
                         }
                     }
                 }
             }
         },
-        "/api/media/preloader": {
+        "/api/teams/{id}/members": {
+            "get": {
+                "description": "Получение списка участников команды",
+                "tags": [
+                    "Команды"
+                ],
+                "summary": "Состав команды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamMemberView"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Загрузка медиафайла",
+                "description": "Приглашение пользователя в команду по email или телефону. Приглашать может капитан команды. Приглашение по email принимает владелец подтвержденного email, приглашение только по телефону адресуется зарегистрированному пользователю с этим телефоном",
                 "tags": [
-                    "Медиафайлы"
+                    "Команды"
                 ],
-                "summary": "Загрузить медиафайл",
+                "summary": "Приглашение в команду",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Загруженный файл",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Данные приглашения",
+                        "name": "createTeamInvitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTeamInvitationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Media"
+                            "$ref": "#/definitions/models.TeamInvitationView"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "Bad"
                         }
                     },
-                    "413": {
-                        "description": "Request Entity Too Large",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "415": {
-                        "description": "Unsupported Media Type",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "Not"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -466,23 +2679,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/rentals": {
-            "get": {
-                "description": "Получение списка всех аренд",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/teams/{id}/members/{user_id}": {
+            "put": {
+                "description": "Изменение роли участника. Назначение капитаном передает ему управление командой",
                 "tags": [
-                    "Аренда"
+                    "Команды"
+                ],
+                "summary": "Изменение роли участника команды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID команды",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая роль",
+                        "name": "updateTeamMember",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTeamMemberRequest"
+                        }
+                    }
                 ],
-                "summary": "Возвращает список всех аренд",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.RentalView"
+                                "$ref": "#/definitions/models.TeamMemberView"
                             }
                         }
                     },
@@ -492,74 +2727,62 @@ const docTemplate = `{
                             "type": "Bad"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "Internal"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Создание новой аренды",
-                "tags": [
-                    "Аренда"
-                ],
-                "summary": "Создание новой аренды",
-                "parameters": [
-                    {
-                        "description": "Данные для создания новой аренды",
-                        "name": "createRental",
-                        "in": "body",
-                        "required": true,
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateRentalRequest"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.RentalView"
+                            "type": "Not"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "type": "Unprocessable"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/rentals/{id}": {
-            "get": {
-                "description": "Получение информации об аренде по идентификатору",
+            },
+            "delete": {
+                "description": "Исключение участника капитаном или выход участника из команды. Капитан не может покинуть команду, не передав управление",
                 "tags": [
-                    "Аренда"
+                    "Команды"
                 ],
-                "summary": "Возвращает информацию об аренде по ID",
+                "summary": "Исключение из команды",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID аренды",
+                        "description": "ID команды",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RentalView"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamMemberView"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "Bad"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -567,29 +2790,48 @@ const docTemplate = `{
                         "schema": {
                             "type": "Not"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаление аренды по идентификатору",
+            }
+        },
+        "/api/teams/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстановление команды, удаленной мягким удалением. Отозванные при удалении приглашения не восстанавливаются",
                 "tags": [
-                    "Аренда"
+                    "Команды"
                 ],
-                "summary": "Удаляет аренду по ID",
+                "summary": "Восстановление удаленной команды",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID аренды",
+                        "description": "ID команды",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "No"
+                            "$ref": "#/definitions/models.TeamView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -597,28 +2839,86 @@ const docTemplate = `{
                         "schema": {
                             "type": "Not"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/teams": {
+        "/api/users": {
             "get": {
-                "description": "Получение списка всех команд",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение списка пользователей с пагинацией и поиском по ФИО, email, телефону и городу",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Команды"
+                    "Пользователи"
+                ],
+                "summary": "Возвращает список пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество элементов на странице",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поиск по ФИО, email, телефону и городу",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Статус: 0 - не подтвержден, 1 - подтвержден, 2 - заблокирован",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Роль",
+                        "name": "role_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Возвращает список всех команд",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.TeamView"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UserView"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -635,28 +2935,41 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Создание новой команды",
+            "put": {
+                "description": "Обновление существующего пользователя",
                 "tags": [
-                    "Команды"
+                    "Пользователи"
                 ],
-                "summary": "Создание новой команды",
+                "summary": "Обновление существующего пользователя",
                 "parameters": [
                     {
-                        "description": "Данные для создания новой команды",
-                        "name": "createTeam",
+                        "description": "Данные для обновления пользователя",
+                        "name": "updateUser",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateTeamRequest"
+                            "$ref": "#/definitions/models.UpdateUserRequest"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/models.TeamView"
+                            "type": "No"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
                         }
                     },
                     "422": {
@@ -668,17 +2981,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/teams/{id}": {
+        "/api/users/{id}": {
             "get": {
-                "description": "Получение информации о команде по идентификатору",
+                "description": "Получение информации о пользователе по идентификатору",
                 "tags": [
-                    "Команды"
+                    "Пользователи"
                 ],
-                "summary": "Возвращает информацию о команде по ID",
+                "summary": "Возвращает информацию о пользователе по ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID команды",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -688,7 +3001,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TeamView"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
                     "400": {
@@ -706,60 +3019,76 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Обновление существующей команды",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменение данных и роли пользователя. При смене email пользователь должен подтвердить новый адрес",
                 "tags": [
-                    "Команды"
+                    "Пользователи"
                 ],
-                "summary": "Обновление существующей команды",
+                "summary": "Изменение пользователя администратором",
                 "parameters": [
-                    {
-                        "description": "Данные для обновления команды",
-                        "name": "updateTeam",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateTeamRequest"
-                        }
-                    },
                     {
                         "type": "integer",
-                        "description": "ID команды",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Данные пользователя",
+                        "name": "updateUser",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUpdateUserRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "No"
+                            "$ref": "#/definitions/models.UserView"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "Not"
+                            "type": "Bad"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "Unprocessable"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "Not"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Удаление команды по идентификатору",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Мягкое удаление пользователя: запись сохраняется с датой удаления, все сессии завершаются",
                 "tags": [
-                    "Команды"
+                    "Пользователи"
                 ],
-                "summary": "Удаляет команду по ID",
+                "summary": "Удаляет пользователя по ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID команды",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -772,6 +3101,12 @@ const docTemplate = `{
                             "type": "No"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -781,59 +3116,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users": {
-            "get": {
-                "description": "Получение списка всех пользователей",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/api/users/{id}/block": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Блокировка пользователя. Все сессии пользователя завершаются, войти он не сможет до разблокировки",
                 "tags": [
                     "Пользователи"
                 ],
-                "summary": "Возвращает список всех пользователей",
+                "summary": "Блокировка пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/models.UserView"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "Bad"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "Internal"
+                            "type": "Not"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновление существующего пользователя",
+            }
+        },
+        "/api/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстановление пользователя, удаленного мягким удалением. Невозможно, если email или телефон уже заняты",
                 "tags": [
                     "Пользователи"
                 ],
-                "summary": "Обновление существующего пользователя",
+                "summary": "Восстановление удаленного пользователя",
                 "parameters": [
-                    {
-                        "description": "Данные для обновления пользователя",
-                        "name": "updateUser",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateUserRequest"
-                        }
-                    },
                     {
                         "type": "integer",
                         "description": "ID пользователя",
@@ -843,10 +3187,16 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "No"
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -855,22 +3205,27 @@ const docTemplate = `{
                             "type": "Not"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "type": "Unprocessable"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}": {
-            "get": {
-                "description": "Получение информации о пользователе по идентификатору",
+        "/api/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Назначение пользователю роли. Собственную роль изменить нельзя",
                 "tags": [
-                    "Пользователи"
+                    "Роли"
                 ],
-                "summary": "Возвращает информацию о пользователе по ID",
+                "summary": "Назначение роли пользователю",
                 "parameters": [
                     {
                         "type": "integer",
@@ -878,13 +3233,22 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Роль",
+                        "name": "updateUserRole",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.UserView"
                         }
                     },
                     "400": {
@@ -893,6 +3257,12 @@ const docTemplate = `{
                             "type": "Bad"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -900,13 +3270,20 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаление пользователя по идентификатору",
+            }
+        },
+        "/api/users/{id}/unblock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Разблокировка пользователя. Восстанавливается статус подтверждения email, который был до блокировки",
                 "tags": [
                     "Пользователи"
                 ],
-                "summary": "Удаляет пользователя по ID",
+                "summary": "Разблокировка пользователя",
                 "parameters": [
                     {
                         "type": "integer",
@@ -917,10 +3294,16 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "No"
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -928,6 +3311,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "Not"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -945,10 +3334,73 @@ const docTemplate = `{
                 }
             }
         },
-        "models.AddressSuggestion": {
+        "models.AddressSuggestion": {
+            "type": "object",
+            "properties": {
+                "geo": {
+                    "$ref": "#/definitions/models.Geo"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdminUpdateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 3
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                },
+                "role_id": {
+                    "description": "Новая роль, если требуется её изменить",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AvailabilityInterval": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "Окончание интервала",
+                    "type": "string"
+                },
+                "rental_id": {
+                    "description": "Аренда, занимающая интервал",
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "Начало интервала",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус интервала",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChangeRentalStatusRequest": {
             "type": "object",
             "properties": {
-                "value": {
+                "comment": {
+                    "description": "Комментарий (например, причина отказа)",
                     "type": "string"
                 }
             }
@@ -982,12 +3434,24 @@ const docTemplate = `{
                 "info": {
                     "type": "string"
                 },
+                "lat": {
+                    "description": "Широта, если не указана - определяется по адресу",
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
                 "location": {
                     "type": "string"
                 },
                 "logo": {
                     "type": "string"
                 },
+                "lon": {
+                    "description": "Долгота, если не указана - определяется по адресу",
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
                 "media": {
                     "type": "string"
                 },
@@ -1000,6 +3464,14 @@ const docTemplate = `{
                 "places": {
                     "type": "integer"
                 },
+                "responsible": {
+                    "description": "Ответственный",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    ]
+                },
                 "slug": {
                     "type": "string"
                 },
@@ -1036,6 +3508,14 @@ const docTemplate = `{
                     "description": "Идентификатор",
                     "type": "integer"
                 },
+                "recurrence": {
+                    "description": "Правило повторения аренды",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RentalRecurrence"
+                        }
+                    ]
+                },
                 "start_date": {
                     "description": "Дата начала аренды",
                     "type": "string"
@@ -1046,6 +3526,29 @@ const docTemplate = `{
                 }
             }
         },
+        "models.CreateTeamInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email приглашенного",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "Телефон приглашенного",
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 6
+                },
+                "role": {
+                    "description": "Роль в команде, по умолчанию player",
+                    "type": "string",
+                    "enum": [
+                        "player",
+                        "reserve"
+                    ]
+                }
+            }
+        },
         "models.CreateTeamRequest": {
             "type": "object",
             "required": [
@@ -1073,16 +3576,31 @@ const docTemplate = `{
                     "description": "Название*",
                     "type": "string"
                 },
-                "participant_count": {
-                    "description": "Кол-во участников",
-                    "type": "integer"
-                },
                 "uniform_color": {
                     "description": "Цвет формы",
                     "type": "string"
                 }
             }
         },
+        "models.CreateUploadSessionRequest": {
+            "type": "object",
+            "required": [
+                "file_name",
+                "size"
+            ],
+            "properties": {
+                "file_name": {
+                    "description": "Имя файла у клиента",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "size": {
+                    "description": "Размер файла",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
         "models.CreateUserRequest": {
             "type": "object",
             "required": [
@@ -1132,6 +3650,112 @@ const docTemplate = `{
                 }
             }
         },
+        "models.FieldAvailability": {
+            "type": "object",
+            "properties": {
+                "field_id": {
+                    "description": "Идентификатор площадки",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "Начало периода",
+                    "type": "string"
+                },
+                "intervals": {
+                    "description": "Интервалы",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AvailabilityInterval"
+                    }
+                },
+                "slot": {
+                    "description": "Длительность слота",
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug площадки",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Окончание периода",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldSchedule": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Часы работы по дням недели",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldScheduleDay"
+                    }
+                },
+                "exceptions": {
+                    "description": "Исключения",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldScheduleException"
+                    }
+                },
+                "field_id": {
+                    "description": "Идентификатор площадки",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FieldScheduleDay": {
+            "type": "object",
+            "required": [
+                "close_time",
+                "open_time"
+            ],
+            "properties": {
+                "close_time": {
+                    "description": "Время закрытия (15:04, допускается 24:00)",
+                    "type": "string"
+                },
+                "open_time": {
+                    "description": "Время открытия (15:04)",
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "День недели (1 - понедельник, 7 - воскресенье)",
+                    "type": "integer",
+                    "maximum": 7,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.FieldScheduleException": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "close_time": {
+                    "description": "Время закрытия, если площадка работает",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Комментарий",
+                    "type": "string"
+                },
+                "date": {
+                    "description": "Дата (2006-01-02)",
+                    "type": "string"
+                },
+                "is_closed": {
+                    "description": "Площадка закрыта весь день",
+                    "type": "boolean"
+                },
+                "open_time": {
+                    "description": "Время открытия, если площадка работает",
+                    "type": "string"
+                }
+            }
+        },
         "models.FieldView": {
             "type": "object",
             "properties": {
@@ -1150,6 +3774,10 @@ const docTemplate = `{
                 "display": {
                     "type": "boolean"
                 },
+                "distance": {
+                    "description": "Расстояние до точки поиска, км",
+                    "type": "number"
+                },
                 "dressing": {
                     "type": "boolean"
                 },
@@ -1162,6 +3790,10 @@ const docTemplate = `{
                 "info": {
                     "type": "string"
                 },
+                "lat": {
+                    "description": "Широта",
+                    "type": "number"
+                },
                 "location": {
                     "type": "string"
                 },
@@ -1173,6 +3805,10 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "lon": {
+                    "description": "Долгота",
+                    "type": "number"
+                },
                 "media": {
                     "description": "Медиа",
                     "type": "array",
@@ -1189,56 +3825,317 @@ const docTemplate = `{
                 "places": {
                     "type": "integer"
                 },
-                "slug": {
+                "responsible": {
+                    "description": "Ответственный",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    ]
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "square": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "toilet": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email пользователя",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Geo": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "string"
+                },
+                "lon": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 4
+                }
+            }
+        },
+        "models.Media": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ext": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mime_type": {
+                    "description": "Тип содержимого, определенный по самому файлу",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Ключ файла в хранилище",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "Ссылка для скачивания файла",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Пользователь, загрузивший файл",
+                    "type": "integer"
+                },
+                "variants": {
+                    "description": "Производные изображения по имени размера",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.MediaVariant"
+                    }
+                }
+            }
+        },
+        "models.MediaVariant": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "description": "Высота в пикселях",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Ключ файла в хранилище",
+                    "type": "string"
+                },
+                "mime_type": {
+                    "description": "Тип содержимого",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Размер",
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "Ссылка для скачивания файла",
+                    "type": "string"
+                },
+                "width": {
+                    "description": "Ширина в пикселях",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Pagination": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "description": "Текущая страница",
+                    "type": "integer"
+                },
+                "pages": {
+                    "description": "Общее количество страниц",
+                    "type": "integer"
+                },
+                "per_page": {
+                    "description": "Количество элементов на странице",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Общее количество элементов",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaginationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Данные",
+                    "type": "array",
+                    "items": {}
+                },
+                "filter": {
+                    "description": "Параметры фильтрации"
+                },
+                "pagination": {
+                    "description": "Информация о пагинации",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Pagination"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Permission": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код права",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Описание",
                     "type": "string"
                 },
-                "square": {
+                "id": {
                     "type": "integer"
-                },
-                "status": {
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "description": "Токен обновления",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RentalConflictResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код ответа",
                     "type": "integer"
                 },
-                "toilet": {
-                    "type": "boolean"
+                "conflicts": {
+                    "description": "Пересекающиеся аренды",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RentalView"
+                    }
+                },
+                "message": {
+                    "description": "Сообщение",
+                    "type": "string"
                 }
             }
         },
-        "models.LoginUserRequest": {
+        "models.RentalRecurrence": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "frequency"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "count": {
+                    "description": "Количество повторений",
+                    "type": "integer",
+                    "maximum": 52,
+                    "minimum": 1
                 },
-                "password": {
+                "frequency": {
+                    "description": "Частота повторения",
                     "type": "string",
-                    "maxLength": 128,
-                    "minLength": 4
+                    "enum": [
+                        "weekly",
+                        "biweekly"
+                    ]
+                },
+                "until": {
+                    "description": "Дата окончания серии (включительно)",
+                    "type": "string"
                 }
             }
         },
-        "models.Media": {
+        "models.RentalSeriesView": {
             "type": "object",
             "properties": {
+                "count": {
+                    "description": "Количество повторений",
+                    "type": "integer"
+                },
                 "created_at": {
+                    "description": "Дата создания",
                     "type": "string"
                 },
-                "ext": {
+                "frequency": {
+                    "description": "Частота повторения",
                     "type": "string"
                 },
                 "id": {
+                    "description": "Идентификатор",
                     "type": "integer"
                 },
-                "name": {
+                "rentals": {
+                    "description": "Аренды серии",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RentalView"
+                    }
+                },
+                "until": {
+                    "description": "Дата окончания серии",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RentalStatusHistory": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "Комментарий",
                     "type": "string"
                 },
-                "path": {
+                "created_at": {
+                    "description": "Дата изменения статуса",
                     "type": "string"
                 },
-                "size": {
+                "from_status": {
+                    "description": "Предыдущий статус",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Идентификатор",
+                    "type": "integer"
+                },
+                "rental_id": {
+                    "description": "Идентификатор аренды",
+                    "type": "integer"
+                },
+                "to_status": {
+                    "description": "Новый статус",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "Пользователь, изменивший статус",
                     "type": "integer"
                 }
             }
@@ -1274,6 +4171,10 @@ const docTemplate = `{
                     "description": "Идентификатор",
                     "type": "integer"
                 },
+                "series_id": {
+                    "description": "Серия повторяющихся аренд",
+                    "type": "integer"
+                },
                 "start_date": {
                     "description": "Дата начала аренды",
                     "type": "string"
@@ -1282,6 +4183,10 @@ const docTemplate = `{
                     "description": "Статус аренды",
                     "type": "integer"
                 },
+                "status_name": {
+                    "description": "Название статуса аренды",
+                    "type": "string"
+                },
                 "team": {
                     "description": "Команда",
                     "allOf": [
@@ -1300,6 +4205,25 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "description": "Новый пароль",
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 4
+                },
+                "token": {
+                    "description": "Токен из письма",
+                    "type": "string"
+                }
+            }
+        },
         "models.Role": {
             "type": "object",
             "properties": {
@@ -1308,6 +4232,101 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "permissions": {
+                    "description": "Права роли",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.TeamInvitationView": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email приглашенного",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Срок действия",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invited_by": {
+                    "description": "Пригласивший пользователь",
+                    "type": "integer"
+                },
+                "invitee_id": {
+                    "description": "Приглашенный пользователь, найденный по телефону",
+                    "type": "integer"
+                },
+                "phone": {
+                    "description": "Телефон приглашенного",
+                    "type": "string"
+                },
+                "responded_at": {
+                    "description": "Дата ответа",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Роль в команде после принятия",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус",
+                    "type": "integer"
+                },
+                "status_name": {
+                    "description": "Название статуса",
+                    "type": "string"
+                },
+                "team": {
+                    "description": "Команда",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TeamView"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "Пользователь, ответивший на приглашение",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TeamMemberView": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Дата вступления",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "Роль в команде",
+                    "type": "string"
+                },
+                "team_id": {
+                    "description": "Идентификатор команды",
+                    "type": "integer"
+                },
+                "user": {
+                    "description": "Пользователь",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    ]
                 }
             }
         },
@@ -1353,7 +4372,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "participant_count": {
-                    "description": "Кол-во участников",
+                    "description": "Кол-во участников (по составу команды)",
                     "type": "integer"
                 },
                 "responsible": {
@@ -1374,6 +4393,27 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TokenPairResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "JWT токен доступа",
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "Время жизни токена доступа, сек",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "description": "Токен обновления",
+                    "type": "string"
+                },
+                "token_type": {
+                    "description": "Тип токена доступа",
+                    "type": "string"
+                }
+            }
+        },
         "models.UpdateFieldRequest": {
             "type": "object",
             "required": [
@@ -1403,12 +4443,24 @@ const docTemplate = `{
                 "info": {
                     "type": "string"
                 },
+                "lat": {
+                    "description": "Широта, если не указана - определяется по адресу",
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
                 "location": {
                     "type": "string"
                 },
                 "logo": {
                     "type": "string"
                 },
+                "lon": {
+                    "description": "Долгота, если не указана - определяется по адресу",
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
                 "media": {
                     "type": "string"
                 },
@@ -1421,6 +4473,14 @@ const docTemplate = `{
                 "places": {
                     "type": "integer"
                 },
+                "responsible": {
+                    "description": "Ответственный",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UserView"
+                        }
+                    ]
+                },
                 "slug": {
                     "type": "string"
                 },
@@ -1432,6 +4492,57 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateFieldScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Часы работы по дням недели",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldScheduleDay"
+                    }
+                },
+                "exceptions": {
+                    "description": "Исключения",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldScheduleException"
+                    }
+                }
+            }
+        },
+        "models.UpdateRolePermissionsRequest": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "permissions": {
+                    "description": "Коды прав",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.UpdateTeamMemberRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "Роль в команде",
+                    "type": "string",
+                    "enum": [
+                        "captain",
+                        "player",
+                        "reserve"
+                    ]
+                }
+            }
+        },
         "models.UpdateTeamRequest": {
             "type": "object",
             "required": [
@@ -1463,10 +4574,6 @@ const docTemplate = `{
                     "description": "Название*",
                     "type": "string"
                 },
-                "participant_count": {
-                    "description": "Кол-во участников",
-                    "type": "integer"
-                },
                 "uniform_color": {
                     "description": "Цвет формы",
                     "type": "string"
@@ -1504,6 +4611,49 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role_id"
+            ],
+            "properties": {
+                "role_id": {
+                    "description": "Идентификатор роли",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UploadSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Дата, после которой незавершенная загрузка удаляется",
+                    "type": "string"
+                },
+                "file_name": {
+                    "description": "Имя файла у клиента",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "offset": {
+                    "description": "Количество полученных байт, с него продолжается загрузка",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Размер файла",
+                    "type": "integer"
+                },
+                "usage": {
+                    "description": "Назначение файла: logo, gallery",
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -1614,6 +4764,18 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Токен из письма",
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -6,6 +6,11 @@ import (
 	"goland_api/pkg/cmd"
 	"goland_api/pkg/database"
 	"goland_api/pkg/handlers"
+	"goland_api/pkg/models"
+	"goland_api/pkg/services/imageproc"
+	"goland_api/pkg/services/loginguard"
+	"goland_api/pkg/services/mailer"
+	"goland_api/pkg/services/storage"
 	"log"
 	"net/http"
 	"os"
@@ -31,14 +36,35 @@ func main() {
 	dataSourceName := os.Getenv("DATABASE_URL")
 	database.InitDB(dataSourceName)
 
+	// Отправка писем
+	mailer.Init()
+
+	// Ограничение попыток входа
+	loginguard.Init(database.DB)
+
+	// Хранилище файлов и обработка изображений
+	storage.Init()
+	imageproc.Init()
+
 	// Запуск консольных команд
 	consoleName := flag.String("consoleName", "Default", "Console Name")
+	flag.Parse()
 
-	if *consoleName == "importFields" {
+	if *consoleName != "Default" {
 		log.Println("Run Console ", *consoleName)
-		cmd.RunImportFields()
-	} else if *consoleName != "Default" {
-		log.Println("Unknown Console ", *consoleName)
+		switch *consoleName {
+		case "importFields":
+			cmd.RunImportFields()
+		case "geocodeFields":
+			cmd.RunGeocodeFields()
+		case "purgeDeleted":
+			cmd.RunPurgeDeleted()
+		case "gcMedia":
+			cmd.RunGCMedia()
+		default:
+			log.Println("Unknown Console ", *consoleName)
+		}
+		return
 	}
 
 	// Регистрация маршрутов
@@ -51,40 +77,87 @@ func main() {
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	// Участники
-	router.HandleFunc("/api/users", handlers.AuthAdminMiddleware(handlers.GetUsers())).Methods("GET")
-	router.HandleFunc("/api/users/{id}", handlers.AuthAdminMiddleware(handlers.GetUser())).Methods("GET")
+	router.HandleFunc("/api/users", handlers.RequirePermission(models.PermissionUsersView, handlers.GetUsers())).Methods("GET")
+	router.HandleFunc("/api/users/{id}", handlers.RequirePermission(models.PermissionUsersView, handlers.GetUser())).Methods("GET")
+	router.HandleFunc("/api/users/{id}", handlers.RequirePermission(models.PermissionUsersManage, handlers.AdminUpdateUser())).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/users/{id}", handlers.RequirePermission(models.PermissionUsersManage, handlers.DeleteUser())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/users/{id}/block", handlers.RequirePermission(models.PermissionUsersManage, handlers.BlockUser())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id}/unblock", handlers.RequirePermission(models.PermissionUsersManage, handlers.UnblockUser())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id}/restore", handlers.RequirePermission(models.PermissionUsersManage, handlers.RestoreUser())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id}/role", handlers.RequirePermission(models.PermissionUsersManage, handlers.UpdateUserRole())).Methods("PUT", "OPTIONS")
+
+	// Роли и права
+	router.HandleFunc("/api/roles", handlers.RequirePermission(models.PermissionUsersManage, handlers.GetRoles())).Methods("GET")
+	router.HandleFunc("/api/permissions", handlers.RequirePermission(models.PermissionUsersManage, handlers.GetPermissions())).Methods("GET")
+	router.HandleFunc("/api/roles/{id}/permissions", handlers.RequirePermission(models.PermissionRolesManage, handlers.UpdateRolePermissions())).Methods("PUT", "OPTIONS")
 
 	// Кабинет
 	router.HandleFunc("/api/auth/info", handlers.AuthMiddleware(handlers.InfoUser())).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/auth/create", handlers.CreateUser()).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/auth/update", handlers.AuthMiddleware(handlers.UpdateUser())).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/api/auth/login", handlers.Login()).Methods("POST", "OPTIONS")
-	router.HandleFunc("/api/auth/refresh", handlers.AuthMiddleware(handlers.Refresh())).Methods("POST", "OPTIONS")
-	//router.HandleFunc("/api/auth", handlers.DeleteUser()).Methods("DELETE")
+	router.HandleFunc("/api/auth/refresh", handlers.Refresh()).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/logout", handlers.AuthMiddleware(handlers.Logout())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/logout-all", handlers.AuthMiddleware(handlers.LogoutAll())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/password/forgot", handlers.ForgotPassword()).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/password/reset", handlers.ResetPassword()).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/email/verify", handlers.VerifyEmail()).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/email/resend", handlers.AuthMiddleware(handlers.ResendEmailVerification())).Methods("POST", "OPTIONS")
 
 	// Команды
 	router.HandleFunc("/api/teams", handlers.GetTeams()).Methods("GET")
 	router.HandleFunc("/api/teams/{id}", handlers.GetTeam()).Methods("GET")
-	router.HandleFunc("/api/teams", handlers.AuthUserMiddleware(handlers.CreateTeam())).Methods("POST", "OPTIONS")
-	router.HandleFunc("/api/teams/{id}", handlers.AuthUserMiddleware(handlers.UpdateTeam())).Methods("PUT", "OPTIONS")
-	router.HandleFunc("/api/teams/{id}", handlers.AuthUserMiddleware(handlers.DeleteTeam())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/teams", handlers.RequirePermission(models.PermissionTeamsCreate, handlers.CreateTeam())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}", handlers.AuthMiddleware(handlers.UpdateTeam())).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}", handlers.AuthMiddleware(handlers.DeleteTeam())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}/restore", handlers.RequirePermission(models.PermissionTeamsDelete, handlers.RestoreTeam())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}/members", handlers.GetTeamMembers()).Methods("GET")
+	router.HandleFunc("/api/teams/{id}/members", handlers.AuthMiddleware(handlers.InviteTeamMember())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}/members/{user_id}", handlers.AuthMiddleware(handlers.UpdateTeamMember())).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/teams/{id}/members/{user_id}", handlers.AuthMiddleware(handlers.DeleteTeamMember())).Methods("DELETE", "OPTIONS")
+
+	// Приглашения в команды
+	router.HandleFunc("/api/invitations", handlers.AuthMiddleware(handlers.GetInvitations())).Methods("GET")
+	router.HandleFunc("/api/invitations/{id}/accept", handlers.AuthMiddleware(handlers.AcceptInvitation())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/invitations/{id}/decline", handlers.AuthMiddleware(handlers.DeclineInvitation())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/invitations/{id}", handlers.AuthMiddleware(handlers.CancelInvitation())).Methods("DELETE", "OPTIONS")
 
 	// Площадки
 	router.HandleFunc("/api/fields", handlers.GetFields()).Methods("GET")
+	router.HandleFunc("/api/fields/nearby", handlers.GetNearbyFields()).Methods("GET")
 	router.HandleFunc("/api/fields/{slug}", handlers.GetField()).Methods("GET")
-	router.HandleFunc("/api/fields", handlers.AuthMiddleware(handlers.CreateField())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/fields/{slug}/availability", handlers.GetFieldAvailability()).Methods("GET")
+	router.HandleFunc("/api/fields/{slug}/schedule", handlers.GetFieldSchedule()).Methods("GET")
+	router.HandleFunc("/api/fields/{slug}/schedule", handlers.AuthMiddleware(handlers.UpdateFieldSchedule())).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/fields", handlers.RequirePermission(models.PermissionFieldsCreate, handlers.CreateField())).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/fields/{slug}", handlers.AuthMiddleware(handlers.UpdateField())).Methods("PUT", "OPTIONS")
-	router.HandleFunc("/api/fields/{slug}", handlers.AuthAdminMiddleware(handlers.DeleteField())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/fields/{slug}", handlers.RequirePermission(models.PermissionFieldsDelete, handlers.DeleteField())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/fields/{slug}/restore", handlers.RequirePermission(models.PermissionFieldsDelete, handlers.RestoreField())).Methods("POST", "OPTIONS")
 
 	// Аренда
 	router.HandleFunc("/api/rentals", handlers.GetRentals()).Methods("GET")
 	router.HandleFunc("/api/rentals/{id}", handlers.GetRental()).Methods("GET")
-	router.HandleFunc("/api/rentals", handlers.AuthMiddleware(handlers.CreateRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals", handlers.RequirePermission(models.PermissionRentalsCreate, handlers.CreateRental())).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/rentals/{id}", handlers.AuthMiddleware(handlers.DeleteRental())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/restore", handlers.RequirePermission(models.PermissionRentalsDelete, handlers.RestoreRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/history", handlers.GetRentalHistory()).Methods("GET")
+	router.HandleFunc("/api/rentals/series/{id}", handlers.GetRentalSeries()).Methods("GET")
+	router.HandleFunc("/api/rentals/series/{id}", handlers.AuthMiddleware(handlers.CancelRentalSeries())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/confirm", handlers.AuthMiddleware(handlers.ConfirmRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/reject", handlers.AuthMiddleware(handlers.RejectRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/cancel", handlers.AuthMiddleware(handlers.CancelRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/complete", handlers.AuthMiddleware(handlers.CompleteRental())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/rentals/{id}/no-show", handlers.AuthMiddleware(handlers.NoShowRental())).Methods("POST", "OPTIONS")
 
 	// Media
-	router.HandleFunc("/api/media/preloader", handlers.Preloader()).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/media/preloader", handlers.AuthMiddleware(handlers.Preloader())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/media/uploads", handlers.AuthMiddleware(handlers.CreateUploadSession())).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/media/uploads/{id}", handlers.AuthMiddleware(handlers.GetUploadSession())).Methods("HEAD", "OPTIONS")
+	router.HandleFunc("/api/media/uploads/{id}", handlers.AuthMiddleware(handlers.UploadChunk())).Methods("PATCH", "OPTIONS")
+	router.HandleFunc("/api/media/uploads/{id}", handlers.AuthMiddleware(handlers.CancelUpload())).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/media/uploads/{id}/complete", handlers.AuthMiddleware(handlers.CompleteUpload())).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/media/{file}", handlers.View()).Methods("GET")
+	router.HandleFunc("/api/media/{file}", handlers.AuthMiddleware(handlers.DeleteMedia())).Methods("DELETE", "OPTIONS")
 
 	// Адресса
 	router.HandleFunc("/api/address/suggests", handlers.SuggestAddress()).Methods("GET")
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"goland_api/pkg/database"
+	"goland_api/pkg/services/dadata"
+	"log"
+	"time"
+)
+
+// Пауза между запросами к Dadata, чтобы не превысить ограничение на частоту запросов
+const geocodeRequestDelay = 100 * time.Millisecond
+
+// RunGeocodeFields заполняет координаты площадок, у которых они не указаны, по адресу
+func RunGeocodeFields() {
+	ctx := context.Background()
+	type fieldAddress struct {
+		id      int64
+		city    string
+		address string
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT id, city, address FROM fields WHERE lat IS NULL OR lon IS NULL ORDER BY id")
+	if err != nil {
+		log.Fatal("Failed to select fields:", err)
+	}
+
+	var fields []fieldAddress
+	for rows.Next() {
+		var field fieldAddress
+		if err := rows.Scan(&field.id, &field.city, &field.address); err != nil {
+			log.Fatal("Failed to scan field:", err)
+		}
+		fields = append(fields, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Fatal("Failed to select fields:", err)
+	}
+
+	updated := 0
+	for _, field := range fields {
+		lat, lon, err := dadata.Geocode(field.city + ", " + field.address)
+		if err != nil {
+			log.Printf("Field %d: %s", field.id, err)
+		} else if _, err := database.Conn(ctx).Exec("UPDATE fields SET lat = $1, lon = $2, updated_at = now() WHERE id = $3", lat, lon, field.id); err != nil {
+			log.Printf("Field %d: failed to update coordinates: %s", field.id, err)
+		} else {
+			updated++
+		}
+		time.Sleep(geocodeRequestDelay)
+	}
+
+	log.Printf("Geocoded %d of %d fields", updated, len(fields))
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"goland_api/pkg/services/storage"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Срок, после которого неиспользуемые медиафайлы удаляются, по умолчанию, дней
+const defaultMediaGCDays = 7
+
+// RunGCMedia удаляет медиафайлы, которые ни к чему не привязаны дольше срока хранения.
+// Срок отсчитывается от отвязки файла от последнего объекта, а для никогда не привязанных файлов - от загрузки.
+// Срок хранения в днях задается переменной MEDIA_GC_DAYS. Также удаляются просроченные загрузки по частям.
+func RunGCMedia() {
+	ctx := context.Background()
+	days := defaultMediaGCDays
+	if value := os.Getenv("MEDIA_GC_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Fatal("Invalid MEDIA_GC_DAYS: ", value)
+		}
+		days = parsed
+	}
+	before := time.Now().AddDate(0, 0, -days)
+
+	err, medias := repository.UnattachedMedias(ctx, before)
+	if err != nil {
+		log.Fatal("Failed to select media:", err)
+	}
+
+	removed := 0
+	for _, media := range medias {
+		// Файл мог быть привязан к объекту после выборки, поэтому условие проверяется повторно
+		result, err := database.Conn(ctx).Exec("DELETE FROM medias m WHERE m.id = $1 "+
+			"AND NOT EXISTS (SELECT 1 FROM media_attachments a WHERE a.media_id = m.id)", media.ID)
+		if err != nil {
+			log.Printf("Media %s: failed to delete record: %s", media.Name, err)
+			continue
+		}
+		if deleted, _ := result.RowsAffected(); deleted == 0 {
+			continue
+		}
+
+		for _, key := range repository.MediaKeys(media) {
+			if err := storage.Default.Delete(ctx, key); err != nil {
+				log.Printf("Media %s: failed to delete %s: %s", media.Name, key, err)
+			}
+		}
+		removed++
+	}
+
+	log.Printf("Removed %d of %d media unattached before %s", removed, len(medias), before.Format("2006-01-02"))
+
+	gcUploadSessions(ctx)
+}
+
+// uploadCompleteTimeout возвращает время, за которое должна завершиться сборка загрузки по частям
+func uploadCompleteTimeout() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("UPLOAD_COMPLETE_TIMEOUT")); err == nil && value > 0 {
+		return value
+	}
+	return models.DefaultUploadCompleteTimeout
+}
+
+// gcUploadSessions удаляет просроченные незавершенные загрузки по частям вместе с полученными частями.
+// Загрузки, которые сейчас собираются, не удаляются, захват старше UPLOAD_COMPLETE_TIMEOUT считается снятым.
+func gcUploadSessions(ctx context.Context) {
+	// Обе выборки используют одну отметку времени, чтобы не удалить загрузку, части которой не выбраны
+	now := time.Now()
+	staleClaim := now.Add(-uploadCompleteTimeout())
+	rows, err := database.Conn(ctx).Query("SELECT c.key FROM upload_chunks c JOIN upload_sessions s ON s.id = c.session_id "+
+		"WHERE s.expires_at < $1 AND (s.completing_at IS NULL OR s.completing_at < $2)", now, staleClaim)
+	if err != nil {
+		log.Fatal("Failed to select upload chunks:", err)
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			log.Fatal("Failed to scan upload chunk:", err)
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Fatal("Failed to select upload chunks:", err)
+	}
+
+	// Части удаляются вместе с загрузками каскадно
+	result, err := database.Conn(ctx).Exec("DELETE FROM upload_sessions "+
+		"WHERE expires_at < $1 AND (completing_at IS NULL OR completing_at < $2)", now, staleClaim)
+	if err != nil {
+		log.Fatal("Failed to delete upload sessions:", err)
+	}
+	for _, key := range keys {
+		if err := storage.Default.Delete(ctx, key); err != nil {
+			log.Printf("Upload chunk %s: failed to delete: %s", key, err)
+		}
+	}
+
+	sessions, _ := result.RowsAffected()
+	log.Printf("Removed %d expired upload sessions with %d chunks", sessions, len(keys))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"goland_api/pkg/database"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Срок хранения удаленных записей по умолчанию, дней
+const defaultPurgeRetentionDays = 90
+
+// Запросы окончательного удаления в порядке выполнения.
+// Сначала удаляются зависимые записи, затем записи, на которые они ссылаются.
+// Запись, на которую еще ссылаются неудаленные данные, остается до следующего запуска.
+var purgeQueries = []struct {
+	table string
+	query string
+}{
+	{"rentals", "DELETE FROM rentals WHERE deleted_at < $1"},
+	{"rental_series", "DELETE FROM rental_series s WHERE s.deleted_at < $1 " +
+		"AND NOT EXISTS (SELECT 1 FROM rentals r WHERE r.series_id = s.id)"},
+	{"teams", "DELETE FROM teams t WHERE t.deleted_at < $1 " +
+		"AND NOT EXISTS (SELECT 1 FROM rentals r WHERE r.team_id = t.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM rental_series s WHERE s.team_id = t.id)"},
+	{"fields", "DELETE FROM fields f WHERE f.deleted_at < $1 " +
+		"AND NOT EXISTS (SELECT 1 FROM rentals r WHERE r.field_id = f.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM rental_series s WHERE s.field_id = f.id)"},
+	{"users", "DELETE FROM users u WHERE u.deleted_at < $1 " +
+		"AND NOT EXISTS (SELECT 1 FROM rentals r WHERE r.user_id = u.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM rental_series s WHERE s.user_id = u.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM rental_status_history h WHERE h.user_id = u.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM teams t WHERE t.responsible_id = u.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM fields f WHERE f.responsible_id = u.id)"},
+	// Привязки медиафайлов к окончательно удаленным объектам. Отвязанные файлы удаляет команда gcMedia.
+	{"media_attachments", "DELETE FROM media_attachments a WHERE a.created_at < $1 AND NOT EXISTS (" +
+		"SELECT 1 FROM fields f WHERE a.entity_type = 'field' AND f.id = a.entity_id " +
+		"UNION ALL SELECT 1 FROM teams t WHERE a.entity_type = 'team' AND t.id = a.entity_id " +
+		"UNION ALL SELECT 1 FROM users u WHERE a.entity_type = 'user' AND u.id = a.entity_id)"},
+}
+
+// RunPurgeDeleted окончательно удаляет записи, удаленные мягким удалением раньше срока хранения.
+// Срок хранения в днях задается переменной PURGE_RETENTION_DAYS.
+func RunPurgeDeleted() {
+	ctx := context.Background()
+	retentionDays := defaultPurgeRetentionDays
+	if value := os.Getenv("PURGE_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			log.Fatal("Invalid PURGE_RETENTION_DAYS: ", value)
+		}
+		retentionDays = days
+	}
+	before := time.Now().AddDate(0, 0, -retentionDays)
+
+	for _, purge := range purgeQueries {
+		result, err := database.Conn(ctx).Exec(purge.query, before)
+		if err != nil {
+			log.Fatalf("Failed to purge %s: %s", purge.table, err)
+		}
+		purged, _ := result.RowsAffected()
+		log.Printf("Purged %d rows from %s deleted before %s", purged, purge.table, before.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Время выполнения одного запроса по умолчанию
+const defaultQueryTimeout = 5 * time.Second
+
+// QueryTimeout - максимальное время выполнения одного запроса. Ноль - без ограничения.
+var QueryTimeout = defaultQueryTimeout
+
+// WithTimeout ограничивает контекст временем выполнения одного запроса QueryTimeout
+func WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, QueryTimeout)
+}
+
+// conn - пул соединений или транзакция
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Querier выполняет запросы в контексте ctx, ограничивая каждый запрос временем QueryTimeout.
+// Запрос прерывается, если контекст отменен, например, когда клиент закрыл соединение.
+type Querier struct {
+	ctx  context.Context
+	conn conn
+}
+
+// Conn возвращает Querier для запросов к пулу соединений DB в контексте ctx
+func Conn(ctx context.Context) Querier {
+	return Querier{ctx: ctx, conn: DB}
+}
+
+// Exec выполняет запрос, не возвращающий строк
+func (q Querier) Exec(query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := WithTimeout(q.ctx)
+	defer cancel()
+	return q.conn.ExecContext(ctx, query, args...)
+}
+
+// Query выполняет запрос, возвращающий строки. Время запроса ограничено до закрытия Rows.
+func (q Querier) Query(query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel := WithTimeout(q.ctx)
+	rows, err := q.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRow выполняет запрос, возвращающий не более одной строки. Время запроса ограничено до вызова Scan.
+func (q Querier) QueryRow(query string, args ...interface{}) *Row {
+	ctx, cancel := WithTimeout(q.ctx)
+	return &Row{row: q.conn.QueryRowContext(ctx, query, args...), cancel: cancel}
+}
+
+// Rows - результат запроса, Close завершает запрос и освобождает его контекст
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+// Close закрывает результат запроса
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// Row - строка результата запроса, Scan завершает запрос и освобождает его контекст
+type Row struct {
+	row    *sql.Row
+	cancel context.CancelFunc
+}
+
+// Scan считывает колонки строки в dest
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
+
+// Tx - транзакция, запросы которой выполняются в контексте ctx с ограничением времени QueryTimeout
+type Tx struct {
+	*sql.Tx
+	q Querier
+}
+
+// Begin начинает транзакцию с параметрами opts в контексте ctx, nil - параметры по умолчанию.
+// Если контекст отменен до Commit, транзакция откатывается.
+func Begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, q: Querier{ctx: ctx, conn: tx}}, nil
+}
+
+// Exec выполняет запрос, не возвращающий строк, в транзакции
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.q.Exec(query, args...)
+}
+
+// Query выполняет запрос, возвращающий строки, в транзакции
+func (tx *Tx) Query(query string, args ...interface{}) (*Rows, error) {
+	return tx.q.Query(query, args...)
+}
+
+// QueryRow выполняет запрос, возвращающий не более одной строки, в транзакции
+func (tx *Tx) QueryRow(query string, args ...interface{}) *Row {
+	return tx.q.QueryRow(query, args...)
+}
+
+// IsTimeout проверяет, что запрос прерван по истечении времени выполнения
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	// query_canceled - запрос прерван по statement_timeout сервера
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
+
+// IsUnavailable проверяет, что база данных недоступна: соединение не установлено или разорвано,
+// у сервера не хватает ресурсов или он останавливается, либо запрос отменен вместе с контекстом
+func IsUnavailable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		class := pqErr.Code.Class()
+		return class == "08" || class == "53" || strings.HasPrefix(string(pqErr.Code), "57P")
+	}
+	return false
+}
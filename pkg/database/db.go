@@ -1,15 +1,30 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"os"
+	"strconv"
+	"time"
+
 	_ "github.com/lib/pq" // Драйвер для PostgreSQL
 )
 
 // DB - глобальная переменная для соединения с базой данных
 var DB *sql.DB
 
-// InitDB - функция для инициализации соединения с базой данных
+// Настройки пула соединений по умолчанию
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+)
+
+// InitDB - функция для инициализации соединения с базой данных.
+// Размер пула и время жизни соединений задаются переменными DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+// DB_CONN_MAX_LIFETIME и DB_CONN_MAX_IDLE_TIME, время выполнения запроса - DB_QUERY_TIMEOUT.
 func InitDB(dataSourceName string) {
 	var dbError error
 	DB, dbError = sql.Open("postgres", dataSourceName)
@@ -17,9 +32,43 @@ func InitDB(dataSourceName string) {
 		log.Fatalf("Не удалось открыть соединение с базой данных: %v", dbError)
 	}
 
-	if dbError = DB.Ping(); dbError != nil {
+	DB.SetMaxOpenConns(getIntEnv("DB_MAX_OPEN_CONNS", defaultMaxOpenConns))
+	DB.SetMaxIdleConns(getIntEnv("DB_MAX_IDLE_CONNS", defaultMaxIdleConns))
+	DB.SetConnMaxLifetime(getDurationEnv("DB_CONN_MAX_LIFETIME", defaultConnMaxLifetime))
+	DB.SetConnMaxIdleTime(getDurationEnv("DB_CONN_MAX_IDLE_TIME", defaultConnMaxIdleTime))
+	QueryTimeout = getDurationEnv("DB_QUERY_TIMEOUT", defaultQueryTimeout)
+
+	ctx, cancel := WithTimeout(context.Background())
+	defer cancel()
+	if dbError = DB.PingContext(ctx); dbError != nil {
 		log.Fatalf("Не удалось установить соединение с базой данных: %v", dbError)
 	}
 
 	log.Println("Соединение с базой данных установлено.")
-}
\ No newline at end of file
+}
+
+// getIntEnv возвращает числовое значение переменной окружения или значение по умолчанию
+func getIntEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Неверное значение %s: %s", key, value)
+	}
+	return result
+}
+
+// getDurationEnv возвращает длительность из переменной окружения (например, 30s, 5m) или значение по умолчанию
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Неверное значение %s: %s", key, value)
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+// Package dbtest подменяет соединение database.DB в тестах драйвером,
+// ответы которого на запросы задает сам тест, и считает выполненные запросы.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"goland_api/pkg/database"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// Result - ответ на запрос: строки для SELECT и RETURNING или число измененных строк
+type Result struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+}
+
+// Handler возвращает ответ на запрос query с аргументами args.
+// Вызывается из нескольких горутин, если их использует тест.
+type Handler func(query string, args []driver.Value) (Result, error)
+
+// DB - подмененное соединение с базой данных
+type DB struct {
+	handler   Handler
+	queries   int64
+	commits   int64
+	rollbacks int64
+}
+
+// Open подменяет database.DB соединением, на запросы к которому отвечает handler.
+// Прежнее соединение восстанавливается по завершении теста.
+func Open(tb testing.TB, handler Handler) *DB {
+	tb.Helper()
+
+	db := &DB{handler: handler}
+	previous := database.DB
+	database.DB = sql.OpenDB(connector{db: db})
+	tb.Cleanup(func() {
+		database.DB.Close()
+		database.DB = previous
+	})
+	return db
+}
+
+// Queries возвращает количество запросов, выполненных с последнего Reset
+func (db *DB) Queries() int64 {
+	return atomic.LoadInt64(&db.queries)
+}
+
+// Commits возвращает количество подтвержденных транзакций
+func (db *DB) Commits() int64 {
+	return atomic.LoadInt64(&db.commits)
+}
+
+// Rollbacks возвращает количество отмененных транзакций
+func (db *DB) Rollbacks() int64 {
+	return atomic.LoadInt64(&db.rollbacks)
+}
+
+// Reset обнуляет счетчики запросов и транзакций
+func (db *DB) Reset() {
+	atomic.StoreInt64(&db.queries, 0)
+	atomic.StoreInt64(&db.commits, 0)
+	atomic.StoreInt64(&db.rollbacks, 0)
+}
+
+func (db *DB) handle(query string, args []driver.NamedValue) (Result, error) {
+	atomic.AddInt64(&db.queries, 1)
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return db.handler(query, values)
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dbtest: используйте dbtest.Open")
+}
+
+// conn выполняет запросы без подготовки, транзакции только подсчитываются
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("dbtest: подготовленные запросы не поддерживаются")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{db: c.db}, nil
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{db: c.db}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: result}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.db.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error {
+	atomic.AddInt64(&t.db.commits, 1)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	atomic.AddInt64(&t.db.rollbacks, 1)
+	return nil
+}
+
+type rows struct {
+	result Result
+	next   int
+}
+
+// Columns возвращает имена колонок. Если они не заданы, колонки именуются по номеру.
+func (r *rows) Columns() []string {
+	if r.result.Columns == nil && len(r.result.Rows) > 0 {
+		columns := make([]string, len(r.result.Rows[0]))
+		for i := range columns {
+			columns[i] = fmt.Sprintf("column%d", i+1)
+		}
+		return columns
+	}
+	return r.result.Columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.Rows[r.next])
+	r.next++
+	return nil
+}
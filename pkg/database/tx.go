@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Количество попыток выполнить транзакцию при конфликтах сериализации и взаимных блокировках
+const maxTxAttempts = 3
+
+// Базовая пауза перед повтором транзакции, увеличивается с каждой попыткой
+const txRetryDelay = 20 * time.Millisecond
+
+// Serializable - параметры транзакции с уровнем изоляции SERIALIZABLE.
+// Нужен, когда транзакция проверяет прочитанные данные и изменяет записи на их основе:
+// параллельная транзакция, нарушившая проверку, прерывается конфликтом сериализации и повторяется.
+var Serializable = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+// WithTx выполняет fn в транзакции с уровнем изоляции по умолчанию (READ COMMITTED) в контексте ctx.
+// Если fn вернула ошибку, транзакция откатывается и ошибка возвращается без изменений,
+// иначе транзакция фиксируется. При взаимной блокировке транзакция повторяется целиком,
+// поэтому fn не должна иметь побочных эффектов вне базы данных:
+// письма, уведомления и т.п. отправляются после успешного WithTx.
+func WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	return WithTxOptions(ctx, nil, fn)
+}
+
+// WithTxOptions выполняет fn так же, как WithTx, в транзакции с параметрами opts.
+// Конфликты сериализации возникают только на уровнях REPEATABLE READ и SERIALIZABLE,
+// такие транзакции тоже повторяются целиком.
+func WithTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = runTx(ctx, opts, fn)
+		if err == nil || !IsRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		// Случайная пауза, чтобы конфликтующие транзакции не повторялись одновременно
+		delay := time.Duration(attempt)*txRetryDelay + time.Duration(rand.Int63n(int64(txRetryDelay)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+// runTx выполняет одну попытку транзакции
+func runTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	tx, err := Begin(ctx, opts)
+	if err != nil {
+		return err
+	}
+	// После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsRetryable проверяет, что транзакция прервана из-за конфликта с параллельной транзакцией
+// и может быть успешно выполнена повторно
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	// serialization_failure и deadlock_detected
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"goland_api/pkg/database"
+	"log"
+	"net/http"
+)
+
+// writeAuditLog записывает событие в журнал аудита.
+// Ошибка записи не должна прерывать обработку запроса, поэтому она только логируется.
+func writeAuditLog(r *http.Request, userId *int64, event string, data map[string]interface{}) {
+	ctx := r.Context()
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Println("Ошибка при записи в журнал аудита", event, err)
+		return
+	}
+
+	_, err = database.Conn(ctx).Exec("INSERT INTO audit_logs (user_id, event, ip, user_agent, data) VALUES ($1, $2, $3, $4, $5)",
+		userId, event, getClientIP(r), r.UserAgent(), payload)
+	if err != nil {
+		log.Println("Ошибка при записи в журнал аудита", event, err)
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// Время жизни токенов
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// generateRandomToken возвращает случайную строку из size байт в base64url
+func generateRandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken возвращает SHA-256 хеш одноразового токена, в базе хранится только он
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokenPair выпускает токен обновления в сессии familyId и токен доступа, привязанный к ней
+func issueTokenPair(executor sqlExecutor, r *http.Request, userId int64, name string, familyId string) (error, models.TokenPairResponse) {
+	var pair models.TokenPairResponse
+
+	refreshToken, err := generateRandomToken(32)
+	if err != nil {
+		return err, pair
+	}
+
+	_, err = executor.Exec("INSERT INTO refresh_tokens (user_id, family_id, token_hash, user_agent, ip, expires_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		userId,
+		familyId,
+		hashToken(refreshToken),
+		r.UserAgent(),
+		getClientIP(r),
+		time.Now().Add(refreshTokenTTL),
+	)
+	if err != nil {
+		return err, pair
+	}
+
+	accessToken, err := getNewToken(userId, name, familyId)
+	if err != nil {
+		return err, pair
+	}
+
+	pair.AccessToken = accessToken
+	pair.RefreshToken = refreshToken
+	pair.TokenType = "Bearer"
+	pair.ExpiresIn = int64(accessTokenTTL.Seconds())
+
+	return nil, pair
+}
+
+// startSession открывает новую сессию пользователя и выдает для неё пару токенов
+func startSession(executor sqlExecutor, r *http.Request, userId int64, name string) (error, models.TokenPairResponse) {
+	familyId, err := generateRandomToken(16)
+	if err != nil {
+		return err, models.TokenPairResponse{}
+	}
+	return issueTokenPair(executor, r, userId, name, familyId)
+}
+
+// isSessionActive проверяет, что сессия пользователя не была завершена
+func isSessionActive(ctx context.Context, familyId string, userId int64) (error, bool) {
+	if familyId == "" {
+		return nil, false
+	}
+
+	var active bool
+	err := database.Conn(ctx).QueryRow("SELECT EXISTS (SELECT 1 FROM refresh_tokens WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > now())",
+		familyId, userId).Scan(&active)
+	return err, active
+}
+
+// revokeSession отзывает все токены обновления сессии
+func revokeSession(executor sqlExecutor, familyId string) error {
+	_, err := executor.Exec("UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL", familyId)
+	return err
+}
+
+// revokeUserSessions отзывает все токены обновления пользователя
+func revokeUserSessions(executor sqlExecutor, userId int64) error {
+	_, err := executor.Exec("UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL", userId)
+	return err
+}
+
+// rotateRefreshToken обменивает токен обновления на новую пару токенов.
+// Повторное использование уже обмененного токена считается компрометацией: вся сессия отзывается.
+// Возвращает false, если токен недействителен.
+func rotateRefreshToken(r *http.Request, refreshToken string) (error, models.TokenPairResponse, bool) {
+	ctx := r.Context()
+	var pair models.TokenPairResponse
+	rotated := false
+
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		pair, rotated = models.TokenPairResponse{}, false
+
+		var tokenId int64
+		var userId int64
+		var familyId string
+		var expiresAt time.Time
+		var usedAt, revokedAt sql.NullTime
+		err := tx.QueryRow("SELECT id, user_id, family_id, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE",
+			hashToken(refreshToken)).Scan(&tokenId, &userId, &familyId, &expiresAt, &usedAt, &revokedAt)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if usedAt.Valid || revokedAt.Valid {
+			if usedAt.Valid && !revokedAt.Valid {
+				log.Println("Повторное использование токена обновления, сессия отозвана", userId, familyId)
+			}
+			return revokeSession(tx, familyId)
+		}
+		if !expiresAt.After(time.Now()) {
+			return nil
+		}
+
+		errorUser, userView := getUserViewById(ctx, userId)
+		if errorUser != nil {
+			return nil
+		}
+
+		_, err = tx.Exec("UPDATE refresh_tokens SET used_at = now() WHERE id = $1", tokenId)
+		if err != nil {
+			return err
+		}
+
+		err, pair = issueTokenPair(tx, r, userView.ID, userView.Name, familyId)
+		if err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil || !rotated {
+		return err, models.TokenPairResponse{}, false
+	}
+
+	return nil, pair, true
+}
+
+// @Summary Обновление пары токенов
+// @Description Обмен токена обновления на новую пару токенов. Каждый токен обновления можно использовать только один раз
+// @Tags Аутентификация
+// @Param refreshToken body models.RefreshTokenRequest true "Токен обновления"
+// @Accept  application/json
+// @Produce  application/json
+// @Success 200 {object} models.TokenPairResponse
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Router /api/auth/refresh [post]
+func Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var refreshRequest models.RefreshTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&refreshRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(refreshRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			errRotate, pair, ok := rotateRefreshToken(r, refreshRequest.RefreshToken)
+			if errRotate != nil {
+				log.Println("Ошибка при обновлении токенов", errRotate)
+				sendDatabaseError(w, errRotate, http.StatusInternalServerError, "Ошибка при обновлении токенов")
+				return
+			}
+			if !ok {
+				SendJSONError(w, http.StatusUnauthorized, "Недействительный токен обновления")
+				return
+			}
+
+			json.NewEncoder(w).Encode(pair)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Выход
+// @Description Завершение текущей сессии: токен обновления и токены доступа сессии становятся недействительными
+// @Tags Аутентификация
+// @Produce  application/json
+// @Success 204 No Content
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/auth/logout [post]
+func Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if err := revokeSession(database.Conn(ctx), getAuthSessionID(r)); err != nil {
+				log.Println("Ошибка при завершении сессии", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при завершении сессии")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Выход со всех устройств
+// @Description Завершение всех сессий текущего пользователя
+// @Tags Аутентификация
+// @Produce  application/json
+// @Success 204 No Content
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/auth/logout-all [post]
+func LogoutAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if err := revokeUserSessions(database.Conn(ctx), getAuthUser(r).ID); err != nil {
+				log.Println("Ошибка при завершении сессий", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при завершении сессий")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
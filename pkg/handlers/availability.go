@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Ограничения на параметры календаря занятости
+const (
+	defaultAvailabilityPeriod = 7 * 24 * time.Hour
+	maxAvailabilityPeriod     = 31 * 24 * time.Hour
+	defaultAvailabilitySlot   = 60 * time.Minute
+	minAvailabilitySlot       = 15 * time.Minute
+	maxAvailabilitySlot       = 24 * time.Hour
+)
+
+// getFieldBusyIntervals возвращает занятые арендами интервалы площадки в указанном периоде
+func getFieldBusyIntervals(ctx context.Context, fieldId int64, from time.Time, to time.Time) (error, []models.AvailabilityInterval) {
+	busy := []models.AvailabilityInterval{}
+
+	rows, err := database.Conn(ctx).Query(
+		"SELECT id, start_date, end_date FROM rentals "+
+			"WHERE field_id = $1 AND "+rentalOccupiesFieldCondition+" "+
+			"AND tstzrange(start_date, end_date, '[)') && tstzrange($2, $3, '[)') "+
+			"ORDER BY start_date", fieldId, from, to)
+	if err != nil {
+		return err, busy
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rentalId int64
+		var interval models.AvailabilityInterval
+		if err := rows.Scan(&rentalId, &interval.Start, &interval.End); err != nil {
+			return err, busy
+		}
+		interval.Status = models.AvailabilityBusy
+		interval.RentalID = &rentalId
+		busy = append(busy, interval)
+	}
+
+	return rows.Err(), busy
+}
+
+// buildAvailability строит список свободных и недоступных интервалов периода [from, to).
+// Свободное время нарезается на слоты длительностью slot, занятые и закрытые интервалы обрезаются по границам периода.
+func buildAvailability(from time.Time, to time.Time, slot time.Duration, blocked []models.AvailabilityInterval) []models.AvailabilityInterval {
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Start.Before(blocked[j].Start)
+	})
+
+	intervals := []models.AvailabilityInterval{}
+	cursor := from
+	for _, interval := range blocked {
+		// Не допускаем наложения интервалов, например аренды и закрытия по расписанию
+		if interval.Start.Before(cursor) {
+			interval.Start = cursor
+		}
+		if interval.End.After(to) {
+			interval.End = to
+		}
+		if !interval.End.After(interval.Start) {
+			continue
+		}
+
+		intervals = appendFreeSlots(intervals, cursor, interval.Start, slot)
+		intervals = append(intervals, interval)
+		cursor = interval.End
+	}
+
+	return appendFreeSlots(intervals, cursor, to, slot)
+}
+
+// appendFreeSlots нарезает свободный промежуток [start, end) на слоты
+func appendFreeSlots(intervals []models.AvailabilityInterval, start time.Time, end time.Time, slot time.Duration) []models.AvailabilityInterval {
+	for slotStart := start; slotStart.Before(end); slotStart = slotStart.Add(slot) {
+		slotEnd := slotStart.Add(slot)
+		if slotEnd.After(end) {
+			slotEnd = end
+		}
+		intervals = append(intervals, models.AvailabilityInterval{
+			Start:  slotStart,
+			End:    slotEnd,
+			Status: models.AvailabilityFree,
+		})
+	}
+
+	return intervals
+}
+
+// Документация для метода GetFieldAvailability
+// @Summary Календарь занятости площадки
+// @Description Получение свободных, занятых и закрытых по расписанию интервалов площадки за период
+// @Tags Площадки
+// @Param slug path string true "Slug площадки"
+// @Param from query string false "Начало периода (2006-01-02 15:04:05), по умолчанию начало текущего дня"
+// @Param to query string false "Окончание периода (2006-01-02 15:04:05), по умолчанию через 7 дней"
+// @Param slot query string false "Длительность слота, например 30m или 1h (по умолчанию 60m)"
+// @Produces application/json
+// @Success 200 {object} models.FieldAvailability
+// @Failure 400 Bad Request
+// @Failure 404 Not Found
+// @Router /api/fields/{slug}/availability [get]
+func GetFieldAvailability() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+		slug := vars["slug"]
+
+		errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
+		if errorResponse != nil {
+			sendDatabaseError(w, errorResponse, http.StatusNotFound, "Не смог найти площадку: "+slug)
+			return
+		}
+
+		queryParams := r.URL.Query()
+
+		now := time.Now().In(appLocation())
+		from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if value := queryParams.Get("from"); value != "" {
+			parsed, err := parseDateParam(value)
+			if err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			from = parsed
+		}
+
+		to := from.Add(defaultAvailabilityPeriod)
+		if value := queryParams.Get("to"); value != "" {
+			parsed, err := parseDateParam(value)
+			if err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			to = parsed
+		}
+
+		if !to.After(from) {
+			SendJSONError(w, http.StatusBadRequest, "Окончание периода должно быть позже начала")
+			return
+		}
+		if to.Sub(from) > maxAvailabilityPeriod {
+			SendJSONError(w, http.StatusBadRequest, "Период не может превышать 31 день")
+			return
+		}
+
+		slot := defaultAvailabilitySlot
+		if value := queryParams.Get("slot"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				SendJSONError(w, http.StatusBadRequest, "Неверный формат длительности слота: "+value)
+				return
+			}
+			slot = parsed
+		}
+		if slot < minAvailabilitySlot || slot > maxAvailabilitySlot {
+			SendJSONError(w, http.StatusBadRequest, "Длительность слота должна быть от 15m до 24h")
+			return
+		}
+
+		errBusy, busy := getFieldBusyIntervals(ctx, fieldView.ID, from, to)
+		if errBusy != nil {
+			log.Println("Ошибка при получении занятости площадки", errBusy)
+			sendDatabaseError(w, errBusy, http.StatusInternalServerError, "Ошибка при получении занятости площадки")
+			return
+		}
+
+		errClosed, closed := getFieldClosedIntervals(ctx, fieldView.ID, from, to)
+		if errClosed != nil {
+			log.Println("Ошибка при получении расписания площадки", errClosed)
+			sendDatabaseError(w, errClosed, http.StatusInternalServerError, "Ошибка при получении расписания площадки")
+			return
+		}
+
+		availability := models.FieldAvailability{
+			FieldID:   fieldView.ID,
+			Slug:      fieldView.Slug,
+			From:      from,
+			To:        to,
+			Slot:      slot.String(),
+			Intervals: buildAvailability(from, to, slot, append(busy, closed...)),
+		}
+
+		json.NewEncoder(w).Encode(availability)
+	}
+}
@@ -1,248 +1,194 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
 	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
 	"goland_api/pkg/utils"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
 
-// GetFields возвращает функцию-обработчик, которая получает все площадки из базы данных.
-// Выполняет запрос к базе данных для получения всех площадок и возвращает их в виде JSON-ответа.
+// rowScanner - строка результата запроса (*sql.Row или *sql.Rows)
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// parseFieldSearchFilter разбирает параметры фильтрации списка площадок и формирует условия запроса
+func parseFieldSearchFilter(queryParams url.Values) (error, models.FieldSearchFilter, sqlConditions) {
+	var filter models.FieldSearchFilter
+	var conditions sqlConditions
+
+	conditions.add("deleted_at IS NULL")
+
+	if search := strings.TrimSpace(queryParams.Get("search")); search != "" {
+		filter.Search = &search
+		pattern := containsPattern(search)
+		conditions.add("(name ILIKE ? ESCAPE '\\' OR description ILIKE ? ESCAPE '\\' OR address ILIKE ? ESCAPE '\\')", pattern, pattern, pattern)
+	}
+
+	if city := strings.TrimSpace(queryParams.Get("city")); city != "" {
+		filter.City = &city
+		conditions.add("LOWER(city) = LOWER(?)", city)
+	}
+
+	// Фильтры по наличию удобств
+	amenities := []struct {
+		param  string
+		column string
+		target **bool
+	}{
+		{"for_disabled", "for_disabled", &filter.ForDisabled},
+		{"parking", "parking", &filter.Parking},
+		{"toilet", "toilet", &filter.Toilet},
+		{"dressing", "dressing", &filter.Dressing},
+		{"display", "display", &filter.Display},
+	}
+	for _, amenity := range amenities {
+		value, err := getBoolParam(queryParams, amenity.param)
+		if err != nil {
+			return err, filter, conditions
+		}
+		if value != nil {
+			*amenity.target = value
+			conditions.add("COALESCE("+amenity.column+", false) = ?", *value)
+		}
+	}
+
+	if value := queryParams.Get("min_places"); value != "" {
+		minPlaces, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Неверное значение параметра 'min_places': %s", value), filter, conditions
+		}
+		filter.MinPlaces = &minPlaces
+		conditions.add("places >= ?", minPlaces)
+	}
+
+	if value := queryParams.Get("min_square"); value != "" {
+		minSquare, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Неверное значение параметра 'min_square': %s", value), filter, conditions
+		}
+		filter.MinSquare = &minSquare
+		conditions.add("square >= ?", minSquare)
+	}
+
+	return nil, filter, conditions
+}
+
+// GetFields возвращает функцию-обработчик, которая получает площадки из базы данных.
+// Поддерживает постраничный вывод, фильтрацию по городу и удобствам, а также поиск по названию, описанию и адресу.
 //
-// @Summary Получить все площадки
-// @Description Получить список всех площадок
+// @Summary Получить список площадок
+// @Description Получить список площадок с пагинацией, фильтрами и поиском
 // @Tags Площадки
 // @Accept application/json
 // @Produces application/json
-// @Success 200 {object} []models.FieldView
+// @Param page query int false "Номер страницы"
+// @Param per_page query int false "Количество элементов на странице"
+// @Param search query string false "Поиск по названию, описанию и адресу"
+// @Param city query string false "Город"
+// @Param for_disabled query bool false "Подходит для инвалидов"
+// @Param parking query bool false "Наличие парковки"
+// @Param toilet query bool false "Наличие туалета"
+// @Param dressing query bool false "Наличие раздевалки"
+// @Param display query bool false "Наличие цифрового табло"
+// @Param min_places query int false "Минимальное количество мест"
+// @Param min_square query int false "Минимальная площадь"
+// @Success 200 {object} models.PaginationResponse{data=[]models.FieldView}
 // @Failure 400 Bad Request
 // @Failure 500 Internal Server Error
 // @Router /api/fields [get]
 func GetFields() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var rows *sql.Rows
-		var err error
+		ctx := r.Context()
+		queryParams := r.URL.Query()
+		page, perPage := getPagination(queryParams)
+		offset := (page - 1) * perPage
+
+		errFilter, filter, conditions := parseFieldSearchFilter(queryParams)
+		if errFilter != nil {
+			SendJSONError(w, http.StatusBadRequest, errFilter.Error())
+			return
+		}
 
-		rows, err = database.DB.Query("SELECT id, name, slug, description, city, address, logo, media, responsible_id, status, created_at FROM fields")
+		var totalCount int
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(id) FROM fields"+conditions.where(), conditions.args...).Scan(&totalCount)
+		if err != nil {
+			log.Println("Ошибка в SQL запросе GetFields", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка площадок")
+			return
+		}
+		pages := int(math.Ceil(float64(totalCount) / float64(perPage)))
 
+		query := "SELECT " + repository.FieldViewColumns + " FROM fields" + conditions.where() +
+			" ORDER BY id LIMIT " + conditions.arg(perPage) + " OFFSET " + conditions.arg(offset)
+		rows, err := database.Conn(ctx).Query(query, conditions.args...)
 		if err != nil {
 			log.Println("Ошибка в SQL запросе GetFields", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка площадок")
+			return
 		}
 		defer rows.Close()
-		fields := []models.FieldView{}
-		for rows.Next() {
-			var fieldView models.FieldView
-			var logo sql.NullString
-			var media sql.NullString
-
-			// Add a temporary variable to hold the responsible_id
-			var responsibleID sql.NullInt64
-			if err := rows.Scan(
-				&fieldView.ID,
-				&fieldView.Name,
-				&fieldView.Slug,
-				&fieldView.Description,
-				&fieldView.City,
-				&fieldView.Address,
-				&logo,
-				&media,
-				&responsibleID,
-				&fieldView.Status,
-				&fieldView.CreatedAt,
-			); err != nil {
-				log.Println("Ошибка в Scan", err)
-			}
-
-			// Fetch the responsible user data if responsible_id is not null
-			if responsibleID.Valid {
-				errorResponse, userView := getUserViewById(int64(responsibleID.Int64))
-				if errorResponse != nil {
-					log.Println("Ошибка при получении данных ответственного пользователя", errorResponse.Error())
-				} else {
-					fieldView.Responsible = userView
-				}
-			}
 
-			if logo.Valid {
-				if logo.String != "" {
-					var logoFile models.Media
-					errorMedia, logoFile := getOneMedia(logo.String)
-					if errorMedia != nil {
-						log.Println("Ошибка в Logo", logo.String, errorMedia.Error())
-					} else {
-						fieldView.Logo = &logoFile
-					}
-				}
-			}
-			if media.Valid && len(media.String) > 0 {
-				var mediaList []models.Media
-				var mediaFiles []string
-				err := json.Unmarshal([]byte(media.String), &mediaFiles)
-				if err != nil {
-					log.Println("Ошибка при парсинге JSON:", err)
-				}
-				for _, mediaFile := range mediaFiles {
-					if mediaFile != "" {
-						errorMedia, mediaFile := getOneMedia(mediaFile)
-						if errorMedia != nil {
-							log.Println("Ошибка в Media", mediaFile, errorMedia.Error())
-						} else {
-							mediaList = append(mediaList, mediaFile)
-						}
-					}
-				}
-				fieldView.Media = &mediaList
+		var loader repository.FieldLoader
+		for rows.Next() {
+			if errScan := loader.Scan(rows); errScan != nil {
+				log.Println("Ошибка в Scan", errScan)
 			}
-			fields = append(fields, fieldView)
 		}
 		if err := rows.Err(); err != nil {
 			log.Println("Ошибка в Row Next", err)
 		}
 
-		json.NewEncoder(w).Encode(fields)
-	}
-}
-
-// getOneFieldById получает площадку из базы данных по её ID.
-// Выполняет запрос к базе данных для получения площадки с указанным ID и возвращает данные площадки вместе с любой возникшей ошибкой.
-
-func getOneFieldById(paramId int64) (error, models.FieldView) {
-	var fieldView models.FieldView
-	var logo sql.NullString
-	var media sql.NullString
-
-	// Add a temporary variable to hold the responsible_id
-	var responsibleID sql.NullInt64
-	err := database.DB.QueryRow("SELECT id, name, slug, description, city, address, logo, media, responsible_id, status, created_at FROM fields WHERE id = $1", int64(paramId)).Scan(
-		&fieldView.ID,
-		&fieldView.Name,
-		&fieldView.Slug,
-		&fieldView.Description,
-		&fieldView.City,
-		&fieldView.Address,
-		&logo,
-		&media,
-		&responsibleID,
-		&fieldView.Status,
-		&fieldView.CreatedAt,
-	)
-
-	// Fetch the responsible user data if responsible_id is not null
-	if responsibleID.Valid {
-		errorResponse, userView := getUserViewById(int64(responsibleID.Int64))
-		if errorResponse != nil {
-			log.Println("Ошибка при получении данных ответственного пользователя", errorResponse.Error())
-		} else {
-			fieldView.Responsible = userView
-		}
-	}
-	if err != nil {
-		return err, fieldView
-	}
-
-	if logo.Valid {
-		var logoFile models.Media
-		errorMedia, logoFile := getOneMedia(logo.String)
-		if errorMedia != nil {
-			log.Println(errorMedia.Error())
-		} else {
-			fieldView.Logo = &logoFile
+		errLoad, fieldViews := loader.Load(ctx)
+		if errLoad != nil {
+			log.Println("Ошибка в SQL запросе GetFields", errLoad)
+			sendDatabaseError(w, errLoad, http.StatusInternalServerError, "Ошибка при получении списка площадок")
+			return
 		}
-	}
-	if media.Valid && len(media.String) > 0 {
-		var mediaList []models.Media
-		var mediaFiles []string
-		err := json.Unmarshal([]byte(media.String), &mediaFiles)
-		if err != nil {
-			log.Println("Ошибка при парсинге JSON:", err)
+		fields := []interface{}{}
+		for _, fieldView := range fieldViews {
+			fields = append(fields, fieldView)
 		}
-		for _, mediaFile := range mediaFiles {
-			errorMedia, mediaFile := getOneMedia(mediaFile)
-			if errorMedia != nil {
-				log.Println(errorMedia.Error())
-			} else {
-				mediaList = append(mediaList, mediaFile)
-			}
+
+		response := models.PaginationResponse{
+			Pagination: models.Pagination{
+				Page:       page,
+				PerPage:    perPage,
+				TotalPages: pages,
+				TotalItems: totalCount,
+			},
+			Filter: filter,
+			Data:   fields,
 		}
-		fieldView.Media = &mediaList
+
+		json.NewEncoder(w).Encode(response)
 	}
+}
 
-	return err, fieldView
+// getOneFieldById получает площадку из базы данных по её ID.
+// Выполняет запрос к базе данных для получения площадки с указанным ID и возвращает данные площадки вместе с любой возникшей ошибкой.
+func getOneFieldById(ctx context.Context, paramId int64) (error, models.FieldView) {
+	return repository.FieldViewById(ctx, paramId)
 }
 
 // getOneFieldBySlug получает площадку из базы данных по её slug.
 // Выполняет запрос к базе данных для получения площадки с указанным slug и возвращает данные площадки вместе с любой возникшей ошибкой.
-func getOneFieldBySlug(slug string) (error, models.FieldView) {
-	var fieldView models.FieldView
-	var logo sql.NullString
-	var media sql.NullString
-
-	// Add a temporary variable to hold the responsible_id
-	var responsibleID sql.NullInt64
-	err := database.DB.QueryRow("SELECT id, name, slug, description, city, address, logo, media, responsible_id, status, created_at FROM fields WHERE slug = $1", slug).Scan(
-		&fieldView.ID,
-		&fieldView.Name,
-		&fieldView.Slug,
-		&fieldView.Description,
-		&fieldView.City,
-		&fieldView.Address,
-		&logo,
-		&media,
-		&responsibleID,
-		&fieldView.Status,
-		&fieldView.CreatedAt,
-	)
-
-	// Fetch the responsible user data if responsible_id is not null
-	if responsibleID.Valid {
-		errorResponse, userView := getUserViewById(int64(responsibleID.Int64))
-		if errorResponse != nil {
-			log.Println("Ошибка при получении данных ответственного пользователя", errorResponse.Error())
-		} else {
-			fieldView.Responsible = userView
-		}
-	}
-	if err != nil {
-		return err, fieldView
-	}
-
-	if logo.Valid {
-		var logoFile models.Media
-		errorMedia, logoFile := getOneMedia(logo.String)
-		if errorMedia != nil {
-			log.Println(errorMedia.Error())
-		} else {
-			fieldView.Logo = &logoFile
-		}
-	}
-	if media.Valid && len(media.String) > 0 {
-		var mediaList []models.Media
-		var mediaFiles []string
-		err := json.Unmarshal([]byte(media.String), &mediaFiles)
-		if err != nil {
-			log.Println("Ошибка при парсинге JSON:", err)
-		}
-		for _, mediaFile := range mediaFiles {
-			errorMedia, mediaFile := getOneMedia(mediaFile)
-			if errorMedia != nil {
-				log.Println(errorMedia.Error())
-			} else {
-				mediaList = append(mediaList, mediaFile)
-			}
-		}
-		fieldView.Media = &mediaList
-	}
-
-	return err, fieldView
+func getOneFieldBySlug(ctx context.Context, slug string) (error, models.FieldView) {
+	return repository.FieldViewBySlug(ctx, slug)
 }
 
 // GetField возвращает функцию-обработчик, которая получает конкретную площадку по её slug.
@@ -258,12 +204,13 @@ func getOneFieldBySlug(slug string) (error, models.FieldView) {
 // @Router /api/fields/{slug} [get]
 func GetField() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		vars := mux.Vars(r)
 		slug := vars["slug"]
 
-		errorResponse, fieldView := getOneFieldBySlug(slug)
+		errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 			return
 		}
 
@@ -275,6 +222,7 @@ func GetField() http.HandlerFunc {
 // Декодирует тело JSON-запроса и проверяет данные площадки с помощью структурной валидации.
 // Также проверяет наличие дубликатов площадок по имени и городу.
 func validateCreateFieldRequest(r *http.Request) (error, models.CreateFieldRequest) {
+	ctx := r.Context()
 	var req models.CreateFieldRequest
 	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
 		return validation, req
@@ -286,7 +234,7 @@ func validateCreateFieldRequest(r *http.Request) (error, models.CreateFieldReque
 
 	// Проверка на дубли по полям name и city
 	var count int
-	err := database.DB.QueryRow("SELECT COUNT(*) FROM fields WHERE name = $1 AND city = $2", req.Name, req.City).Scan(&count)
+	err := database.Conn(ctx).QueryRow("SELECT COUNT(*) FROM fields WHERE name = $1 AND city = $2 AND deleted_at IS NULL", req.Name, req.City).Scan(&count)
 	if err != nil {
 		return err, req
 	}
@@ -302,7 +250,7 @@ func validateCreateFieldRequest(r *http.Request) (error, models.CreateFieldReque
 	}
 
 	// Проверка на дубли по полю slug
-	err = database.DB.QueryRow("SELECT COUNT(*) FROM fields WHERE slug = $1", slug).Scan(&count)
+	err = database.Conn(ctx).QueryRow("SELECT COUNT(*) FROM fields WHERE slug = $1 AND deleted_at IS NULL", slug).Scan(&count)
 	if err != nil {
 		return err, req
 	}
@@ -317,6 +265,9 @@ func validateCreateFieldRequest(r *http.Request) (error, models.CreateFieldReque
 // validateUpdatedAtFieldRequest проверяет данные для обновления площадки.
 // Декодирует тело JSON-запроса и проверяет данные обновления площадки с помощью структурной валидации.
 func validateUpdatedAtFieldRequest(r *http.Request, fieldView models.FieldView) (error, models.UpdateFieldRequest) {
+	ctx := r.Context()
+	auth := getAuthUser(r)
+
 	var req models.UpdateFieldRequest
 	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
 		return validation, req
@@ -329,7 +280,7 @@ func validateUpdatedAtFieldRequest(r *http.Request, fieldView models.FieldView)
 	if req.Name != fieldView.Name || req.City != fieldView.City {
 		// Проверка на дубли по полям name и city
 		var count int
-		err := database.DB.QueryRow("SELECT COUNT(*) FROM fields WHERE name = $1 AND city = $2", req.Name, req.City).Scan(&count)
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(*) FROM fields WHERE name = $1 AND city = $2 AND deleted_at IS NULL", req.Name, req.City).Scan(&count)
 		if err != nil {
 			return err, req
 		}
@@ -348,7 +299,7 @@ func validateUpdatedAtFieldRequest(r *http.Request, fieldView models.FieldView)
 	if slug != fieldView.Slug {
 		var count int
 		// Проверка на дубли по полю slug
-		err := database.DB.QueryRow("SELECT COUNT(*) FROM fields WHERE slug = $1", slug).Scan(&count)
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(*) FROM fields WHERE slug = $1 AND deleted_at IS NULL", slug).Scan(&count)
 		if err != nil {
 			return err, req
 		}
@@ -358,16 +309,8 @@ func validateUpdatedAtFieldRequest(r *http.Request, fieldView models.FieldView)
 		}
 	}
 	// Проверка на право редактирования
-	if IsAdmin(*AUTH) {
-		// Admins can edit any field
-	} else if IsUser(*AUTH) {
-		// Users can only edit fields where they are the responsible person
-		if fieldView.Responsible.ID != AUTH.ID {
-			validation := fmt.Errorf("Пользователь не имеет прав на редактирование этой площадки")
-			return validation, req
-		}
-	} else {
-		validation := fmt.Errorf("Пользователь не имеет прав на редактирование площадок")
+	if !Can(*auth, models.PermissionFieldsUpdate, fieldView.Responsible.ID) {
+		validation := fmt.Errorf("Пользователь не имеет прав на редактирование этой площадки")
 		return validation, req
 	}
 
@@ -385,9 +328,14 @@ func validateUpdatedAtFieldRequest(r *http.Request, fieldView models.FieldView)
 // @Produces application/json
 // @Success 201 {object} models.FieldView
 // @Failure 422 Unprocessable Entity
+// @Failure 415 Unsupported Media Type
+// @Failure 403 {object} models.ErrorResponse
 // @Router /api/fields [post]
 func CreateField() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -421,37 +369,68 @@ func CreateField() http.HandlerFunc {
 			field.Description = fieldRequest.Description
 			field.Address = fieldRequest.Address
 
+			if errMedia, code := validateMediaRefs(ctx, *auth, models.MediaEntityField, 0, fieldRequest.Logo, fieldRequest.Media); errMedia != nil {
+				sendDatabaseError(w, errMedia, code, errMedia.Error())
+				return
+			}
+
 			// Use logo ID directly
 			field.Logo = fieldRequest.Logo
 
 			// Use media IDs directly
 			field.Media = fieldRequest.Media
 
-			var err error
-			// Use responsible_id from request if provided, otherwise use AUTH.ID
-			responsibleID := AUTH.ID
+			if (fieldRequest.Lat == nil) != (fieldRequest.Lon == nil) {
+				SendJSONError(w, http.StatusBadRequest, "Координаты площадки должны содержать широту и долготу")
+				return
+			}
+			field.Lat, field.Lon = fieldRequest.Lat, fieldRequest.Lon
+			if field.Lat == nil {
+				field.Lat, field.Lon = geocodeFieldAddress(field.City, field.Address)
+			}
+
+			// Use responsible_id from request if provided, otherwise use the current user
+			responsibleID := auth.ID
 			if fieldRequest.Responsible.ID != 0 {
 				responsibleID = fieldRequest.Responsible.ID
 			}
-			err = database.DB.QueryRow("INSERT INTO fields (name, slug, description, city, address, logo, media, responsible_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
-				field.Name,
-				field.Slug,
-				field.Description,
-				field.City,
-				field.Address,
-				field.Logo,
-				field.Media,
-				responsibleID,
-			).Scan(&field.ID)
+			// Площадка и привязки её медиафайлов сохраняются вместе
+			err := database.WithTx(ctx, func(tx *database.Tx) error {
+				err := tx.QueryRow("INSERT INTO fields (name, slug, description, city, address, logo, media, responsible_id, location, square, info, places, dressing, toilet, display, parking, for_disabled, lat, lon) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, '[]'::jsonb), $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id",
+					field.Name,
+					field.Slug,
+					field.Description,
+					field.City,
+					field.Address,
+					field.Logo,
+					field.Media,
+					responsibleID,
+					fieldRequest.Location,
+					fieldRequest.Square,
+					fieldRequest.Info,
+					fieldRequest.Places,
+					fieldRequest.Dressing,
+					fieldRequest.Toilet,
+					fieldRequest.Display,
+					fieldRequest.Parking,
+					fieldRequest.ForDisabled,
+					field.Lat,
+					field.Lon,
+				).Scan(&field.ID)
+				if err != nil {
+					return err
+				}
+				return syncMediaAttachments(tx, models.MediaEntityField, field.ID, field.Logo, field.Media)
+			})
 			if err != nil {
 				log.Println(err)
-				SendJSONError(w, http.StatusInternalServerError, "Failed to create field")
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Failed to create field")
 				return
 			}
 
-			errField, fieldView := getOneFieldById(int64(field.ID))
+			errField, fieldView := getOneFieldById(ctx, int64(field.ID))
 			if errField != nil {
-				SendJSONError(w, http.StatusBadRequest, errField.Error())
+				sendDatabaseError(w, errField, http.StatusBadRequest, errField.Error())
 				return
 			}
 			json.NewEncoder(w).Encode(fieldView)
@@ -476,10 +455,15 @@ func CreateField() http.HandlerFunc {
 // @Param slug path string true "Slug площадки"
 // @Success 200 {object} models.FieldView
 // @Failure 422 Unprocessable Entity
+// @Failure 415 Unsupported Media Type
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 Not Found
 // @Router /api/fields/{slug} [put]
 func UpdateField() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -493,25 +477,17 @@ func UpdateField() http.HandlerFunc {
 
 		vars := mux.Vars(r)
 		slug := vars["slug"]
-		errorResponse, fieldView := getOneFieldBySlug(slug)
+		errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
 
 		// Check if there was an error retrieving the field
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, "Не смог найти площадку: "+slug)
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, "Не смог найти площадку: "+slug)
 			return
 		}
 
 		// Проверка на право редактирования
-		if IsAdmin(*AUTH) {
-			// Admins can edit any field
-		} else if IsUser(*AUTH) {
-			// Users can only edit fields where they are the responsible person
-			if fieldView.Responsible.ID != AUTH.ID {
-				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на редактирование этой площадки")
-				return
-			}
-		} else {
-			SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на редактирование площадок")
+		if !Can(*auth, models.PermissionFieldsUpdate, fieldView.Responsible.ID) {
+			SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на редактирование этой площадки")
 			return
 		}
 
@@ -537,27 +513,43 @@ func UpdateField() http.HandlerFunc {
 			field.Description = fieldRequest.Description
 			field.Address = fieldRequest.Address
 
+			if errMedia, code := validateMediaRefs(ctx, *auth, models.MediaEntityField, fieldView.ID, fieldRequest.Logo, fieldRequest.Media); errMedia != nil {
+				sendDatabaseError(w, errMedia, code, errMedia.Error())
+				return
+			}
+
 			// Use logo ID directly
 			field.Logo = fieldRequest.Logo
 
 			// Use media IDs directly
 			field.Media = fieldRequest.Media
 
+			if (fieldRequest.Lat == nil) != (fieldRequest.Lon == nil) {
+				SendJSONError(w, http.StatusBadRequest, "Координаты площадки должны содержать широту и долготу")
+				return
+			}
+			// Координаты определяются заново только при изменении адреса
+			field.Lat, field.Lon = fieldRequest.Lat, fieldRequest.Lon
+			if field.Lat == nil {
+				if field.City == fieldView.City && field.Address == fieldView.Address && fieldView.Lat != nil {
+					field.Lat, field.Lon = fieldView.Lat, fieldView.Lon
+				} else {
+					field.Lat, field.Lon = geocodeFieldAddress(field.City, field.Address)
+				}
+			}
+
 			// Update responsible_id if provided in request
-			var errUpdate error
+			responsibleID := fieldView.Responsible.ID
 			if fieldRequest.Responsible.ID != 0 {
-				_, errUpdate = database.DB.Exec("UPDATE fields SET name = $1, slug = $2, description = $3, city = $4, address = $5, logo = $6, media = $7, responsible_id = $8 WHERE id = $9",
-					field.Name,
-					field.Slug,
-					field.Description,
-					field.City,
-					field.Address,
-					field.Logo,
-					field.Media,
-					fieldRequest.Responsible.ID,
-					fieldView.ID)
-			} else {
-				_, errUpdate = database.DB.Exec("UPDATE fields SET name = $1, slug = $2, description = $3, city = $4, address = $5, logo = $6, media = $7 WHERE id = $8",
+				responsibleID = fieldRequest.Responsible.ID
+			}
+			var responsible *int64
+			if responsibleID != 0 {
+				responsible = &responsibleID
+			}
+			errUpdate := database.WithTx(ctx, func(tx *database.Tx) error {
+				_, err := tx.Exec("UPDATE fields SET name = $1, slug = $2, description = $3, city = $4, address = $5, logo = $6, media = $7, responsible_id = $8, "+
+					"location = COALESCE($9, location), square = $10, info = $11, places = $12, dressing = $13, toilet = $14, display = $15, parking = $16, for_disabled = $17, lat = $18, lon = $19, updated_at = now() WHERE id = $20",
 					field.Name,
 					field.Slug,
 					field.Description,
@@ -565,17 +557,33 @@ func UpdateField() http.HandlerFunc {
 					field.Address,
 					field.Logo,
 					field.Media,
+					responsible,
+					fieldRequest.Location,
+					fieldRequest.Square,
+					fieldRequest.Info,
+					fieldRequest.Places,
+					fieldRequest.Dressing,
+					fieldRequest.Toilet,
+					fieldRequest.Display,
+					fieldRequest.Parking,
+					fieldRequest.ForDisabled,
+					field.Lat,
+					field.Lon,
 					fieldView.ID)
-			}
+				if err != nil {
+					return err
+				}
+				return syncMediaAttachments(tx, models.MediaEntityField, fieldView.ID, field.Logo, field.Media)
+			})
 			if errUpdate != nil {
 				log.Println(errUpdate)
-				SendJSONError(w, http.StatusBadRequest, "Возникла ошибка при обновлении: "+errUpdate.Error())
+				sendDatabaseError(w, errUpdate, http.StatusBadRequest, "Возникла ошибка при обновлении: "+errUpdate.Error())
 				return
 			}
 
-			errorResponse, fieldView = getOneFieldBySlug(field.Slug)
+			errorResponse, fieldView = getOneFieldBySlug(ctx, field.Slug)
 			if errorResponse != nil {
-				SendJSONError(w, http.StatusBadRequest, "Возникла ошибка при получении ответа: "+errorResponse.Error())
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, "Возникла ошибка при получении ответа: "+errorResponse.Error())
 				return
 			}
 			json.NewEncoder(w).Encode(fieldView)
@@ -592,7 +600,7 @@ func UpdateField() http.HandlerFunc {
 // Удаляет площадку из базы данных и возвращает сообщение об успешном удалении.
 //
 // @Summary Удалить площадку по ID
-// @Description Удалить спортивную площадку по её идентификатору
+// @Description Мягкое удаление спортивной площадки вместе с её арендами
 // @Tags Площадки
 // @Param slug path string true "Slug площадки"
 // @Success 200 {string} string "Площадка удалена"
@@ -600,6 +608,7 @@ func UpdateField() http.HandlerFunc {
 // @Router /api/fields/{slug} [delete]
 func DeleteField() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -615,15 +624,15 @@ func DeleteField() http.HandlerFunc {
 
 			vars := mux.Vars(r)
 			slug := vars["slug"]
-			errorResponse, fieldView := getOneFieldBySlug(slug)
+			errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
 
 			if errorResponse != nil {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			} else {
-				_, err := database.DB.Exec("DELETE FROM fields WHERE id = $1", fieldView.ID)
-				if err != nil {
-					w.WriteHeader(http.StatusNotFound)
+				if err := deleteField(ctx, fieldView.ID); err != nil {
+					log.Println("Ошибка при удалении площадки", err)
+					sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при удалении площадки")
 					return
 				}
 
@@ -637,3 +646,67 @@ func DeleteField() http.HandlerFunc {
 		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
 	}
 }
+
+// deleteField мягко удаляет площадку вместе с её арендами и сериями аренд.
+// Все записи получают одну дату удаления, по которой они восстанавливаются вместе с площадкой.
+func deleteField(ctx context.Context, fieldId int64) error {
+	return database.WithTx(ctx, func(tx *database.Tx) error {
+		deletedAt := time.Now()
+		if err, _ := softDeleteRow(tx, "fields", fieldId, deletedAt); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE rentals SET deleted_at = $1, updated_at = now() WHERE field_id = $2 AND deleted_at IS NULL", deletedAt, fieldId)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE rental_series SET deleted_at = $1, updated_at = now() WHERE field_id = $2 AND deleted_at IS NULL", deletedAt, fieldId)
+		return err
+	})
+}
+
+// restoreField восстанавливает площадку и аренды, удаленные вместе с ней
+func restoreField(tx *database.Tx, fieldId int64) (error, bool) {
+	err, deletedAt, restored := restoreRow(tx, "fields", fieldId)
+	if err != nil || !restored {
+		return err, restored
+	}
+
+	_, err = tx.Exec("UPDATE rental_series SET deleted_at = NULL, updated_at = now() WHERE field_id = $1 AND deleted_at = $2", fieldId, deletedAt)
+	if err != nil {
+		return err, false
+	}
+	_, err = tx.Exec("UPDATE rentals SET deleted_at = NULL, updated_at = now() WHERE field_id = $1 AND deleted_at = $2", fieldId, deletedAt)
+	if err != nil {
+		return err, false
+	}
+
+	return nil, true
+}
+
+// RestoreField возвращает функцию-обработчик, которая восстанавливает удаленную площадку по её slug.
+//
+// @Summary Восстановить площадку
+// @Description Восстановить площадку, удаленную мягким удалением, вместе с её арендами. Невозможно, если slug или время аренд уже заняты
+// @Tags Площадки
+// @Param slug path string true "Slug площадки"
+// @Produces application/json
+// @Success 200 {object} models.FieldView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/fields/{slug}/restore [post]
+func RestoreField() http.HandlerFunc {
+	return restoreHandler(
+		func(ctx context.Context, vars map[string]string) (error, int64) {
+			// Площадок с одним slug может быть удалено несколько, восстанавливается последняя
+			var id int64
+			err := database.Conn(ctx).QueryRow("SELECT id FROM fields WHERE slug = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1", vars["slug"]).Scan(&id)
+			return err, id
+		},
+		restoreField,
+		func(ctx context.Context, id int64) (error, interface{}) {
+			return getOneFieldById(ctx, id)
+		},
+	)
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// listDB отвечает на запросы списков площадок, команд и аренд: в каждом списке size записей,
+// у каждой записи свои ответственный, логотип и медиа
+func listDB(tb testing.TB, size int) *dbtest.DB {
+	now := time.Now()
+	rowsByIds := func(args []driver.Value, row func(id int64) []driver.Value) dbtest.Result {
+		var result dbtest.Result
+		for _, value := range arrayArg(args[0]) {
+			id, _ := strconv.ParseInt(value, 10, 64)
+			result.Rows = append(result.Rows, row(id))
+		}
+		return result
+	}
+	rowsBySize := func(row func(id int64) []driver.Value) dbtest.Result {
+		var result dbtest.Result
+		for id := int64(1); id <= int64(size); id++ {
+			result.Rows = append(result.Rows, row(id))
+		}
+		return result
+	}
+
+	fieldRow := func(id int64) []driver.Value {
+		return []driver.Value{
+			id, fmt.Sprintf("Field %d", id), fmt.Sprintf("field-%d", id), nil, "Москва", "Адрес", nil, nil, nil, nil, nil,
+			int64(0), false, false, false, false, false,
+			fmt.Sprintf("field-logo-%d", id), fmt.Sprintf(`["field-media-%d"]`, id), 1000 + id, nil, now,
+		}
+	}
+	teamRow := func(id int64) []driver.Value {
+		return []driver.Value{
+			id, fmt.Sprintf("Team %d", id), nil, "Москва", nil, int64(0), 2000 + id,
+			fmt.Sprintf("team-logo-%d", id), fmt.Sprintf(`["team-media-%d"]`, id), nil, now,
+		}
+	}
+	rentalRow := func(id int64) []driver.Value {
+		return []driver.Value{id, id, id, 3000 + id, "", now, now.Add(time.Hour), int64(1), int64(models.RentalStatusPending), nil, now}
+	}
+	userRow := func(id int64) []driver.Value {
+		return []driver.Value{
+			id, fmt.Sprintf("User %d", id), fmt.Sprintf("user%d@example.com", id),
+			nil, nil, nil, nil, int64(models.UserStatusVerified), now, int64(2), "user",
+		}
+	}
+
+	return dbtest.Open(tb, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(query), "SELECT COUNT("):
+			return dbtest.Result{Rows: [][]driver.Value{{int64(size)}}}, nil
+		case strings.Contains(query, "FROM users u"):
+			return rowsByIds(args, userRow), nil
+		case strings.Contains(query, "FROM medias"):
+			var result dbtest.Result
+			for i, name := range arrayArg(args[0]) {
+				result.Rows = append(result.Rows, []driver.Value{
+					int64(i + 1), name, "media/" + name, "png", "image/png", int64(1), []byte("{}"), nil, now,
+				})
+			}
+			return result, nil
+		case strings.Contains(query, "FROM rentals r"):
+			return rowsBySize(rentalRow), nil
+		case strings.Contains(query, "FROM fields") && strings.Contains(query, "ANY($1)"):
+			return rowsByIds(args, fieldRow), nil
+		case strings.Contains(query, "FROM fields"):
+			return rowsBySize(fieldRow), nil
+		case strings.Contains(query, "FROM teams") && strings.Contains(query, "ANY($1)"):
+			return rowsByIds(args, teamRow), nil
+		case strings.Contains(query, "FROM teams"):
+			return rowsBySize(teamRow), nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+}
+
+// arrayArg разбирает значение аргумента pq.Array вида {1,2} или {"a","b"}
+func arrayArg(value driver.Value) []string {
+	var array string
+	switch v := value.(type) {
+	case string:
+		array = v
+	case []byte:
+		array = string(v)
+	}
+	array = strings.TrimSuffix(strings.TrimPrefix(array, "{"), "}")
+	if array == "" {
+		return nil
+	}
+
+	values := strings.Split(array, ",")
+	for i, value := range values {
+		values[i] = strings.Trim(value, `"`)
+	}
+	return values
+}
+
+// listQueryCases - обработчики списков и количество запросов к базе данных,
+// которое не должно зависеть от размера страницы
+var listQueryCases = []struct {
+	name    string
+	handler func() http.HandlerFunc
+	url     string
+	queries int64
+}{
+	// Количество, список, ответственные, медиафайлы
+	{"GetFields", GetFields, "/api/fields", 4},
+	// Список, ответственные, медиафайлы
+	{"GetTeams", GetTeams, "/api/teams", 3},
+	// Количество, список, площадки с ответственными и медиафайлами,
+	// команды с ответственными и медиафайлами, пользователи
+	{"GetRentals", GetRentals, "/api/rentals", 9},
+}
+
+// listLength возвращает количество записей в ответе списка с пагинацией или без нее
+func listLength(tb testing.TB, body []byte) int {
+	tb.Helper()
+	var list []json.RawMessage
+	if err := json.Unmarshal(body, &list); err == nil {
+		return len(list)
+	}
+	var page struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		tb.Fatalf("unexpected response %s: %v", body, err)
+	}
+	return len(page.Data)
+}
+
+func TestListQueryCountDoesNotDependOnPageSize(t *testing.T) {
+	for _, tc := range listQueryCases {
+		for _, size := range []int{1, 50} {
+			t.Run(fmt.Sprintf("%s/%d", tc.name, size), func(t *testing.T) {
+				db := listDB(t, size)
+
+				r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("%s?per_page=%d", tc.url, size), nil)
+				w := httptest.NewRecorder()
+				tc.handler()(w, r)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+				}
+				if length := listLength(t, w.Body.Bytes()); length != size {
+					t.Fatalf("len(data) = %d, want %d", length, size)
+				}
+				if queries := db.Queries(); queries != tc.queries {
+					t.Fatalf("queries = %d, want %d", queries, tc.queries)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkListQueries(b *testing.B) {
+	for _, tc := range listQueryCases {
+		for _, size := range []int{1, 50} {
+			b.Run(fmt.Sprintf("%s/%d", tc.name, size), func(b *testing.B) {
+				db := listDB(b, size)
+				handler := tc.handler()
+				url := fmt.Sprintf("%s?per_page=%d", tc.url, size)
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+				}
+				b.ReportMetric(float64(db.Queries())/float64(b.N), "queries/op")
+			})
+		}
+	}
+}
+
+// seedListDB подключается к базе данных DATABASE_URL и добавляет size площадок, команд и аренд
+// с отдельными ответственными. Записи помечаются меткой, которая возвращается для фильтрации списков,
+// и удаляются по завершении. Без DATABASE_URL бенчмарк пропускается.
+func seedListDB(b *testing.B, size int) string {
+	b.Helper()
+	dataSourceName := os.Getenv("DATABASE_URL")
+	if dataSourceName == "" {
+		b.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dataSourceName)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		b.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	b.Cleanup(func() {
+		database.DB = previous
+		db.Close()
+	})
+
+	ctx := context.Background()
+	tag := fmt.Sprintf("bench-%d", time.Now().UnixNano())
+	var userIds, fieldIds, teamIds []int64
+	b.Cleanup(func() {
+		queries := []struct {
+			query string
+			ids   []int64
+		}{
+			{"DELETE FROM rentals WHERE field_id = ANY($1)", fieldIds},
+			{"DELETE FROM fields WHERE id = ANY($1)", fieldIds},
+			{"DELETE FROM teams WHERE id = ANY($1)", teamIds},
+			{"DELETE FROM users WHERE id = ANY($1)", userIds},
+		}
+		for _, cleanup := range queries {
+			if _, err := db.Exec(cleanup.query, pq.Array(cleanup.ids)); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	err = database.WithTx(ctx, func(tx *database.Tx) error {
+		for i := 1; i <= size; i++ {
+			var userId, fieldId, teamId int64
+			err := tx.QueryRow("INSERT INTO users (name, email, password, status) VALUES ($1, $2, '', $3) RETURNING id",
+				fmt.Sprintf("User %d", i), fmt.Sprintf("%s-%d@example.com", tag, i), models.UserStatusVerified).Scan(&userId)
+			if err != nil {
+				return err
+			}
+			err = tx.QueryRow("INSERT INTO fields (name, slug, city, address, responsible_id) VALUES ($1, $2, $3, 'Адрес', $4) RETURNING id",
+				fmt.Sprintf("Field %d", i), fmt.Sprintf("%s-%d", tag, i), tag, userId).Scan(&fieldId)
+			if err != nil {
+				return err
+			}
+			err = tx.QueryRow("INSERT INTO teams (name, city, responsible_id) VALUES ($1, $2, $3) RETURNING id",
+				fmt.Sprintf("Team %d", i), tag, userId).Scan(&teamId)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("INSERT INTO rentals (field_id, team_id, user_id, comment, start_date, end_date, status) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+				fieldId, teamId, userId, tag, start, start.Add(time.Hour), models.RentalStatusPending)
+			if err != nil {
+				return err
+			}
+			userIds, fieldIds, teamIds = append(userIds, userId), append(fieldIds, fieldId), append(teamIds, teamId)
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return tag
+}
+
+// BenchmarkListQueriesPostgres измеряет обработчики списков на реальной базе данных DATABASE_URL
+// с примененными миграциями. Списки площадок и аренд ограничены добавленными записями,
+// список команд возвращает все команды базы.
+func BenchmarkListQueriesPostgres(b *testing.B) {
+	const size = 50
+	tag := seedListDB(b, size)
+	filters := map[string]string{
+		"GetFields":  "city=" + tag,
+		"GetTeams":   "",
+		"GetRentals": "search=" + tag,
+	}
+
+	for _, tc := range listQueryCases {
+		for _, perPage := range []int{1, size} {
+			b.Run(fmt.Sprintf("%s/%d", tc.name, perPage), func(b *testing.B) {
+				handler := tc.handler()
+				url := fmt.Sprintf("%s?per_page=%d&%s", tc.url, perPage, filters[tc.name])
+
+				w := httptest.NewRecorder()
+				handler(w, httptest.NewRequest(http.MethodGet, url, nil))
+				if w.Code != http.StatusOK {
+					b.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+				}
+				if length := listLength(b, w.Body.Bytes()); tc.name != "GetTeams" && length != perPage {
+					b.Fatalf("len(data) = %d, want %d", length, perPage)
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+				}
+			})
+		}
+	}
+}
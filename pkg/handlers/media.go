@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"goland_api/pkg/services/imageproc"
+	"goland_api/pkg/services/storage"
 	"io"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -19,18 +23,8 @@ import (
 )
 
 // getOneMedia получает информацию о медиафайле по его имени
-func getOneMedia(fileName string) (error, models.Media) {
-	var media models.Media
-	err := database.DB.QueryRow("SELECT * FROM medias WHERE name = $1", fileName).Scan(
-		&media.ID,
-
-		&media.Name,
-		&media.Path,
-		&media.Ext,
-		&media.Size,
-		&media.CreatedAt,
-	)
-
+func getOneMedia(ctx context.Context, fileName string) (error, models.Media) {
+	err, media := repository.MediaByName(ctx, fileName)
 	if err != nil {
 		log.Println("Ошибка в getOneMedia", fileName, err.Error())
 	}
@@ -39,17 +33,8 @@ func getOneMedia(fileName string) (error, models.Media) {
 }
 
 // getMediaById получает информацию о медиафайле по его ID
-func getMediaById(id int64) (error, models.Media) {
-	var media models.Media
-	err := database.DB.QueryRow("SELECT * FROM medias WHERE id = $1", id).Scan(
-		&media.ID,
-		&media.Name,
-		&media.Path,
-		&media.Ext,
-		&media.Size,
-		&media.CreatedAt,
-	)
-
+func getMediaById(ctx context.Context, id int64) (error, models.Media) {
+	err, media := repository.MediaById(ctx, id)
 	if err != nil {
 		log.Println("Ошибка в getMediaById", id, err.Error())
 	}
@@ -58,9 +43,10 @@ func getMediaById(id int64) (error, models.Media) {
 }
 
 // @Summary Открыть медиафайл
-// @Description Открытие медиафайла
+// @Description Открытие медиафайла или его уменьшенной копии
 // @Tags Медиафайлы
-// @Param file formData file true "Загруженный файл"
+// @Param file path string true "Имя файла"
+// @Param size query string false "Размер копии изображения, например thumb, medium, large"
 // @Success 200 {object} models.Media
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 413 {object} models.ErrorResponse
@@ -69,64 +55,104 @@ func getMediaById(id int64) (error, models.Media) {
 // @Router /api/media/{file} [get]
 func View() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		vars := mux.Vars(r)
-		file := vars["file"]
-
-		errorResponse, media := getOneMedia(file)
-		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+		// Производные изображения адресуются ключом вида <имя>_<вариант> или параметром size
+		file, variantName := splitVariantKey(vars["file"])
+		if size := r.URL.Query().Get("size"); size != "" {
+			variantName = size
+		}
+		if variantName != "" && !isKnownVariant(variantName) {
+			SendJSONError(w, http.StatusBadRequest, "Неверное значение параметра 'size': "+variantName)
 			return
 		}
 
+		errorResponse, media := getOneMedia(ctx, file)
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 			return
 		}
 
-		// Проверяем существование файла
-		if _, err := os.Stat(media.Path); os.IsNotExist(err) {
+		key, mimeType := media.Path, media.MimeType
+		if variantName != "" {
+			// Ответ зависит от того, поддерживает ли клиент WebP
+			w.Header().Add("Vary", "Accept")
+			// Если изображение меньше запрошенного размера, вариант не создается и отдается оригинал
+			if variant, ok := selectVariant(media, variantName, r.Header.Get("Accept")); ok {
+				key, mimeType = variant.Key, variant.MimeType
+			}
+		}
+		fileName := media.Name + "." + mediaExtensions[mimeType]
+
+		// Открываем файл в хранилище
+		fileData, fileInfo, err := storage.Default.Get(ctx, key)
+		if err == storage.ErrNotFound {
 			SendJSONError(w, http.StatusNotFound, "File not found")
 			return
 		}
-
-		// Открываем файл
-		fileData, err := os.Open(media.Path)
 		if err != nil {
+			log.Println("Ошибка при открытии файла", key, err)
 			SendJSONError(w, http.StatusInternalServerError, "Cannot open file")
 			return
 		}
 		defer fileData.Close()
 
-		// Получаем информацию о файле
-		fileInfo, err := fileData.Stat()
-		if err != nil {
-			SendJSONError(w, http.StatusInternalServerError, "Cannot get file info")
-			return
+		// Изображения и видео открываются в браузере, остальные файлы только скачиваются.
+		// Тип содержимого определен при загрузке, браузеру запрещено определять его заново.
+		disposition := "attachment"
+		if strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "video/") {
+			disposition = "inline"
 		}
-
-		// Устанавливаем правильные заголовки для отображения в браузере
-		contentType := getContentType(media.Ext)
-		w.Header().Set("Content-Type", contentType)
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size()))
-		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", media.Name+"."+media.Ext))
+		w.Header().Set("Content-Type", mimeType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, fileName))
 
 		// Копируем содержимое файла в response writer
-		http.ServeContent(w, r, media.Name+"."+media.Ext, fileInfo.ModTime(), fileData)
+		http.ServeContent(w, r, fileName, fileInfo.ModTime, fileData)
+	}
+}
+
+// isKnownVariant проверяет, что производные изображения с именем name создаются при загрузке
+func isKnownVariant(name string) bool {
+	name = strings.TrimSuffix(name, imageproc.WebPSuffix)
+	for _, variant := range imageproc.Variants {
+		if variant.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectVariant выбирает производное изображение name.
+// Клиенту, который принимает WebP, отдается вариант в WebP, если он есть.
+func selectVariant(media models.Media, name string, accept string) (models.MediaVariant, bool) {
+	if !strings.HasSuffix(name, imageproc.WebPSuffix) && strings.Contains(accept, "image/webp") {
+		if variant, ok := media.Variants[name+imageproc.WebPSuffix]; ok {
+			return variant, true
+		}
 	}
+	variant, ok := media.Variants[name]
+	return variant, ok
 }
 
 // @Summary Загрузить медиафайл
-// @Description Загрузка медиафайла
+// @Description Загрузка медиафайла. Тип файла определяется по содержимому, размер ограничен в зависимости от назначения.
+// @Description Файл принадлежит загрузившему его пользователю.
 // @Tags Медиафайлы
+// @Security BearerAuth
 // @Param file formData file true "Загруженный файл"
+// @Param usage query string false "Назначение файла: logo - логотип (только изображения), gallery - галерея (изображения и видео, по умолчанию)"
 // @Success 200 {object} models.Media
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
 // @Failure 413 {object} models.ErrorResponse
 // @Failure 415 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/media/preloader [post]
 func Preloader() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -139,54 +165,132 @@ func Preloader() http.HandlerFunc {
 		}
 
 		if r.Method == http.MethodPost {
+			policy, ok := getUploadPolicy(r.URL.Query().Get("usage"))
+			if !ok {
+				SendJSONError(w, http.StatusBadRequest, "Неверное значение параметра 'usage': "+r.URL.Query().Get("usage"))
+				return
+			}
+			// Запрос больше допустимого размера файла не читается целиком
+			r.Body = http.MaxBytesReader(w, r.Body, policy.maxSize()+multipartOverhead)
+
 			// Загрузка файла
 			file, fileHeader, errFile := r.FormFile("file")
 			if errFile != nil {
+				var errTooLarge *http.MaxBytesError
+				if errors.As(errFile, &errTooLarge) {
+					SendJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Размер файла превышает %d МБ", policy.maxSize()>>20))
+					return
+				}
 				log.Println("Не удалось прочитать файл")
 				SendJSONError(w, http.StatusBadRequest, "Не удалось прочитать файл")
 				return
 			}
 			defer file.Close()
 
-			fileName := getRandomName()
-			dstPath := filepath.Join("./public/upload/", fileName)
+			mimeType, errSniff := sniffMIMEType(file)
+			if errSniff != nil {
+				log.Println("Не удалось прочитать файл", errSniff)
+				SendJSONError(w, http.StatusBadRequest, "Не удалось прочитать файл")
+				return
+			}
+			if errUpload, code := validateUpload(policy, fileHeader.Filename, fileHeader.Size, mimeType); errUpload != nil {
+				SendJSONError(w, code, errUpload.Error())
+				return
+			}
 
-			f, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
-			if err != nil {
-				log.Println("Не удалось открыть файл")
-				SendJSONError(w, http.StatusInternalServerError, "Не удалось открыть файл")
+			errStore, media := storeMedia(ctx, getAuthUser(r).ID, file, fileHeader.Size, mimeType)
+			if errStore != nil {
+				switch {
+				case errors.Is(errStore, imageproc.ErrTooLarge):
+					SendJSONError(w, http.StatusRequestEntityTooLarge, errStore.Error())
+				case errors.Is(errStore, errInvalidImage):
+					SendJSONError(w, http.StatusUnsupportedMediaType, errStore.Error())
+				default:
+					log.Println("Не удалось сохранить файл", errStore)
+					sendDatabaseError(w, errStore, http.StatusInternalServerError, "Не удалось сохранить файл")
+				}
 				return
 			}
-			defer f.Close()
 
-			fileSize, err := io.Copy(f, file)
-			if err != nil {
-				log.Println("Не удалось скопировать файл")
-				SendJSONError(w, http.StatusInternalServerError, "Не удалось скопировать файл")
+			json.NewEncoder(w).Encode(media)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Удалить медиафайл
+// @Description Удаление медиафайла вместе с его производными изображениями. Удалить можно только свой файл,
+// @Description который не используется как логотип или медиа площадки, команды или пользователя
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param file path string true "Имя файла"
+// @Success 200 {string} string "Media deleted"
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/media/{file} [delete]
+func DeleteMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			vars := mux.Vars(r)
+			errorResponse, media := getOneMedia(ctx, vars["file"])
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Файл не найден")
 				return
 			}
 
-			createdAt := time.Now()
-			mimeType := getMIMEType(fileHeader.Filename)
+			// Файлы, загруженные до учета владельцев, может удалить только пользователь с правом на любые файлы
+			var ownerId int64
+			if media.UserID != nil {
+				ownerId = *media.UserID
+			}
+			if !Can(*auth, models.PermissionMediaDelete, ownerId) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на удаление этого файла")
+				return
+			}
 
-			var media models.Media
-			media.Name = fileName
-			media.Path = dstPath
-			media.Ext = mimeType
-			media.Size = fileSize
-			media.CreatedAt = createdAt
+			errDelete, deleted := deleteUnattachedMedia(ctx, int64(media.ID))
+			if errDelete != nil {
+				log.Println("Ошибка при удалении файла", media.Name, errDelete)
+				sendDatabaseError(w, errDelete, http.StatusInternalServerError, "Ошибка при удалении файла")
+				return
+			}
+			if !deleted {
+				SendJSONError(w, http.StatusConflict, "Файл используется и не может быть удален")
+				return
+			}
 
-			errInsert := database.DB.QueryRow("INSERT INTO medias (name, path, ext, size) VALUES ($1, $2, $3, $4) RETURNING id", media.Name, media.Path, media.Ext, media.Size).Scan(&media.ID)
-			if errInsert != nil {
-				log.Println(errInsert)
+			// Запись уже удалена, файлы, которые не удалось удалить из хранилища, только записываются в журнал
+			for _, key := range repository.MediaKeys(media) {
+				if err := storage.Default.Delete(ctx, key); err != nil {
+					log.Println("Не удалось удалить файл", key, err)
+				}
 			}
 
-			json.NewEncoder(w).Encode(media)
+			json.NewEncoder(w).Encode("Media deleted")
 			return
 		}
 
 		// Если метод не поддерживается
-		w.Header().Set("Allow", "POST, OPTIONS")
+		w.Header().Set("Allow", "DELETE, OPTIONS")
 		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
 	}
 }
@@ -197,43 +301,100 @@ func getRandomName() string {
 	return newUUID.String()
 }
 
-func getMIMEType(filename string) string {
-	extFile := filepath.Ext(filename)
-	extData := strings.Split(extFile, ".")
-	ext := ""
-	if len(extData) > 0 {
-		ext = extData[1]
-	} else {
-		log.Println("Расширение файла не удалось получить:" + extFile)
-	}
+// errInvalidImage - файл определен как изображение, но не может быть обработан
+var errInvalidImage = errors.New("Не удалось обработать изображение")
 
-	return ext
+// variantKey возвращает ключ производного изображения variant медиафайла name в хранилище
+func variantKey(name string, variant string) string {
+	return name + "_" + variant
+}
+
+// splitVariantKey разделяет ключ производного изображения на имя медиафайла и имя варианта.
+// Для ключа оригинала имя варианта пустое.
+func splitVariantKey(key string) (string, string) {
+	if i := strings.Index(key, "_"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
 }
 
-// Вспомогательная функция для определения Content-Type
-func getContentType(ext string) string {
-	switch strings.ToLower(ext) {
-	case "jpg", "jpeg":
-		return "image/jpeg"
-	case "png":
-		return "image/png"
-	case "gif":
-		return "image/gif"
-	case "pdf":
-		return "application/pdf"
-	case "txt":
-		return "text/plain"
-	case "html":
-		return "text/html"
-	case "css":
-		return "text/css"
-	case "js":
-		return "application/javascript"
-	case "json":
-		return "application/json"
-	case "xml":
-		return "application/xml"
-	default:
-		return "application/octet-stream"
+// storeMedia сохраняет файл в хранилище и создает запись в medias.
+// Изображения сохраняются без метаданных, для них создаются производные изображения.
+// При ошибке уже сохраненные файлы удаляются.
+func storeMedia(ctx context.Context, userId int64, file io.Reader, size int64, mimeType string) (error, models.Media) {
+	var media models.Media
+	media.UserID = &userId
+	media.Name = getRandomName()
+	// Ключ файла в хранилище совпадает с его именем
+	media.Path = media.Name
+	media.Ext = mediaExtensions[mimeType]
+	media.MimeType = mimeType
+	media.Size = size
+	media.Variants = map[string]models.MediaVariant{}
+	media.CreatedAt = time.Now()
+
+	var stored []string
+	removeStored := func() {
+		for _, key := range stored {
+			if err := storage.Default.Delete(ctx, key); err != nil {
+				log.Println("Не удалось удалить файл", key, err)
+			}
+		}
 	}
+
+	if strings.HasPrefix(mimeType, "image/") {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err, media
+		}
+		original, variants, err := imageproc.Process(data, mimeType)
+		if errors.Is(err, imageproc.ErrTooLarge) {
+			return err, media
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidImage, err), media
+		}
+
+		if err := storage.Default.Put(ctx, media.Path, bytes.NewReader(original.Data), int64(len(original.Data)), mimeType); err != nil {
+			return err, media
+		}
+		stored = append(stored, media.Path)
+		media.Size = int64(len(original.Data))
+
+		for name, variant := range variants {
+			key := variantKey(media.Name, name)
+			if err := storage.Default.Put(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.MimeType); err != nil {
+				removeStored()
+				return err, media
+			}
+			stored = append(stored, key)
+			media.Variants[name] = models.MediaVariant{
+				Key:      key,
+				MimeType: variant.MimeType,
+				Width:    variant.Width,
+				Height:   variant.Height,
+				Size:     int64(len(variant.Data)),
+			}
+		}
+	} else {
+		if err := storage.Default.Put(ctx, media.Path, file, size, mimeType); err != nil {
+			return err, media
+		}
+		stored = append(stored, media.Path)
+	}
+
+	variants, err := json.Marshal(media.Variants)
+	if err != nil {
+		removeStored()
+		return err, media
+	}
+	err = database.Conn(ctx).QueryRow("INSERT INTO medias (name, path, ext, mime_type, size, variants, user_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		media.Name, media.Path, media.Ext, media.MimeType, media.Size, variants, userId).Scan(&media.ID)
+	if err != nil {
+		// Файлы без записи в medias недоступны, удаляем их
+		removeStored()
+		return err, media
+	}
+
+	return nil, repository.WithURL(ctx, media)
 }
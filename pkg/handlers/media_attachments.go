@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// syncMediaAttachments приводит привязки медиафайлов объекта entityType с ID entityId
+// в соответствие с его логотипом и медиа. Файлы, которые больше ни к чему не привязаны,
+// помечаются датой отвязки, от нее отсчитывается срок до удаления командой gcMedia.
+func syncMediaAttachments(tx *database.Tx, entityType string, entityId int64, logo *string, media *json.RawMessage) error {
+	names, err := parseMediaNames(media)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("DELETE FROM media_attachments WHERE entity_type = $1 AND entity_id = $2 RETURNING media_id", entityType, entityId)
+	if err != nil {
+		return err
+	}
+	var detached []int64
+	for rows.Next() {
+		var mediaId int64
+		if err := rows.Scan(&mediaId); err != nil {
+			rows.Close()
+			return err
+		}
+		detached = append(detached, mediaId)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	attach := func(role string, names []string) error {
+		if len(names) == 0 {
+			return nil
+		}
+		_, err := tx.Exec("INSERT INTO media_attachments (media_id, entity_type, entity_id, role) "+
+			"SELECT id, $1, $2, $3 FROM medias WHERE name = ANY($4) ON CONFLICT DO NOTHING",
+			entityType, entityId, role, pq.Array(names))
+		return err
+	}
+	if logo != nil && *logo != "" {
+		if err := attach(models.MediaUsageLogo, []string{*logo}); err != nil {
+			return err
+		}
+	}
+	if err := attach(models.MediaUsageGallery, names); err != nil {
+		return err
+	}
+
+	if len(detached) == 0 {
+		return nil
+	}
+	_, err = tx.Exec("UPDATE medias m SET detached_at = now() WHERE m.id = ANY($1) "+
+		"AND NOT EXISTS (SELECT 1 FROM media_attachments a WHERE a.media_id = m.id)", pq.Array(detached))
+	return err
+}
+
+// getAttachedMediaIds возвращает идентификаторы медиафайлов, привязанных к объекту entityType с ID entityId
+func getAttachedMediaIds(ctx context.Context, entityType string, entityId int64) (error, map[int64]bool) {
+	attached := map[int64]bool{}
+	if entityId == 0 {
+		return nil, attached
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT media_id FROM media_attachments WHERE entity_type = $1 AND entity_id = $2", entityType, entityId)
+	if err != nil {
+		return err, attached
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mediaId int64
+		if err := rows.Scan(&mediaId); err != nil {
+			return err, attached
+		}
+		attached[mediaId] = true
+	}
+	return rows.Err(), attached
+}
+
+// deleteUnattachedMedia удаляет запись медиафайла, если он ни к чему не привязан.
+// Возвращает false, если файл используется.
+func deleteUnattachedMedia(ctx context.Context, mediaId int64) (error, bool) {
+	result, err := database.Conn(ctx).Exec("DELETE FROM medias m WHERE m.id = $1 "+
+		"AND NOT EXISTS (SELECT 1 FROM media_attachments a WHERE a.media_id = m.id)", mediaId)
+	if err != nil {
+		return err, false
+	}
+	deleted, err := result.RowsAffected()
+	return err, deleted > 0
+}
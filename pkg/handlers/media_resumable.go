@@ -0,0 +1,636 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/services/imageproc"
+	"goland_api/pkg/services/storage"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+)
+
+// Максимальный размер одной части по умолчанию, МБ
+const defaultUploadChunkMaxSize = 16
+
+// Время жизни незавершенной загрузки по умолчанию. Отсчитывается от получения последней части.
+const defaultUploadSessionTTL = 24 * time.Hour
+
+// Заголовки протокола загрузки по частям
+const (
+	uploadOffsetHeader = "Upload-Offset" // Смещение части от начала файла
+	uploadLengthHeader = "Upload-Length" // Размер файла
+)
+
+// uploadChunkMaxSize возвращает максимальный размер одной части в байтах
+func uploadChunkMaxSize() int64 {
+	size := int64(defaultUploadChunkMaxSize)
+	if value, err := strconv.ParseInt(os.Getenv("UPLOAD_CHUNK_MAX_SIZE_MB"), 10, 64); err == nil && value > 0 {
+		size = value
+	}
+	return size << 20
+}
+
+// uploadSessionTTL возвращает время жизни незавершенной загрузки
+func uploadSessionTTL() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("UPLOAD_SESSION_TTL")); err == nil && value > 0 {
+		return value
+	}
+	return defaultUploadSessionTTL
+}
+
+// uploadCompleteTimeout возвращает время, за которое должна завершиться сборка загрузки.
+// Захват загрузки старше этого времени считается снятым.
+func uploadCompleteTimeout() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("UPLOAD_COMPLETE_TIMEOUT")); err == nil && value > 0 {
+		return value
+	}
+	return models.DefaultUploadCompleteTimeout
+}
+
+// uploadChunkKey возвращает ключ части загрузки в хранилище.
+// Ключ уникален для каждой попытки, поэтому повторно отправленная часть не перезаписывает принятую.
+func uploadChunkKey(sessionId string, start int64) string {
+	return fmt.Sprintf("uploads/%s/%020d-%s", sessionId, start, getRandomName())
+}
+
+// setUploadHeaders передает клиенту, сколько байт получено и каков размер файла
+func setUploadHeaders(w http.ResponseWriter, session models.UploadSession) {
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+	w.Header().Set(uploadLengthHeader, strconv.FormatInt(session.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// getUploadSession получает незавершенную загрузку пользователя userId.
+// Чужие и просроченные загрузки не находятся.
+func getUploadSession(ctx context.Context, id string, userId int64) (error, models.UploadSession) {
+	var session models.UploadSession
+	err := database.Conn(ctx).QueryRow("SELECT id, usage, file_name, size, received, expires_at, created_at FROM upload_sessions "+
+		"WHERE id = $1 AND user_id = $2 AND expires_at > now()", id, userId).Scan(
+		&session.ID,
+		&session.Usage,
+		&session.FileName,
+		&session.Size,
+		&session.Offset,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	return err, session
+}
+
+// getUploadChunkKeys возвращает ключи частей загрузки в порядке их смещения
+func getUploadChunkKeys(ctx context.Context, sessionId string) (error, []string) {
+	var keys []string
+	rows, err := database.Conn(ctx).Query("SELECT key FROM upload_chunks WHERE session_id = $1 ORDER BY start", sessionId)
+	if err != nil {
+		return err, keys
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return err, keys
+		}
+		keys = append(keys, key)
+	}
+	return rows.Err(), keys
+}
+
+// appendUploadChunk записывает часть, сохраненную в хранилище под ключом key, если загрузка все еще ожидает смещение start.
+// Возвращает false, если другая часть с этим смещением уже принята или загрузка завершается.
+func appendUploadChunk(ctx context.Context, session models.UploadSession, start int64, size int64, key string) (error, bool) {
+	appended := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		appended = false
+		now := time.Now()
+		result, err := tx.Exec("UPDATE upload_sessions SET received = received + $1, updated_at = now(), expires_at = $2 "+
+			"WHERE id = $3 AND received = $4 AND (completing_at IS NULL OR completing_at < $5)",
+			size, now.Add(uploadSessionTTL()), session.ID, start, now.Add(-uploadCompleteTimeout()))
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO upload_chunks (session_id, start, size, key) VALUES ($1, $2, $3, $4)", session.ID, start, size, key)
+		appended = err == nil
+		return err
+	})
+	return err, err == nil && appended
+}
+
+// claimUploadSession атомарно захватывает загрузку пользователя userId для завершения или отмены
+// и возвращает время захвата. Захват, которому больше uploadCompleteTimeout, считается снятым:
+// запрос, захвативший загрузку, к этому времени уже прерван, например вместе с перезапуском сервера.
+// Возвращает false, если загрузку уже завершает или отменяет параллельный запрос.
+func claimUploadSession(ctx context.Context, id string, userId int64) (error, time.Time, bool) {
+	// Время с точностью до микросекунд, как оно хранится в базе данных, чтобы по нему можно было снять захват
+	claimedAt := time.Now().Truncate(time.Microsecond)
+	result, err := database.Conn(ctx).Exec("UPDATE upload_sessions SET completing_at = $1, updated_at = now() "+
+		"WHERE id = $2 AND user_id = $3 AND expires_at > now() AND (completing_at IS NULL OR completing_at < $4)",
+		claimedAt, id, userId, claimedAt.Add(-uploadCompleteTimeout()))
+	if err != nil {
+		return err, claimedAt, false
+	}
+	claimed, err := result.RowsAffected()
+	return err, claimedAt, claimed > 0
+}
+
+// releaseUploadSession снимает захват загрузки, сделанный в claimedAt, если завершить её не удалось,
+// чтобы завершение можно было повторить. Захват снимается и после отмены запроса клиентом.
+// Захват, который после истечения времени перешел к другому запросу, не снимается.
+func releaseUploadSession(ctx context.Context, id string, claimedAt time.Time) {
+	_, err := database.Conn(context.WithoutCancel(ctx)).Exec("UPDATE upload_sessions SET completing_at = NULL, updated_at = now() "+
+		"WHERE id = $1 AND completing_at = $2", id, claimedAt)
+	if err != nil {
+		log.Println("Не удалось снять захват загрузки", id, err)
+	}
+}
+
+// removeUploadSession удаляет загрузку и её части из хранилища.
+// Части, которые не удалось удалить, только записываются в журнал.
+func removeUploadSession(ctx context.Context, sessionId string) error {
+	err, keys := getUploadChunkKeys(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	if _, err := database.Conn(ctx).Exec("DELETE FROM upload_sessions WHERE id = $1", sessionId); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := storage.Default.Delete(ctx, key); err != nil {
+			log.Println("Не удалось удалить часть загрузки", key, err)
+		}
+	}
+	return nil
+}
+
+// chunkReader читает части загрузки из хранилища одну за другой как один файл
+type chunkReader struct {
+	ctx     context.Context
+	keys    []string
+	current storage.Object
+}
+
+// Read читает очередную часть, следующая часть открывается после окончания предыдущей
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.keys) == 0 {
+				return 0, io.EOF
+			}
+			object, _, err := storage.Default.Get(c.ctx, c.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			c.current, c.keys = object, c.keys[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close закрывает открытую часть
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	return c.current.Close()
+}
+
+// @Summary Начать загрузку медиафайла по частям
+// @Description Создание загрузки большого файла, например видео матча. Части отправляются методом PATCH,
+// @Description полученный объем можно узнать методом HEAD, после последней части загрузка завершается методом POST .../complete.
+// @Description Незавершенная загрузка сохраняется UPLOAD_SESSION_TTL после получения последней части
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param createUpload body models.CreateUploadSessionRequest true "Имя и размер файла"
+// @Param usage query string false "Назначение файла: logo - логотип, gallery - галерея (по умолчанию)"
+// @Success 201 {object} models.UploadSession
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Router /api/media/uploads [post]
+func CreateUploadSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			usage := r.URL.Query().Get("usage")
+			if usage == "" {
+				usage = models.MediaUsageGallery
+			}
+			policy, ok := getUploadPolicy(usage)
+			if !ok {
+				SendJSONError(w, http.StatusBadRequest, "Неверное значение параметра 'usage': "+usage)
+				return
+			}
+
+			var req models.CreateUploadSessionRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := validator.New().Struct(req); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			// Тип файла станет известен только после загрузки, размер проверяется сразу
+			if maxSize := policy.maxSize(); req.Size > maxSize {
+				SendJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Размер файла превышает %d МБ", maxSize>>20))
+				return
+			}
+
+			session := models.UploadSession{
+				ID:        getRandomName(),
+				Usage:     usage,
+				FileName:  req.FileName,
+				Size:      req.Size,
+				ExpiresAt: time.Now().Add(uploadSessionTTL()),
+			}
+			err := database.Conn(ctx).QueryRow("INSERT INTO upload_sessions (id, user_id, usage, file_name, size, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at",
+				session.ID, auth.ID, session.Usage, session.FileName, session.Size, session.ExpiresAt).Scan(&session.CreatedAt)
+			if err != nil {
+				log.Println("Ошибка при создании загрузки", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Не удалось начать загрузку")
+				return
+			}
+
+			setUploadHeaders(w, session)
+			w.Header().Set("Location", "/api/media/uploads/"+session.ID)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(session)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Узнать состояние загрузки по частям
+// @Description Возвращает в заголовке Upload-Offset количество полученных байт, с него клиент продолжает загрузку
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param id path string true "ID загрузки"
+// @Success 200 {string} string "Заголовки Upload-Offset и Upload-Length"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/media/uploads/{id} [head]
+func GetUploadSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "HEAD, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Offset")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodHead {
+			vars := mux.Vars(r)
+			errSession, session := getUploadSession(ctx, vars["id"], auth.ID)
+			if errSession != nil {
+				sendDatabaseError(w, errSession, http.StatusNotFound, "Загрузка не найдена")
+				return
+			}
+
+			setUploadHeaders(w, session)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "HEAD, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Отправить часть файла
+// @Description Добавляет тело запроса к загружаемому файлу. Заголовок Upload-Offset должен совпадать с количеством
+// @Description уже полученных байт, иначе ответ 409 с актуальным Upload-Offset. Размер части ограничен UPLOAD_CHUNK_MAX_SIZE_MB
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param id path string true "ID загрузки"
+// @Param Upload-Offset header int true "Смещение части от начала файла"
+// @Success 204 No Content
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Router /api/media/uploads/{id} [patch]
+func UploadChunk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "HEAD, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Offset")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPatch {
+			vars := mux.Vars(r)
+			errSession, session := getUploadSession(ctx, vars["id"], auth.ID)
+			if errSession != nil {
+				sendDatabaseError(w, errSession, http.StatusNotFound, "Загрузка не найдена")
+				return
+			}
+
+			start, errOffset := strconv.ParseInt(r.Header.Get(uploadOffsetHeader), 10, 64)
+			if errOffset != nil {
+				SendJSONError(w, http.StatusBadRequest, "Неверное значение заголовка "+uploadOffsetHeader)
+				return
+			}
+			if start != session.Offset {
+				setUploadHeaders(w, session)
+				SendJSONError(w, http.StatusConflict, fmt.Sprintf("Ожидается часть со смещением %d", session.Offset))
+				return
+			}
+
+			// Часть читается в память целиком, её размер ограничен
+			r.Body = http.MaxBytesReader(w, r.Body, uploadChunkMaxSize())
+			chunk, errRead := io.ReadAll(r.Body)
+			if errRead != nil {
+				var errTooLarge *http.MaxBytesError
+				if errors.As(errRead, &errTooLarge) {
+					SendJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Размер части превышает %d МБ", uploadChunkMaxSize()>>20))
+					return
+				}
+				SendJSONError(w, http.StatusBadRequest, "Не удалось прочитать часть файла")
+				return
+			}
+			if len(chunk) == 0 {
+				SendJSONError(w, http.StatusBadRequest, "Часть файла пуста")
+				return
+			}
+			if start+int64(len(chunk)) > session.Size {
+				SendJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Часть выходит за пределы файла размером %d байт", session.Size))
+				return
+			}
+
+			key := uploadChunkKey(session.ID, start)
+			if err := storage.Default.Put(ctx, key, bytes.NewReader(chunk), int64(len(chunk)), "application/octet-stream"); err != nil {
+				log.Println("Не удалось сохранить часть загрузки", key, err)
+				SendJSONError(w, http.StatusInternalServerError, "Не удалось сохранить часть файла")
+				return
+			}
+
+			errAppend, appended := appendUploadChunk(ctx, session, start, int64(len(chunk)), key)
+			if errAppend != nil || !appended {
+				// Часть не принята, её файл больше не нужен
+				if err := storage.Default.Delete(ctx, key); err != nil {
+					log.Println("Не удалось удалить часть загрузки", key, err)
+				}
+			}
+			if errAppend != nil {
+				log.Println("Ошибка при сохранении части загрузки", session.ID, errAppend)
+				sendDatabaseError(w, errAppend, http.StatusInternalServerError, "Не удалось сохранить часть файла")
+				return
+			}
+			if !appended {
+				// Часть с этим смещением приняла параллельная попытка
+				if err, current := getUploadSession(ctx, session.ID, auth.ID); err == nil {
+					setUploadHeaders(w, current)
+				}
+				SendJSONError(w, http.StatusConflict, "Часть с этим смещением уже получена")
+				return
+			}
+
+			session.Offset += int64(len(chunk))
+			setUploadHeaders(w, session)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PATCH, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Завершить загрузку по частям
+// @Description Собирает полученные части в медиафайл. Тип и размер файла проверяются так же, как при обычной загрузке
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param id path string true "ID загрузки"
+// @Success 200 {object} models.Media
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/media/uploads/{id}/complete [post]
+func CompleteUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			vars := mux.Vars(r)
+			errSession, session := getUploadSession(ctx, vars["id"], auth.ID)
+			if errSession != nil {
+				sendDatabaseError(w, errSession, http.StatusNotFound, "Загрузка не найдена")
+				return
+			}
+			if session.Offset != session.Size {
+				setUploadHeaders(w, session)
+				SendJSONError(w, http.StatusConflict, fmt.Sprintf("Получено %d из %d байт", session.Offset, session.Size))
+				return
+			}
+
+			// Сборка ограничена временем захвата, чтобы захват не был перехвачен, пока файл еще собирается
+			ctx, cancel := context.WithTimeout(ctx, uploadCompleteTimeout())
+			defer cancel()
+
+			// Файл собирает только один запрос, параллельные запросы получают 409
+			errClaim, claimedAt, claimed := claimUploadSession(ctx, session.ID, auth.ID)
+			if errClaim != nil {
+				log.Println("Ошибка при захвате загрузки", session.ID, errClaim)
+				sendDatabaseError(w, errClaim, http.StatusInternalServerError, "Не удалось завершить загрузку")
+				return
+			}
+			if !claimed {
+				SendJSONError(w, http.StatusConflict, "Загрузка уже завершается")
+				return
+			}
+			completed := false
+			defer func() {
+				if !completed {
+					releaseUploadSession(ctx, session.ID, claimedAt)
+				}
+			}()
+
+			errKeys, keys := getUploadChunkKeys(ctx, session.ID)
+			if errKeys != nil {
+				sendDatabaseError(w, errKeys, http.StatusInternalServerError, "Не удалось прочитать загрузку")
+				return
+			}
+			file := &chunkReader{ctx: ctx, keys: keys}
+			defer file.Close()
+
+			// Тип файла определяется по первым байтам, как при обычной загрузке
+			buffered := bufio.NewReader(file)
+			head, errPeek := buffered.Peek(512)
+			if errPeek != nil && errPeek != io.EOF {
+				log.Println("Не удалось прочитать загрузку", session.ID, errPeek)
+				SendJSONError(w, http.StatusInternalServerError, "Не удалось прочитать загрузку")
+				return
+			}
+			mimeType := detectMIMEType(head)
+
+			policy, _ := getUploadPolicy(session.Usage)
+			if errUpload, code := validateUpload(policy, session.FileName, session.Size, mimeType); errUpload != nil {
+				SendJSONError(w, code, errUpload.Error())
+				return
+			}
+
+			errStore, media := storeMedia(ctx, auth.ID, buffered, session.Size, mimeType)
+			if errStore != nil {
+				switch {
+				case errors.Is(errStore, imageproc.ErrTooLarge):
+					SendJSONError(w, http.StatusRequestEntityTooLarge, errStore.Error())
+				case errors.Is(errStore, errInvalidImage):
+					SendJSONError(w, http.StatusUnsupportedMediaType, errStore.Error())
+				default:
+					log.Println("Не удалось сохранить файл", errStore)
+					sendDatabaseError(w, errStore, http.StatusInternalServerError, "Не удалось сохранить файл")
+				}
+				return
+			}
+
+			// Медиафайл уже создан, поэтому ошибка удаления загрузки только записывается в журнал.
+			// Оставшуюся загрузку удалит gcMedia после истечения срока. Захват не снимается,
+			// чтобы из тех же частей сразу же не был собран второй медиафайл.
+			completed = true
+			if err := removeUploadSession(ctx, session.ID); err != nil {
+				log.Println("Не удалось удалить загрузку", session.ID, err)
+			}
+
+			json.NewEncoder(w).Encode(media)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Отменить загрузку по частям
+// @Description Удаляет незавершенную загрузку и полученные части
+// @Tags Медиафайлы
+// @Security BearerAuth
+// @Param id path string true "ID загрузки"
+// @Success 204 No Content
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/media/uploads/{id} [delete]
+func CancelUpload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "HEAD, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Offset")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			vars := mux.Vars(r)
+			errSession, session := getUploadSession(ctx, vars["id"], auth.ID)
+			if errSession != nil {
+				sendDatabaseError(w, errSession, http.StatusNotFound, "Загрузка не найдена")
+				return
+			}
+
+			// Загрузку, которую собирает параллельный запрос, удалять нельзя
+			errClaim, claimedAt, claimed := claimUploadSession(ctx, session.ID, auth.ID)
+			if errClaim != nil {
+				log.Println("Ошибка при захвате загрузки", session.ID, errClaim)
+				sendDatabaseError(w, errClaim, http.StatusInternalServerError, "Ошибка при удалении загрузки")
+				return
+			}
+			if !claimed {
+				SendJSONError(w, http.StatusConflict, "Загрузка уже завершается")
+				return
+			}
+			if err := removeUploadSession(ctx, session.ID); err != nil {
+				releaseUploadSession(ctx, session.ID, claimedAt)
+				log.Println("Ошибка при удалении загрузки", session.ID, err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при удалении загрузки")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
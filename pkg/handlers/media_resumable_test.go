@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Загрузку, которую уже собирает другой запрос, нельзя завершить или отменить повторно
+func TestClaimedUploadSessionConflict(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		url     string
+	}{
+		{"complete", CompleteUpload(), http.MethodPost, "/api/media/uploads/upload-1/complete"},
+		{"cancel", CancelUpload(), http.MethodDelete, "/api/media/uploads/upload-1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbtest.Open(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT id, usage, file_name, size, received"):
+					now := time.Now()
+					return dbtest.Result{Rows: [][]driver.Value{{
+						"upload-1", models.MediaUsageGallery, "match.mp4", int64(100), int64(100), now.Add(time.Hour), now,
+					}}}, nil
+				case strings.HasPrefix(query, "UPDATE upload_sessions SET completing_at = $1"):
+					// Захват уже у параллельного запроса
+					return dbtest.Result{RowsAffected: 0}, nil
+				}
+				return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+			})
+
+			r := httptest.NewRequest(tc.method, tc.url, nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "upload-1"})
+			r = r.WithContext(context.WithValue(r.Context(), authUserKey{}, &models.UserView{ID: 1}))
+			w := httptest.NewRecorder()
+			tc.handler(w, r)
+
+			if w.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, http.StatusConflict, w.Body.String())
+			}
+		})
+	}
+}
+
+// Захват, оставшийся после прерванного запроса, например после перезапуска сервера,
+// снимается по истечении UPLOAD_COMPLETE_TIMEOUT, а снять захват может только его владелец
+func TestUploadSessionClaimExpires(t *testing.T) {
+	t.Setenv("UPLOAD_COMPLETE_TIMEOUT", "1m")
+
+	var claimArgs, releaseArgs []driver.Value
+	dbtest.Open(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "UPDATE upload_sessions SET completing_at = $1"):
+			if !strings.Contains(query, "completing_at IS NULL OR completing_at < $4") {
+				t.Errorf("claim does not take over stale claims: %s", query)
+			}
+			claimArgs = args
+			return dbtest.Result{RowsAffected: 1}, nil
+		case strings.HasPrefix(query, "UPDATE upload_sessions SET completing_at = NULL"):
+			if !strings.Contains(query, "completing_at = $2") {
+				t.Errorf("release is not limited to own claim: %s", query)
+			}
+			releaseArgs = args
+			return dbtest.Result{RowsAffected: 1}, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+
+	err, claimedAt, claimed := claimUploadSession(context.Background(), "upload-1", 1)
+	if err != nil || !claimed {
+		t.Fatalf("claimUploadSession = %v, %v", err, claimed)
+	}
+	if claimArgs[0] != claimedAt {
+		t.Fatalf("completing_at = %v, want %v", claimArgs[0], claimedAt)
+	}
+	if stale := claimArgs[3].(time.Time); !stale.Equal(claimedAt.Add(-time.Minute)) {
+		t.Fatalf("stale claim bound = %v, want %v", stale, claimedAt.Add(-time.Minute))
+	}
+
+	releaseUploadSession(context.Background(), "upload-1", claimedAt)
+	if releaseArgs[1] != claimedAt {
+		t.Fatalf("released claim = %v, want %v", releaseArgs[1], claimedAt)
+	}
+}
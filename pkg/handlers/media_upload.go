@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Допустимые типы изображений
+var imageMIMETypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Допустимые типы видео
+var videoMIMETypes = []string{"video/mp4", "video/webm", "video/quicktime"}
+
+// Расширения, с которыми сохраняются файлы допустимых типов
+var mediaExtensions = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/gif":       "gif",
+	"image/webp":      "webp",
+	"video/mp4":       "mp4",
+	"video/webm":      "webm",
+	"video/quicktime": "mov",
+}
+
+// Запас на заголовки и границы multipart-запроса сверх размера файла
+const multipartOverhead = 1 << 20
+
+// uploadPolicy - ограничения на файлы одного назначения
+type uploadPolicy struct {
+	maxSizeEnv     string   // Переменная окружения с максимальным размером файла в мегабайтах
+	defaultMaxSize int64    // Максимальный размер файла по умолчанию, МБ
+	types          []string // Допустимые типы содержимого
+}
+
+// Ограничения загрузки по назначению файла
+var uploadPolicies = map[string]uploadPolicy{
+	models.MediaUsageLogo:    {"MEDIA_LOGO_MAX_SIZE_MB", 5, imageMIMETypes},
+	models.MediaUsageGallery: {"MEDIA_GALLERY_MAX_SIZE_MB", 100, append(append([]string{}, imageMIMETypes...), videoMIMETypes...)},
+}
+
+// getUploadPolicy возвращает ограничения для назначения usage. По умолчанию файл загружается в галерею.
+func getUploadPolicy(usage string) (uploadPolicy, bool) {
+	if usage == "" {
+		usage = models.MediaUsageGallery
+	}
+	policy, ok := uploadPolicies[usage]
+	return policy, ok
+}
+
+// maxSize возвращает максимальный размер файла в байтах
+func (p uploadPolicy) maxSize() int64 {
+	size := p.defaultMaxSize
+	if value, err := strconv.ParseInt(os.Getenv(p.maxSizeEnv), 10, 64); err == nil && value > 0 {
+		size = value
+	}
+	return size << 20
+}
+
+// allows проверяет, что файлы типа mimeType допустимы
+func (p uploadPolicy) allows(mimeType string) bool {
+	for _, allowed := range p.types {
+		if allowed == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// validateUpload проверяет размер и тип файла по ограничениям policy.
+// Возвращает ошибку и код ответа, если файл не подходит.
+func validateUpload(policy uploadPolicy, fileName string, size int64, mimeType string) (error, int) {
+	if maxSize := policy.maxSize(); size > maxSize {
+		return fmt.Errorf("Размер файла превышает %d МБ", maxSize>>20), http.StatusRequestEntityTooLarge
+	}
+	if !policy.allows(mimeType) {
+		return fmt.Errorf("Недопустимый тип файла: %s", mimeType), http.StatusUnsupportedMediaType
+	}
+
+	// Расширение, указанное клиентом, должно соответствовать содержимому файла
+	if ext := filepath.Ext(fileName); ext != "" {
+		claimed, _, _ := mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(ext)))
+		if claimed != "" && claimed != mimeType {
+			return fmt.Errorf("Содержимое файла (%s) не соответствует расширению %s", mimeType, ext), http.StatusUnsupportedMediaType
+		}
+	}
+
+	return nil, http.StatusOK
+}
+
+// sniffMIMEType определяет тип содержимого по первым байтам файла и возвращает позицию чтения в начало
+func sniffMIMEType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return detectMIMEType(head[:n]), nil
+}
+
+// detectMIMEType определяет тип содержимого по сигнатуре.
+// Видео QuickTime, которое снимают телефоны, http.DetectContentType не распознает, оно проверяется отдельно.
+func detectMIMEType(head []byte) string {
+	if len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")) && bytes.Equal(head[8:12], []byte("qt  ")) {
+		return "video/quicktime"
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mimeType
+}
+
+// validateMediaRefs проверяет, что логотип и медиа ссылаются на загруженные файлы,
+// тип которых допустим для логотипа и для галереи соответственно.
+// Привязать к объекту entityType с ID entityId можно только свои файлы, чужие - с правом media.attach.any.
+// Файлы, уже привязанные к этому объекту, не проверяются, для нового объекта entityId равен нулю.
+// Возвращает ошибку и код ответа, если ссылка недопустима.
+func validateMediaRefs(ctx context.Context, auth models.UserView, entityType string, entityId int64, logo *string, media *json.RawMessage) (error, int) {
+	usages := map[string]string{}
+	names, err := parseMediaNames(media)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	for _, name := range names {
+		usages[name] = models.MediaUsageGallery
+	}
+	if logo != nil && *logo != "" {
+		names = append(names, *logo)
+		usages[*logo] = models.MediaUsageLogo
+	}
+	if len(names) == 0 {
+		return nil, http.StatusOK
+	}
+
+	err, medias := repository.MediasByNames(ctx, names)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	err, attached := getAttachedMediaIds(ctx, entityType, entityId)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	for name, usage := range usages {
+		media, ok := medias[name]
+		if !ok {
+			return fmt.Errorf("Файл '%s' не найден", name), http.StatusUnprocessableEntity
+		}
+		if !uploadPolicies[usage].allows(media.MimeType) {
+			return fmt.Errorf("Файл '%s' типа %s нельзя использовать как %s", name, media.MimeType, usage), http.StatusUnsupportedMediaType
+		}
+		if !attached[int64(media.ID)] && !canAttachMedia(auth, media) {
+			return fmt.Errorf("Файл '%s' загружен другим пользователем", name), http.StatusForbidden
+		}
+	}
+
+	return nil, http.StatusOK
+}
+
+// canAttachMedia проверяет право пользователя привязать медиафайл к объекту.
+// Файл без владельца можно привязать только с правом media.attach.any.
+func canAttachMedia(auth models.UserView, media models.Media) bool {
+	if media.UserID == nil {
+		return Can(auth, models.PermissionMediaAttach)
+	}
+	return Can(auth, models.PermissionMediaAttach, *media.UserID)
+}
+
+// parseMediaNames возвращает имена файлов из списка медиа
+func parseMediaNames(media *json.RawMessage) ([]string, error) {
+	var names []string
+	if media == nil || len(*media) == 0 || string(*media) == "null" {
+		return names, nil
+	}
+	if err := json.Unmarshal(*media, &names); err != nil {
+		return nil, fmt.Errorf("Медиа должно быть списком имен файлов")
+	}
+	return names, nil
+}
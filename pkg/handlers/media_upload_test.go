@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Начала файлов с настоящими сигнатурами форматов
+var (
+	pngHead  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	jpegHead = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	gifHead  = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00")
+	webpHead = []byte("RIFF\x24\x00\x00\x00WEBPVP8 \x18\x00\x00\x00")
+	mp4Head  = []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom\x00\x00\x00\x08free")
+	movHead  = []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x02\x00qt  \x00\x00\x00\x08wide")
+	htmlHead = []byte("<!DOCTYPE html><html><body><script>alert(1)</script></body></html>")
+)
+
+func TestDetectMIMEType(t *testing.T) {
+	cases := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"png", pngHead, "image/png"},
+		{"jpeg", jpegHead, "image/jpeg"},
+		{"gif", gifHead, "image/gif"},
+		{"webp", webpHead, "image/webp"},
+		{"mp4", mp4Head, "video/mp4"},
+		{"mov", movHead, "video/quicktime"},
+		{"html", htmlHead, "text/html"},
+		{"short", []byte("\x00\x00\x00\x14ftyp"), "application/octet-stream"},
+		{"empty", nil, "text/plain"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectMIMEType(tc.head); got != tc.want {
+				t.Fatalf("detectMIMEType = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateUpload(t *testing.T) {
+	t.Setenv("MEDIA_LOGO_MAX_SIZE_MB", "")
+	t.Setenv("MEDIA_GALLERY_MAX_SIZE_MB", "")
+	logo := uploadPolicies[models.MediaUsageLogo]
+	gallery := uploadPolicies[models.MediaUsageGallery]
+
+	cases := []struct {
+		name     string
+		policy   uploadPolicy
+		fileName string
+		size     int64
+		head     []byte
+		want     int
+	}{
+		{"png logo", logo, "logo.png", 1024, pngHead, http.StatusOK},
+		{"jpeg logo", logo, "logo.JPG", 1024, jpegHead, http.StatusOK},
+		{"webp gallery", gallery, "photo.webp", 1024, webpHead, http.StatusOK},
+		{"mp4 gallery", gallery, "clip.mp4", 1024, mp4Head, http.StatusOK},
+		{"mov gallery", gallery, "clip.mov", 1024, movHead, http.StatusOK},
+		{"without extension", gallery, "photo", 1024, jpegHead, http.StatusOK},
+		{"video logo", logo, "clip.mp4", 1024, mp4Head, http.StatusUnsupportedMediaType},
+		{"html renamed to png", logo, "logo.png", 1024, htmlHead, http.StatusUnsupportedMediaType},
+		{"png named jpg", gallery, "photo.jpg", 1024, pngHead, http.StatusUnsupportedMediaType},
+		{"jpeg named gif", gallery, "photo.gif", 1024, jpegHead, http.StatusUnsupportedMediaType},
+		{"oversized logo", logo, "logo.png", 5<<20 + 1, pngHead, http.StatusRequestEntityTooLarge},
+		{"max size logo", logo, "logo.png", 5 << 20, pngHead, http.StatusOK},
+		{"oversized video", gallery, "clip.mp4", 100<<20 + 1, mp4Head, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err, code := validateUpload(tc.policy, tc.fileName, tc.size, detectMIMEType(tc.head))
+			if code != tc.want {
+				t.Fatalf("code = %d, want %d (err = %v)", code, tc.want, err)
+			}
+			if (err == nil) != (tc.want == http.StatusOK) {
+				t.Fatalf("err = %v, want error: %v", err, tc.want != http.StatusOK)
+			}
+		})
+	}
+}
+
+// Недопустимые файлы отклоняются до сохранения
+func TestPreloaderRejectsInvalidFiles(t *testing.T) {
+	t.Setenv("MEDIA_LOGO_MAX_SIZE_MB", "1")
+	padded := func(head []byte, size int) []byte {
+		return append(append([]byte{}, head...), make([]byte, size-len(head))...)
+	}
+
+	cases := []struct {
+		name     string
+		fileName string
+		content  []byte
+		want     int
+		message  string
+	}{
+		{"html renamed to png", "logo.png", htmlHead, http.StatusUnsupportedMediaType, "Недопустимый тип файла: text/html"},
+		{"png named jpg", "logo.jpg", padded(pngHead, 1024), http.StatusUnsupportedMediaType, "не соответствует расширению"},
+		{"video", "clip.mp4", padded(mp4Head, 1024), http.StatusUnsupportedMediaType, "Недопустимый тип файла: video/mp4"},
+		// Файл больше допустимого, но запрос укладывается в запас на multipart
+		{"oversized file", "logo.png", padded(pngHead, 1<<20+1), http.StatusRequestEntityTooLarge, "Размер файла превышает 1 МБ"},
+		// Запрос не дочитывается до конца
+		{"oversized body", "logo.png", padded(pngHead, 3<<20), http.StatusRequestEntityTooLarge, "Размер файла превышает 1 МБ"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, err := form.CreateFormFile("file", tc.fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write(tc.content)
+			form.Close()
+
+			r := httptest.NewRequest(http.MethodPost, "/api/media/preloader?usage=logo", &body)
+			r.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			Preloader()(w, r)
+
+			if w.Code != tc.want {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tc.want, w.Body.String())
+			}
+			var response models.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(response.Message, tc.message) {
+				t.Fatalf("message = %q, want %q", response.Message, tc.message)
+			}
+		})
+	}
+}
+
+func TestValidateMediaRefsOwnership(t *testing.T) {
+	// Файл own.png загружен пользователем 1, foreign.png - пользователем 2, legacy.png - до учета владельцев.
+	// К площадке 5 уже привязан attached.png пользователя 2.
+	owners := map[string]interface{}{"own.png": int64(1), "foreign.png": int64(2), "legacy.png": nil, "attached.png": int64(2)}
+	ids := map[string]int64{"own.png": 1, "foreign.png": 2, "legacy.png": 3, "attached.png": 4}
+	dbtest.Open(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.Contains(query, "FROM medias WHERE name = ANY($1)"):
+			var result dbtest.Result
+			for _, name := range arrayArg(args[0]) {
+				result.Rows = append(result.Rows, []driver.Value{
+					ids[name], name, "media/" + name, "png", "image/png", int64(1), []byte("{}"), owners[name], time.Now(),
+				})
+			}
+			return result, nil
+		case strings.Contains(query, "FROM media_attachments"):
+			if args[0] == models.MediaEntityField && args[1] == int64(5) {
+				return dbtest.Result{Rows: [][]driver.Value{{ids["attached.png"]}}}, nil
+			}
+			return dbtest.Result{Columns: []string{"media_id"}}, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+
+	user := models.UserView{ID: 1, Role: models.Role{Permissions: []string{"media.attach.own"}}}
+	admin := models.UserView{ID: 10, Role: models.Role{Permissions: []string{"media.attach.own", "media.attach.any"}}}
+
+	cases := []struct {
+		name     string
+		auth     models.UserView
+		entityId int64
+		logo     string
+		media    []string
+		want     int
+	}{
+		{"own files", user, 0, "own.png", []string{"own.png"}, http.StatusOK},
+		{"foreign logo", user, 0, "foreign.png", nil, http.StatusForbidden},
+		{"foreign gallery", user, 0, "", []string{"own.png", "foreign.png"}, http.StatusForbidden},
+		{"file without owner", user, 0, "legacy.png", nil, http.StatusForbidden},
+		{"already attached", user, 5, "", []string{"attached.png", "own.png"}, http.StatusOK},
+		{"attached to another field", user, 6, "", []string{"attached.png"}, http.StatusForbidden},
+		{"admin", admin, 0, "foreign.png", []string{"legacy.png"}, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logo *string
+			if tc.logo != "" {
+				logo = &tc.logo
+			}
+			var media *json.RawMessage
+			if tc.media != nil {
+				raw, _ := json.Marshal(tc.media)
+				media = (*json.RawMessage)(&raw)
+			}
+
+			err, code := validateMediaRefs(context.Background(), tc.auth, models.MediaEntityField, tc.entityId, logo, media)
+			if code != tc.want {
+				t.Fatalf("code = %d, want %d (err = %v)", code, tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"goland_api/pkg/services/dadata"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+)
+
+// Ограничения на параметры поиска площадок рядом с точкой
+const (
+	defaultNearbyRadiusKm = 5.0
+	maxNearbyRadiusKm     = 100.0
+	// Длина одного градуса широты, км
+	kmPerLatDegree = 111.045
+)
+
+// fieldDistanceExpression возвращает SQL-выражение расстояния в километрах от площадки до точки (формула гаверсинусов)
+func fieldDistanceExpression(latPlaceholder string, lonPlaceholder string) string {
+	return fmt.Sprintf("(2 * 6371 * asin(least(1, sqrt("+
+		"power(sin(radians(lat - %[1]s) / 2), 2) + "+
+		"cos(radians(%[1]s)) * cos(radians(lat)) * power(sin(radians(lon - %[2]s) / 2), 2)))))",
+		latPlaceholder, lonPlaceholder)
+}
+
+// geocodeFieldAddress определяет координаты площадки по адресу.
+// Если адрес не удалось распознать, площадка сохраняется без координат.
+func geocodeFieldAddress(city string, address string) (*float64, *float64) {
+	lat, lon, err := dadata.Geocode(city + ", " + address)
+	if err != nil {
+		log.Println("Ошибка при определении координат площадки", err)
+		return nil, nil
+	}
+	return &lat, &lon
+}
+
+// parseNearbyFieldsFilter разбирает параметры поиска площадок рядом с точкой.
+// Возвращает условия запроса и выражение расстояния до точки.
+func parseNearbyFieldsFilter(queryParams url.Values) (error, models.NearbyFieldsFilter, sqlConditions, string) {
+	var filter models.NearbyFieldsFilter
+
+	errFilter, searchFilter, conditions := parseFieldSearchFilter(queryParams)
+	if errFilter != nil {
+		return errFilter, filter, conditions, ""
+	}
+	filter.FieldSearchFilter = searchFilter
+
+	lat, err := getFloatParam(queryParams, "lat")
+	if err != nil {
+		return err, filter, conditions, ""
+	}
+	lon, err := getFloatParam(queryParams, "lon")
+	if err != nil {
+		return err, filter, conditions, ""
+	}
+	if lat == nil || lon == nil {
+		return fmt.Errorf("Параметры 'lat' и 'lon' обязательны"), filter, conditions, ""
+	}
+	if *lat < -90 || *lat > 90 || *lon < -180 || *lon > 180 {
+		return fmt.Errorf("Координаты вне допустимого диапазона"), filter, conditions, ""
+	}
+	filter.Lat, filter.Lon = *lat, *lon
+
+	filter.RadiusKm = defaultNearbyRadiusKm
+	radius, err := getFloatParam(queryParams, "radius_km")
+	if err != nil {
+		return err, filter, conditions, ""
+	}
+	if radius != nil {
+		if *radius <= 0 || *radius > maxNearbyRadiusKm {
+			return fmt.Errorf("Радиус поиска должен быть больше 0 и не более %.0f км", maxNearbyRadiusKm), filter, conditions, ""
+		}
+		filter.RadiusKm = *radius
+	}
+
+	// Предварительный отбор по ограничивающему прямоугольнику позволяет использовать индекс по координатам
+	latDelta := filter.RadiusKm / kmPerLatDegree
+	conditions.add("lat BETWEEN ? AND ?", filter.Lat-latDelta, filter.Lat+latDelta)
+
+	cosLat := math.Cos(filter.Lat * math.Pi / 180)
+	if cosLat > 0.01 {
+		lonDelta := filter.RadiusKm / (kmPerLatDegree * cosLat)
+		// Вблизи 180-го меридиана прямоугольник не строится, остается только проверка расстояния
+		if filter.Lon-lonDelta >= -180 && filter.Lon+lonDelta <= 180 {
+			conditions.add("lon BETWEEN ? AND ?", filter.Lon-lonDelta, filter.Lon+lonDelta)
+		}
+	}
+
+	distance := fieldDistanceExpression(conditions.arg(filter.Lat), conditions.arg(filter.Lon))
+	conditions.add(distance+" <= ?", filter.RadiusKm)
+
+	return nil, filter, conditions, distance
+}
+
+// GetNearbyFields возвращает функцию-обработчик, которая ищет площадки в радиусе от точки.
+// Площадки сортируются по расстоянию, расстояние в километрах возвращается в поле distance.
+//
+// @Summary Поиск площадок рядом
+// @Description Получить площадки в радиусе от точки, отсортированные по расстоянию. Поддерживает те же фильтры, что и список площадок
+// @Tags Площадки
+// @Produces application/json
+// @Param lat query number true "Широта"
+// @Param lon query number true "Долгота"
+// @Param radius_km query number false "Радиус поиска, км (по умолчанию 5, не более 100)"
+// @Param page query int false "Номер страницы"
+// @Param per_page query int false "Количество элементов на странице"
+// @Param search query string false "Поиск по названию, описанию и адресу"
+// @Param city query string false "Город"
+// @Success 200 {object} models.PaginationResponse{data=[]models.FieldView}
+// @Failure 400 Bad Request
+// @Failure 500 Internal Server Error
+// @Router /api/fields/nearby [get]
+func GetNearbyFields() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		queryParams := r.URL.Query()
+		page, perPage := getPagination(queryParams)
+		offset := (page - 1) * perPage
+
+		errFilter, filter, conditions, distance := parseNearbyFieldsFilter(queryParams)
+		if errFilter != nil {
+			SendJSONError(w, http.StatusBadRequest, errFilter.Error())
+			return
+		}
+
+		var totalCount int
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(id) FROM fields"+conditions.where(), conditions.args...).Scan(&totalCount)
+		if err != nil {
+			log.Println("Ошибка в SQL запросе GetNearbyFields", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при поиске площадок")
+			return
+		}
+		pages := int(math.Ceil(float64(totalCount) / float64(perPage)))
+
+		query := "SELECT " + repository.FieldViewColumns + ", " + distance + " AS distance FROM fields" + conditions.where() +
+			" ORDER BY distance, id LIMIT " + conditions.arg(perPage) + " OFFSET " + conditions.arg(offset)
+		rows, err := database.Conn(ctx).Query(query, conditions.args...)
+		if err != nil {
+			log.Println("Ошибка в SQL запросе GetNearbyFields", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при поиске площадок")
+			return
+		}
+		defer rows.Close()
+
+		var loader repository.FieldLoader
+		var distances []float64
+		for rows.Next() {
+			var fieldDistance float64
+			if errScan := loader.Scan(rows, &fieldDistance); errScan != nil {
+				log.Println("Ошибка в Scan", errScan)
+				continue
+			}
+			distances = append(distances, math.Round(fieldDistance*1000)/1000)
+		}
+		if err := rows.Err(); err != nil {
+			log.Println("Ошибка в Row Next", err)
+		}
+
+		errLoad, fieldViews := loader.Load(ctx)
+		if errLoad != nil {
+			log.Println("Ошибка в SQL запросе GetNearbyFields", errLoad)
+			sendDatabaseError(w, errLoad, http.StatusInternalServerError, "Ошибка при поиске площадок")
+			return
+		}
+		fields := []interface{}{}
+		for i, fieldView := range fieldViews {
+			fieldView.Distance = &distances[i]
+			fields = append(fields, fieldView)
+		}
+
+		response := models.PaginationResponse{
+			Pagination: models.Pagination{
+				Page:       page,
+				PerPage:    perPage,
+				TotalPages: pages,
+				TotalItems: totalCount,
+			},
+			Filter: filter,
+			Data:   fields,
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// getRolePermissions получает коды прав роли
+func getRolePermissions(ctx context.Context, roleId int) (error, []string) {
+	permissions := []string{}
+
+	rows, err := database.Conn(ctx).Query("SELECT p.code FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id WHERE rp.role_id = $1 ORDER BY p.code", roleId)
+	if err != nil {
+		return err, permissions
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return err, permissions
+		}
+		permissions = append(permissions, code)
+	}
+
+	return rows.Err(), permissions
+}
+
+// hasPermission проверяет наличие у пользователя права с точным кодом
+func hasPermission(user models.UserView, code string) bool {
+	for _, permission := range user.Role.Permissions {
+		if permission == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Can проверяет право пользователя на действие permission.
+// Для прав, разделенных на .own и .any, передается владелец объекта:
+// право .any действует на любые объекты, право .own - только на объекты пользователя.
+func Can(user models.UserView, permission string, ownerId ...int64) bool {
+	if hasPermission(user, permission) || hasPermission(user, permission+".any") {
+		return true
+	}
+	if len(ownerId) > 0 && user.ID != 0 && ownerId[0] == user.ID {
+		return hasPermission(user, permission+".own")
+	}
+	return false
+}
+
+// RequirePermission - middleware, пропускающий только авторизованных пользователей с правом permission
+func RequirePermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if !Can(*getAuthUser(r), permission) {
+			SendJSONError(w, http.StatusForbidden, "Недостаточно прав")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getRoles получает список ролей с их правами
+func getRoles(ctx context.Context) (error, []models.Role) {
+	roles := []models.Role{}
+
+	rows, err := database.Conn(ctx).Query("SELECT id, name FROM roles ORDER BY id")
+	if err != nil {
+		return err, roles
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.ID, &role.Name); err != nil {
+			return err, roles
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return err, roles
+	}
+
+	for i := range roles {
+		errPermissions, permissions := getRolePermissions(ctx, roles[i].ID)
+		if errPermissions != nil {
+			return errPermissions, roles
+		}
+		roles[i].Permissions = permissions
+	}
+
+	return nil, roles
+}
+
+// getOneRoleById получает роль с её правами
+func getOneRoleById(ctx context.Context, roleId int) (error, models.Role) {
+	var role models.Role
+
+	err := database.Conn(ctx).QueryRow("SELECT id, name FROM roles WHERE id = $1", roleId).Scan(&role.ID, &role.Name)
+	if err != nil {
+		return err, role
+	}
+
+	err, role.Permissions = getRolePermissions(ctx, role.ID)
+	return err, role
+}
+
+// Документация для метода GetRoles
+// @Summary Список ролей
+// @Description Получение списка ролей с их правами
+// @Tags Роли
+// @Produces application/json
+// @Success 200 {object} []models.Role
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/roles [get]
+func GetRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		errRoles, roles := getRoles(ctx)
+		if errRoles != nil {
+			log.Println("Ошибка при получении ролей", errRoles)
+			sendDatabaseError(w, errRoles, http.StatusInternalServerError, "Ошибка при получении ролей")
+			return
+		}
+
+		json.NewEncoder(w).Encode(roles)
+	}
+}
+
+// Документация для метода GetPermissions
+// @Summary Список прав
+// @Description Получение списка всех прав, которые можно назначить ролям
+// @Tags Роли
+// @Produces application/json
+// @Success 200 {object} []models.Permission
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/permissions [get]
+func GetPermissions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rows, err := database.Conn(ctx).Query("SELECT id, code, description FROM permissions ORDER BY code")
+		if err != nil {
+			log.Println("Ошибка при получении прав", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении прав")
+			return
+		}
+		defer rows.Close()
+
+		permissions := []models.Permission{}
+		for rows.Next() {
+			var permission models.Permission
+			if err := rows.Scan(&permission.ID, &permission.Code, &permission.Description); err != nil {
+				log.Println("Ошибка в Scan", err)
+				continue
+			}
+			permissions = append(permissions, permission)
+		}
+		if err := rows.Err(); err != nil {
+			log.Println("Ошибка в Row Next", err)
+		}
+
+		json.NewEncoder(w).Encode(permissions)
+	}
+}
+
+// setRolePermissions заменяет права роли.
+// Возвращает ошибку, если среди кодов есть несуществующие.
+func setRolePermissions(ctx context.Context, roleId int, codes []string) error {
+	return database.WithTx(ctx, func(tx *database.Tx) error {
+		var known int
+		err := tx.QueryRow("SELECT COUNT(DISTINCT code) FROM permissions WHERE code = ANY($1)", pq.Array(codes)).Scan(&known)
+		if err != nil {
+			return err
+		}
+		unique := map[string]bool{}
+		for _, code := range codes {
+			unique[code] = true
+		}
+		if known != len(unique) {
+			return fmt.Errorf("Указаны несуществующие права")
+		}
+
+		if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = $1", roleId); err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO role_permissions (role_id, permission_id) SELECT $1, id FROM permissions WHERE code = ANY($2)", roleId, pq.Array(codes))
+		return err
+	})
+}
+
+// Документация для метода UpdateRolePermissions
+// @Summary Изменение прав роли
+// @Description Замена списка прав роли
+// @Tags Роли
+// @Param id path int true "ID роли"
+// @Param updateRolePermissions body models.UpdateRolePermissionsRequest true "Коды прав"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} models.Role
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/roles/{id}/permissions [put]
+func UpdateRolePermissions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			auth := getAuthUser(r)
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			errorResponse, role := getOneRoleById(ctx, paramId)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Роль не найдена")
+				return
+			}
+
+			var roleRequest models.UpdateRolePermissionsRequest
+			if err := json.NewDecoder(r.Body).Decode(&roleRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(roleRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			// Нельзя лишить собственную роль права управлять правами, иначе его не вернуть через API
+			if role.ID == auth.Role.ID {
+				keepsManage := false
+				for _, code := range roleRequest.Permissions {
+					if code == models.PermissionRolesManage {
+						keepsManage = true
+					}
+				}
+				if !keepsManage {
+					SendJSONError(w, http.StatusConflict, "Нельзя лишить собственную роль права "+models.PermissionRolesManage)
+					return
+				}
+			}
+
+			if err := setRolePermissions(ctx, role.ID, roleRequest.Permissions); err != nil {
+				log.Println("Ошибка при изменении прав роли", err)
+				sendDatabaseError(w, err, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			errorResponse, role = getOneRoleById(ctx, role.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(role)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода UpdateUserRole
+// @Summary Назначение роли пользователю
+// @Description Назначение пользователю роли. Собственную роль изменить нельзя
+// @Tags Роли
+// @Param id path int true "ID пользователя"
+// @Param updateUserRole body models.UpdateUserRoleRequest true "Роль"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} models.UserView
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Security BearerAuth
+// @Router /api/users/{id}/role [put]
+func UpdateUserRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			auth := getAuthUser(r)
+			userView, ok := getManagedUser(w, r)
+			if !ok {
+				return
+			}
+
+			var roleRequest models.UpdateUserRoleRequest
+			if err := json.NewDecoder(r.Body).Decode(&roleRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(roleRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			errorRole, role := getOneRoleById(ctx, roleRequest.RoleID)
+			if errorRole != nil {
+				sendDatabaseError(w, errorRole, http.StatusBadRequest, "Роль не найдена")
+				return
+			}
+
+			// Назначить роль с правом управления ролями может только тот, у кого есть это право
+			if hasPermission(models.UserView{Role: role}, models.PermissionRolesManage) && !Can(*auth, models.PermissionRolesManage) {
+				SendJSONError(w, http.StatusForbidden, "Недостаточно прав для назначения этой роли")
+				return
+			}
+
+			_, err := database.Conn(ctx).Exec("UPDATE users SET role_id = $1, updated_at = now() WHERE id = $2", role.ID, userView.ID)
+			if err != nil {
+				log.Println("Ошибка при назначении роли", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при назначении роли")
+				return
+			}
+
+			writeAuditLog(r, &auth.ID, models.AuditEventUserRoleSet, map[string]interface{}{
+				"user_id":          userView.ID,
+				"previous_role_id": userView.Role.ID,
+				"role_id":          role.ID,
+			})
+
+			errorResponse, userView := getUserViewById(ctx, userView.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(userView)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
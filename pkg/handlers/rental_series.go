@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// buildRentalOccurrences формирует список аренд по правилу повторения.
+// Без правила повторения возвращается одна аренда на указанный период.
+func buildRentalOccurrences(startDate time.Time, endDate time.Time, recurrence *models.RentalRecurrence) (error, []models.Rental) {
+	occurrences := []models.Rental{{StartDate: startDate, EndDate: endDate}}
+	if recurrence == nil {
+		return nil, occurrences
+	}
+
+	if recurrence.Until == nil && recurrence.Count == nil {
+		return fmt.Errorf("Для повторяющейся аренды необходимо указать дату окончания или количество повторений"), occurrences
+	}
+
+	// Шаг в днях: время аренды сохраняется по часам площадки и при переходе на летнее время
+	step := 7
+	if recurrence.Frequency == models.RentalFrequencyBiweekly {
+		step = 14
+	}
+
+	var until time.Time
+	if recurrence.Until != nil {
+		parsed, err := parseDateParam(*recurrence.Until)
+		if err != nil {
+			return err, occurrences
+		}
+		// Дата без времени включает весь день
+		if hour, minute, second := parsed.Clock(); hour == 0 && minute == 0 && second == 0 && parsed.Nanosecond() == 0 {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		if parsed.Before(startDate) {
+			return fmt.Errorf("Дата окончания серии должна быть позже начала первой аренды"), occurrences
+		}
+		until = parsed
+	}
+
+	count := models.MaxRentalOccurrences + 1
+	if recurrence.Count != nil {
+		count = *recurrence.Count
+	}
+
+	occurrences = []models.Rental{}
+	for i := 0; i < count; i++ {
+		occurrenceStart := startDate.AddDate(0, 0, i*step)
+		if recurrence.Until != nil && occurrenceStart.After(until) {
+			break
+		}
+		if len(occurrences) == models.MaxRentalOccurrences {
+			return fmt.Errorf("Серия не может содержать более %d аренд", models.MaxRentalOccurrences), occurrences
+		}
+		occurrences = append(occurrences, models.Rental{
+			StartDate: occurrenceStart,
+			EndDate:   endDate.AddDate(0, 0, i*step),
+		})
+	}
+
+	return nil, occurrences
+}
+
+// createRentals сохраняет аренды одной транзакцией.
+// Если передано правило повторения, аренды объединяются в серию.
+func createRentals(ctx context.Context, rentalRequest models.CreateRentalRequest, occurrences []models.Rental, userId int64) (error, *int64, []int64) {
+	var seriesId *int64
+	var rentalIds []int64
+
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		// При повторе транзакции результаты предыдущей попытки отбрасываются
+		seriesId, rentalIds = nil, nil
+
+		if rentalRequest.Recurrence != nil {
+			// Для серии, ограниченной датой, сохраняем окончание последней аренды
+			var until *time.Time
+			if rentalRequest.Recurrence.Until != nil {
+				last := occurrences[len(occurrences)-1].EndDate
+				until = &last
+			}
+			count := len(occurrences)
+
+			var id int64
+			err := tx.QueryRow("INSERT INTO rental_series (field_id, team_id, user_id, frequency, until, count) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+				rentalRequest.FieldID,
+				rentalRequest.TeamID,
+				userId,
+				rentalRequest.Recurrence.Frequency,
+				until,
+				count,
+			).Scan(&id)
+			if err != nil {
+				return err
+			}
+			seriesId = &id
+		}
+
+		for _, occurrence := range occurrences {
+			// Длительность аренды в секундах
+			duration := int(occurrence.EndDate.Sub(occurrence.StartDate).Seconds())
+
+			var rentalId int64
+			err := tx.QueryRow("INSERT INTO rentals (field_id, team_id, user_id, comment, start_date, end_date, duration, status, series_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+				rentalRequest.FieldID,
+				rentalRequest.TeamID,
+				userId,
+				rentalRequest.Comment,
+				occurrence.StartDate,
+				occurrence.EndDate,
+				duration,
+				models.RentalStatusPending,
+				seriesId,
+			).Scan(&rentalId)
+			if err != nil {
+				return err
+			}
+
+			if err := addRentalStatusHistory(tx, rentalId, nil, models.RentalStatusPending, userId, nil); err != nil {
+				return err
+			}
+			rentalIds = append(rentalIds, rentalId)
+		}
+		return nil
+	})
+
+	return err, seriesId, rentalIds
+}
+
+// getOneRentalSeriesById получает серию аренд со всеми её арендами
+func getOneRentalSeriesById(ctx context.Context, paramId int64) (error, models.RentalSeriesView) {
+	var seriesView models.RentalSeriesView
+	seriesView.Rentals = []models.RentalView{}
+
+	err := database.Conn(ctx).QueryRow("SELECT id, frequency, until, count, created_at FROM rental_series WHERE id = $1 AND deleted_at IS NULL", paramId).Scan(
+		&seriesView.ID,
+		&seriesView.Frequency,
+		&seriesView.Until,
+		&seriesView.Count,
+		&seriesView.CreatedAt,
+	)
+	if err != nil {
+		return err, seriesView
+	}
+
+	errRentals, rentals := repository.RentalViewsBySeries(ctx, paramId)
+	if errRentals != nil {
+		return errRentals, seriesView
+	}
+	seriesView.Rentals = rentals
+
+	return nil, seriesView
+}
+
+// getRentalSeriesOwners получает автора серии аренд и ответственного за её площадку
+func getRentalSeriesOwners(ctx context.Context, seriesId int64) (error, int64, int64) {
+	var userId, responsibleId sql.NullInt64
+	err := database.Conn(ctx).QueryRow("SELECT s.user_id, f.responsible_id FROM rental_series s "+
+		"LEFT JOIN fields f ON f.id = s.field_id WHERE s.id = $1 AND s.deleted_at IS NULL", seriesId).Scan(&userId, &responsibleId)
+	return err, userId.Int64, responsibleId.Int64
+}
+
+// cancelRentalSeries отменяет предстоящие аренды серии одной транзакцией и записывает их в историю статусов.
+// Прошедшие, удаленные и уже закрытые аренды не меняются. Возвращает количество отмененных аренд.
+func cancelRentalSeries(ctx context.Context, seriesId int64, userId int64, comment *string) (error, int) {
+	// Статусы, из которых аренду можно отменить
+	var fromStatuses []int64
+	for status := range models.RentalStatusNames {
+		if models.CanChangeRentalStatus(status, models.RentalStatusCancelled) {
+			fromStatuses = append(fromStatuses, int64(status))
+		}
+	}
+
+	cancelled := 0
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		cancelled = 0
+		rows, err := tx.Query("WITH upcoming AS ("+
+			"SELECT id, status FROM rentals WHERE series_id = $1 AND deleted_at IS NULL AND start_date > $2 AND status = ANY($3) FOR UPDATE"+
+			") UPDATE rentals r SET status = $4, updated_at = now() FROM upcoming u WHERE r.id = u.id RETURNING r.id, u.status",
+			seriesId, time.Now(), pq.Array(fromStatuses), models.RentalStatusCancelled)
+		if err != nil {
+			return err
+		}
+		type change struct {
+			rentalId   int64
+			fromStatus int
+		}
+		var changes []change
+		for rows.Next() {
+			var c change
+			if err := rows.Scan(&c.rentalId, &c.fromStatus); err != nil {
+				rows.Close()
+				return err
+			}
+			changes = append(changes, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			fromStatus := c.fromStatus
+			if err := addRentalStatusHistory(tx, c.rentalId, &fromStatus, models.RentalStatusCancelled, userId, comment); err != nil {
+				return err
+			}
+		}
+		cancelled = len(changes)
+		return nil
+	})
+
+	return err, cancelled
+}
+
+// Документация для метода GetRentalSeries
+// @Summary Возвращает серию повторяющихся аренд
+// @Description Получение серии аренд со всеми её арендами
+// @Tags Аренда
+// @Param id path int true "ID серии"
+// @Success 200 {object} models.RentalSeriesView
+// @Failure 404 Not Found
+// @Router /api/rentals/series/{id} [get]
+func GetRentalSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+		paramId, _ := strconv.Atoi(vars["id"])
+
+		errorResponse, seriesView := getOneRentalSeriesById(ctx, int64(paramId))
+		if errorResponse != nil {
+			sendDatabaseError(w, errorResponse, http.StatusNotFound, "Серия аренд не найдена")
+			return
+		}
+
+		json.NewEncoder(w).Encode(seriesView)
+	}
+}
+
+// Документация для метода CancelRentalSeries
+// @Summary Отмена серии аренд
+// @Description Отмена всех предстоящих аренд серии одной транзакцией: при ошибке ни одна аренда не отменяется.
+// @Description Отдельную аренду серии можно отменить через /api/rentals/{id}/cancel
+// @Tags Аренда
+// @Param id path int true "ID серии"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Причина отмены"
+// @Success 200 {object} models.RentalSeriesView
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/rentals/series/{id} [delete]
+func CancelRentalSeries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			// Право на отмену проверяется по самой серии, а не по её арендам, которых может не быть
+			errorResponse, userId, responsibleId := getRentalSeriesOwners(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Серия аренд не найдена")
+				return
+			}
+			if !canChangeRentalStatusOf(*auth, userId, responsibleId, models.RentalStatusCancelled) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на отмену этой серии аренд")
+				return
+			}
+
+			var statusRequest models.ChangeRentalStatusRequest
+			if errJson := json.NewDecoder(r.Body).Decode(&statusRequest); errJson != nil && errJson != io.EOF {
+				SendJSONError(w, http.StatusBadRequest, errJson.Error())
+				return
+			}
+
+			// Все аренды серии отменяются одной транзакцией: при ошибке ни одна аренда не меняется
+			if errCancel, _ := cancelRentalSeries(ctx, int64(paramId), auth.ID, statusRequest.Comment); errCancel != nil {
+				log.Println("Ошибка при отмене серии аренд", paramId, errCancel)
+				sendDatabaseError(w, errCancel, http.StatusInternalServerError, "Ошибка при отмене серии аренд")
+				return
+			}
+
+			errorResponse, seriesView := getOneRentalSeriesById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(seriesView)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
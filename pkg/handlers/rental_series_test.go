@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCancelRentalSeries(t *testing.T) {
+	const authorId, responsibleId = 1, 2
+	cases := []struct {
+		name        string
+		user        models.UserView
+		exists      bool
+		upcoming    int
+		failHistory bool
+		code        int
+		cancelled   bool
+	}{
+		{"author", models.UserView{ID: authorId, Role: models.Role{Permissions: []string{"rentals.cancel.own"}}},
+			true, 2, false, http.StatusOK, true},
+		{"field responsible", models.UserView{ID: responsibleId, Role: models.Role{Permissions: []string{"rentals.approve.own"}}},
+			true, 2, false, http.StatusOK, true},
+		// Право проверяется по серии, даже если в ней не осталось аренд
+		{"other user without rentals", models.UserView{ID: 3, Role: models.Role{Permissions: []string{"rentals.cancel.own"}}},
+			true, 0, false, http.StatusForbidden, false},
+		{"other user", models.UserView{ID: 3, Role: models.Role{Permissions: []string{"rentals.cancel.own", "rentals.approve.own"}}},
+			true, 2, false, http.StatusForbidden, false},
+		{"not found", models.UserView{ID: authorId, Role: models.Role{Permissions: []string{"rentals.cancel.own"}}},
+			false, 0, false, http.StatusNotFound, false},
+		// Ошибка на второй аренде откатывает отмену всей серии
+		{"history error", models.UserView{ID: authorId, Role: models.Role{Permissions: []string{"rentals.cancel.own"}}},
+			true, 2, true, http.StatusInternalServerError, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cancelQueries, historyRows int64
+			db := dbtest.Open(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+				switch {
+				case strings.Contains(query, "FROM rental_series s"):
+					result := dbtest.Result{Columns: []string{"user_id", "responsible_id"}}
+					if tc.exists {
+						result.Rows = [][]driver.Value{{int64(authorId), int64(responsibleId)}}
+					}
+					return result, nil
+				case strings.HasPrefix(query, "WITH upcoming AS"):
+					atomic.AddInt64(&cancelQueries, 1)
+					if args[3] != int64(models.RentalStatusCancelled) {
+						t.Errorf("status = %v, want %d", args[3], models.RentalStatusCancelled)
+					}
+					result := dbtest.Result{Columns: []string{"id", "status"}}
+					for id := 1; id <= tc.upcoming; id++ {
+						result.Rows = append(result.Rows, []driver.Value{int64(id), int64(models.RentalStatusPending)})
+					}
+					return result, nil
+				case strings.HasPrefix(query, "INSERT INTO rental_status_history"):
+					if tc.failHistory && atomic.AddInt64(&historyRows, 1) == 2 {
+						return dbtest.Result{}, errors.New("history insert failed")
+					}
+					return dbtest.Result{RowsAffected: 1}, nil
+				case strings.HasPrefix(query, "SELECT id, frequency"):
+					return dbtest.Result{Rows: [][]driver.Value{{int64(1), models.RentalFrequencyWeekly, nil, int64(2), time.Now()}}}, nil
+				case strings.Contains(query, "FROM rentals r WHERE r.series_id"):
+					return dbtest.Result{Columns: []string{"id"}}, nil
+				}
+				return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+			})
+
+			r := httptest.NewRequest(http.MethodDelete, "/api/rentals/series/1", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r = r.WithContext(context.WithValue(r.Context(), authUserKey{}, &tc.user))
+			w := httptest.NewRecorder()
+			CancelRentalSeries()(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tc.code, w.Body.String())
+			}
+			if tc.code == http.StatusForbidden && cancelQueries != 0 {
+				t.Fatal("rentals were cancelled without permission")
+			}
+			if committed := db.Commits() > 0; committed != tc.cancelled {
+				t.Fatalf("committed = %v, want %v", committed, tc.cancelled)
+			}
+			if tc.failHistory && db.Rollbacks() == 0 {
+				t.Fatal("transaction was not rolled back")
+			}
+		})
+	}
+}
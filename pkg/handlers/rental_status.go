@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// sqlExecutor - соединение или транзакция, в которой выполняется запрос
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// addRentalStatusHistory сохраняет запись об изменении статуса аренды
+func addRentalStatusHistory(executor sqlExecutor, rentalId int64, fromStatus *int, toStatus int, userId int64, comment *string) error {
+	_, err := executor.Exec("INSERT INTO rental_status_history (rental_id, from_status, to_status, user_id, comment) VALUES ($1, $2, $3, $4, $5)",
+		rentalId,
+		fromStatus,
+		toStatus,
+		userId,
+		comment,
+	)
+	return err
+}
+
+// getRentalStatusHistory получает историю изменения статусов аренды
+func getRentalStatusHistory(ctx context.Context, rentalId int64) (error, []models.RentalStatusHistory) {
+	history := []models.RentalStatusHistory{}
+
+	rows, err := database.Conn(ctx).Query("SELECT id, rental_id, from_status, to_status, user_id, comment, created_at FROM rental_status_history WHERE rental_id = $1 ORDER BY created_at, id", rentalId)
+	if err != nil {
+		return err, history
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record models.RentalStatusHistory
+		if err := rows.Scan(
+			&record.ID,
+			&record.RentalID,
+			&record.FromStatus,
+			&record.ToStatus,
+			&record.UserID,
+			&record.Comment,
+			&record.CreatedAt,
+		); err != nil {
+			return err, history
+		}
+		history = append(history, record)
+	}
+
+	return rows.Err(), history
+}
+
+// changeRentalStatus переводит аренду в новый статус и сохраняет запись в истории.
+// Возвращает false, если статус аренды был изменен параллельным запросом.
+func changeRentalStatus(ctx context.Context, rentalView models.RentalView, toStatus int, userId int64, comment *string) (error, bool) {
+	changed := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		result, err := tx.Exec("UPDATE rentals SET status = $1, updated_at = now() WHERE id = $2 AND status = $3",
+			toStatus,
+			rentalView.ID,
+			rentalView.Status,
+		)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		fromStatus := rentalView.Status
+		if err := addRentalStatusHistory(tx, rentalView.ID, &fromStatus, toStatus, userId, comment); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+
+	return err, err == nil && changed
+}
+
+// canChangeRentalStatus проверяет право пользователя перевести аренду в статус toStatus.
+// Подтверждать, отклонять и закрывать аренду может ответственный за площадку,
+// отменить аренду может также её автор.
+func canChangeRentalStatus(auth models.UserView, rentalView models.RentalView, toStatus int) bool {
+	return canChangeRentalStatusOf(auth, rentalView.User.ID, rentalView.Field.Responsible.ID, toStatus)
+}
+
+// canChangeRentalStatusOf проверяет право пользователя перевести в статус toStatus аренду автора userId
+// на площадке с ответственным responsibleId
+func canChangeRentalStatusOf(auth models.UserView, userId int64, responsibleId int64, toStatus int) bool {
+	if Can(auth, models.PermissionRentalsApprove, responsibleId) {
+		return true
+	}
+	return toStatus == models.RentalStatusCancelled && Can(auth, models.PermissionRentalsCancel, userId)
+}
+
+// changeRentalStatusHandler возвращает обработчик перевода аренды в статус toStatus
+func changeRentalStatusHandler(toStatus int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			errorResponse, rentalView := getOneRentalById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Аренда не найдена")
+				return
+			}
+
+			if !canChangeRentalStatus(*auth, rentalView, toStatus) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на изменение статуса этой аренды")
+				return
+			}
+
+			if !models.CanChangeRentalStatus(rentalView.Status, toStatus) {
+				SendJSONError(w, http.StatusConflict, "Недопустимый переход статуса аренды: "+
+					models.RentalStatusNames[rentalView.Status]+" -> "+models.RentalStatusNames[toStatus])
+				return
+			}
+
+			// Завершить аренду или отметить неявку можно только после её начала
+			if (toStatus == models.RentalStatusCompleted || toStatus == models.RentalStatusNoShow) && time.Now().Before(rentalView.StartDate) {
+				SendJSONError(w, http.StatusUnprocessableEntity, "Аренда еще не началась")
+				return
+			}
+
+			var statusRequest models.ChangeRentalStatusRequest
+			if errJson := json.NewDecoder(r.Body).Decode(&statusRequest); errJson != nil && errJson != io.EOF {
+				SendJSONError(w, http.StatusBadRequest, errJson.Error())
+				return
+			}
+
+			errChange, changed := changeRentalStatus(ctx, rentalView, toStatus, auth.ID, statusRequest.Comment)
+			if errChange != nil {
+				log.Println("Ошибка при изменении статуса аренды", errChange)
+				sendDatabaseError(w, errChange, http.StatusInternalServerError, "Ошибка при изменении статуса аренды")
+				return
+			}
+			if !changed {
+				SendJSONError(w, http.StatusConflict, "Статус аренды был изменен, повторите запрос")
+				return
+			}
+
+			errorResponse, rentalView = getOneRentalById(ctx, rentalView.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(rentalView)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода ConfirmRental
+// @Summary Подтверждение аренды
+// @Description Подтверждение аренды ответственным за площадку
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Комментарий"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/rentals/{id}/confirm [post]
+func ConfirmRental() http.HandlerFunc {
+	return changeRentalStatusHandler(models.RentalStatusConfirmed)
+}
+
+// Документация для метода RejectRental
+// @Summary Отклонение аренды
+// @Description Отклонение аренды ответственным за площадку
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Причина отказа"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/rentals/{id}/reject [post]
+func RejectRental() http.HandlerFunc {
+	return changeRentalStatusHandler(models.RentalStatusRejected)
+}
+
+// Документация для метода CancelRental
+// @Summary Отмена аренды
+// @Description Отмена аренды её автором или ответственным за площадку
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Причина отмены"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/rentals/{id}/cancel [post]
+func CancelRental() http.HandlerFunc {
+	return changeRentalStatusHandler(models.RentalStatusCancelled)
+}
+
+// Документация для метода CompleteRental
+// @Summary Завершение аренды
+// @Description Отметка о том, что аренда состоялась
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Комментарий"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/rentals/{id}/complete [post]
+func CompleteRental() http.HandlerFunc {
+	return changeRentalStatusHandler(models.RentalStatusCompleted)
+}
+
+// Документация для метода NoShowRental
+// @Summary Неявка на аренду
+// @Description Отметка о том, что команда не пришла на аренду
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Param changeRentalStatus body models.ChangeRentalStatusRequest false "Комментарий"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/rentals/{id}/no-show [post]
+func NoShowRental() http.HandlerFunc {
+	return changeRentalStatusHandler(models.RentalStatusNoShow)
+}
+
+// Документация для метода GetRentalHistory
+// @Summary История статусов аренды
+// @Description Получение истории изменения статусов аренды
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Produces application/json
+// @Success 200 {object} []models.RentalStatusHistory
+// @Failure 404 Not Found
+// @Router /api/rentals/{id}/history [get]
+func GetRentalHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+		paramId, _ := strconv.Atoi(vars["id"])
+
+		errorResponse, rentalView := getOneRentalById(ctx, int64(paramId))
+		if errorResponse != nil {
+			sendDatabaseError(w, errorResponse, http.StatusNotFound, "Аренда не найдена")
+			return
+		}
+
+		errHistory, history := getRentalStatusHistory(ctx, rentalView.ID)
+		if errHistory != nil {
+			log.Println("Ошибка при получении истории статусов аренды", errHistory)
+			sendDatabaseError(w, errHistory, http.StatusInternalServerError, "Ошибка при получении истории статусов аренды")
+			return
+		}
+
+		json.NewEncoder(w).Encode(history)
+	}
+}
@@ -1,124 +1,207 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
 	"log"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// rentalSortOrders - допустимые варианты сортировки списка аренд
+var rentalSortOrders = map[string]string{
+	"id":          "r.id ASC",
+	"-id":         "r.id DESC",
+	"start_date":  "r.start_date ASC, r.id ASC",
+	"-start_date": "r.start_date DESC, r.id DESC",
+	"end_date":    "r.end_date ASC, r.id ASC",
+	"-end_date":   "r.end_date DESC, r.id DESC",
+	"created_at":  "r.created_at ASC, r.id ASC",
+	"-created_at": "r.created_at DESC, r.id DESC",
+}
+
+// parseRentalSearchFilter разбирает параметры фильтрации списка аренд и формирует условия запроса
+func parseRentalSearchFilter(queryParams url.Values) (error, models.RentalSearchFilter, sqlConditions) {
+	var filter models.RentalSearchFilter
+	var conditions sqlConditions
+
+	conditions.add("r.deleted_at IS NULL")
+
+	if search := strings.TrimSpace(queryParams.Get("search")); search != "" {
+		filter.Search = &search
+		pattern := containsPattern(search)
+		conditions.add("(r.comment ILIKE ? ESCAPE '\\' OR t.name ILIKE ? ESCAPE '\\' OR f.name ILIKE ? ESCAPE '\\')", pattern, pattern, pattern)
+	}
+
+	fieldIds, err := getInt64ListParam(queryParams, "field_ids")
+	if err != nil {
+		return err, filter, conditions
+	}
+	if len(fieldIds) > 0 {
+		filter.FieldId = &fieldIds
+		conditions.add("r.field_id = ANY(?)", pq.Array(fieldIds))
+	}
+
+	teamIds, err := getInt64ListParam(queryParams, "team_ids")
+	if err != nil {
+		return err, filter, conditions
+	}
+	if len(teamIds) > 0 {
+		filter.TeamId = &teamIds
+		conditions.add("r.team_id = ANY(?)", pq.Array(teamIds))
+	}
+
+	// Период фильтрации: аренды, пересекающиеся с [start_date, end_date)
+	if value := queryParams.Get("start_date"); value != "" {
+		startDate, err := parseDateParam(value)
+		if err != nil {
+			return err, filter, conditions
+		}
+		filter.StartDate = &value
+		conditions.add("r.end_date > ?", startDate)
+	}
+	if value := queryParams.Get("end_date"); value != "" {
+		endDate, err := parseDateParam(value)
+		if err != nil {
+			return err, filter, conditions
+		}
+		filter.EndDate = &value
+		conditions.add("r.start_date < ?", endDate)
+	}
+
+	// Статус можно передать номером или названием: status=1,confirmed
+	var statuses []int
+	for _, value := range getListParam(queryParams, "status") {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			status = 0
+			for code, name := range models.RentalStatusNames {
+				if name == value {
+					status = code
+				}
+			}
+		}
+		if _, exists := models.RentalStatusNames[status]; !exists {
+			return fmt.Errorf("Неизвестный статус аренды: %s", value), filter, conditions
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) > 0 {
+		filter.Status = &statuses
+		conditions.add("r.status = ANY(?)", pq.Array(statuses))
+	}
+
+	filter.Sort = "id"
+	if value := queryParams.Get("sort"); value != "" {
+		if _, exists := rentalSortOrders[value]; !exists {
+			return fmt.Errorf("Недопустимая сортировка: %s", value), filter, conditions
+		}
+		filter.Sort = value
+	}
+
+	return nil, filter, conditions
+}
+
 // Документация для метода GetRentals
 // @Summary Возвращает список всех аренд
-// @Description Получение списка всех аренд
+// @Description Получение списка аренд с фильтрацией, поиском и сортировкой
 // @Tags Аренда
 // @Accept application/json
 // @Produces application/json
-// @Success 200 {object} []models.RentalView
+// @Param page query int false "Номер страницы"
+// @Param per_page query int false "Количество элементов на странице"
+// @Param search query string false "Поиск по комментарию, названию команды и площадки"
+// @Param field_ids query string false "Идентификаторы площадок через запятую"
+// @Param team_ids query string false "Идентификаторы команд через запятую"
+// @Param start_date query string false "Аренды, заканчивающиеся после даты (2006-01-02 15:04:05)"
+// @Param end_date query string false "Аренды, начинающиеся до даты (2006-01-02 15:04:05)"
+// @Param status query string false "Статусы аренды через запятую (номер или название)"
+// @Param sort query string false "Сортировка: id, start_date, end_date, created_at; префикс - для обратного порядка"
+// @Success 200 {object} models.PaginationResponse{data=[]models.RentalView}
 // @Failure 400 Bad Request
 // @Failure 500 Internal Server Error
 // @Router /api/rentals [get]
 func GetRentals() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		// Извлекаем параметры из GET-запроса
 		queryParams := r.URL.Query()
-		// Извлекаем значение параметра "page" (с значением по умолчанию)
-		page := getIntParam(queryParams, "page", 1)
-		// Извлекаем значение параметра "per_page" (с значением по умолчанию)
-		perPage := getIntParam(queryParams, "per_page", 10)
+		// Извлекаем значения параметров "page" и "per_page" (с значениями по умолчанию)
+		page, perPage := getPagination(queryParams)
 		// Вычисляем OFFSET
 		offset := (page - 1) * perPage
 
+		errFilter, filter, conditions := parseRentalSearchFilter(queryParams)
+		if errFilter != nil {
+			SendJSONError(w, http.StatusBadRequest, errFilter.Error())
+			return
+		}
+
+		from := `
+		FROM rentals r
+		LEFT JOIN teams t ON t.id = r.team_id
+		LEFT JOIN fields f ON f.id = r.field_id
+	` + conditions.where()
+
 		var totalCount int
-		err := database.DB.QueryRow("SELECT COUNT(id) FROM rentals").Scan(&totalCount)
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(r.id)"+from, conditions.args...).Scan(&totalCount)
 		if err != nil {
-			log.Fatal(err)
+			log.Println(err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка аренд")
+			return
 		}
 
 		var pages int
 		pages = int(math.Ceil(float64(totalCount) / float64(perPage)))
 
 		query := `
-		SELECT id, field_id, team_id, user_id, comment, start_date, end_date, duration, status, created_at 
-		FROM rentals
-		ORDER BY id
-		LIMIT $1 OFFSET $2
-	`
+		SELECT ` + repository.RentalViewColumns + `
+	` + from + `
+		ORDER BY ` + rentalSortOrders[filter.Sort] + `
+		LIMIT ` + conditions.arg(perPage) + ` OFFSET ` + conditions.arg(offset)
+
 		// Выполняем запрос
-		rows, errQuery := database.DB.Query(query, perPage, offset)
+		rows, errQuery := database.Conn(ctx).Query(query, conditions.args...)
 		if errQuery != nil {
 			log.Println(errQuery)
+			sendDatabaseError(w, errQuery, http.StatusInternalServerError, "Ошибка при получении списка аренд")
+			return
 		}
 		defer rows.Close()
-		// Преобразуем []RentalView в []interface{}
-		var rentalsInterface []interface{}
+
+		// Площадки, команды и пользователи аренд страницы загружаются пакетно
+		var loader repository.RentalLoader
 		for rows.Next() {
-			var rentalView models.RentalView
-			var fieldId int64
-			var teamId int64
-			var userId int64
-
-			if err := rows.Scan(
-				&rentalView.ID,
-				&fieldId,
-				&teamId,
-				&userId,
-				&rentalView.Comment,
-				&rentalView.StartDate,
-				&rentalView.EndDate,
-				&rentalView.Duration,
-				&rentalView.Status,
-				&rentalView.CreatedAt,
-			); err != nil {
+			if err := loader.Scan(rows); err != nil {
 				log.Println(err)
 			}
-			if fieldId != 0 {
-				errorField, fieldView := getOneFieldById(int64(fieldId))
-				if errorField != nil {
-					log.Println(errorField.Error())
-				} else {
-					rentalView.Field = fieldView
-				}
-			}
-			if teamId != 0 {
-				errorTeam, teamView := getOneTeamById(int64(teamId))
-				if errorTeam != nil {
-					log.Println(errorTeam.Error())
-				} else {
-					rentalView.Team = teamView
-				}
-			}
-			if userId != 0 {
-				errorUser, userView := getUserViewById(int64(userId))
-				if errorUser != nil {
-					log.Println(errorUser.Error())
-				} else {
-					rentalView.User = userView
-				}
-			}
-			rentalsInterface = append(rentalsInterface, rentalView)
-			//rentals = append(rentals, rentalView)
 		}
 		if err := rows.Err(); err != nil {
 			log.Println(err)
 		}
 
-		var fiter models.RentalSearchFilter
-		validationFilterJsonError := json.NewDecoder(r.Body).Decode(&fiter)
-		if validationFilterJsonError != nil {
-			log.Println(validationFilterJsonError.Error())
+		errLoad, rentalViews := loader.Load(ctx)
+		if errLoad != nil {
+			log.Println(errLoad)
+			sendDatabaseError(w, errLoad, http.StatusInternalServerError, "Ошибка при получении списка аренд")
+			return
 		}
-		validate := validator.New()
-		validationFilterError := validate.Struct(fiter)
-		if validationFilterError != nil {
-			log.Println(validationFilterError.Error())
+		// Преобразуем []RentalView в []interface{}
+		rentalsInterface := []interface{}{}
+		for _, rentalView := range rentalViews {
+			rentalsInterface = append(rentalsInterface, rentalView)
 		}
 
 		response := models.PaginationResponse{
@@ -128,7 +211,7 @@ func GetRentals() http.HandlerFunc {
 				TotalPages: pages,
 				TotalItems: totalCount,
 			},
-			Filter: fiter,
+			Filter: filter,
 			Data:   rentalsInterface,
 		}
 
@@ -136,53 +219,9 @@ func GetRentals() http.HandlerFunc {
 	}
 }
 
-func getOneRentalById(paramId int64) (error, models.RentalView) {
-	var rentalView models.RentalView
-	var fieldId int64
-	var teamId int64
-	var userId int64
-
-	err := database.DB.QueryRow("SELECT id, field_id, team_id, user_id, comment, start_date, end_date, duration, status, created_at FROM rentals WHERE id = $1", int64(paramId)).Scan(
-		&rentalView.ID,
-		&fieldId,
-		&teamId,
-		&userId,
-		&rentalView.Comment,
-		&rentalView.StartDate,
-		&rentalView.EndDate,
-		&rentalView.Duration,
-		&rentalView.Status,
-		&rentalView.CreatedAt,
-	)
-	if err != nil {
-		return err, rentalView
-	}
-	if fieldId != 0 {
-		errorField, fieldView := getOneFieldById(int64(fieldId))
-		if errorField != nil {
-			log.Println(errorField.Error())
-		} else {
-			rentalView.Field = fieldView
-		}
-	}
-	if teamId != 0 {
-		errorTeam, teamView := getOneTeamById(int64(teamId))
-		if errorTeam != nil {
-			log.Println(errorTeam.Error())
-		} else {
-			rentalView.Team = teamView
-		}
-	}
-	if userId != 0 {
-		errorUser, userView := getUserViewById(int64(userId))
-		if errorUser != nil {
-			log.Println(errorUser.Error())
-		} else {
-			rentalView.User = userView
-		}
-	}
-
-	return err, rentalView
+// getOneRentalById получает аренду по ID. Удаленные аренды не возвращаются.
+func getOneRentalById(ctx context.Context, paramId int64) (error, models.RentalView) {
+	return repository.RentalViewById(ctx, paramId)
 }
 
 // Документация для метода GetRental
@@ -196,12 +235,13 @@ func getOneRentalById(paramId int64) (error, models.RentalView) {
 // @Router /api/rentals/{id} [get]
 func GetRental() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		vars := mux.Vars(r)
 		paramId, _ := strconv.Atoi(vars["id"])
 
-		errorResponse, rentalView := getOneRentalById(int64(paramId))
+		errorResponse, rentalView := getOneRentalById(ctx, int64(paramId))
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 			return
 		}
 
@@ -209,6 +249,82 @@ func GetRental() http.HandlerFunc {
 	}
 }
 
+// rentalOccupiesFieldCondition - условие, при котором аренда занимает площадку
+var rentalOccupiesFieldCondition = fmt.Sprintf("deleted_at IS NULL AND status NOT IN (%d, %d)",
+	models.RentalStatusRejected,
+	models.RentalStatusCancelled,
+)
+
+// validateRentalPeriod проверяет корректность периода аренды
+func validateRentalPeriod(startDate time.Time, endDate time.Time) error {
+	if !endDate.After(startDate) {
+		return fmt.Errorf("Дата завершения аренды должна быть позже даты начала")
+	}
+	if startDate.Before(time.Now()) {
+		return fmt.Errorf("Нельзя забронировать площадку на прошедшее время")
+	}
+
+	return nil
+}
+
+// getConflictingRentals возвращает аренды площадки, пересекающиеся с указанным периодом
+func getConflictingRentals(ctx context.Context, fieldId int64, startDate time.Time, endDate time.Time) (error, []models.RentalView) {
+	conflicts := []models.RentalView{}
+
+	rows, err := database.Conn(ctx).Query(
+		"SELECT id FROM rentals "+
+			"WHERE field_id = $1 AND "+rentalOccupiesFieldCondition+" "+
+			"AND tstzrange(start_date, end_date, '[)') && tstzrange($2, $3, '[)') "+
+			"ORDER BY start_date", fieldId, startDate, endDate)
+	if err != nil {
+		return err, conflicts
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err, conflicts
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err, conflicts
+	}
+
+	for _, id := range ids {
+		errorRental, rentalView := getOneRentalById(ctx, id)
+		if errorRental != nil {
+			log.Println("Ошибка при получении пересекающейся аренды", id, errorRental.Error())
+			continue
+		}
+		conflicts = append(conflicts, rentalView)
+	}
+
+	return nil, conflicts
+}
+
+// isRentalOverlapError проверяет, что ошибка вызвана ограничением на пересечение аренд
+func isRentalOverlapError(err error) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == "23P01"
+	}
+	return false
+}
+
+// sendRentalConflict отправляет ответ 409 со списком пересекающихся аренд
+func sendRentalConflict(w http.ResponseWriter, conflicts []models.RentalView) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+
+	json.NewEncoder(w).Encode(models.RentalConflictResponse{
+		Code:      http.StatusConflict,
+		Message:   "Площадка уже забронирована на это время",
+		Conflicts: conflicts,
+	})
+}
+
 func validateCreateRentalRequest(r *http.Request) (error, models.CreateRentalRequest) {
 	var req models.CreateRentalRequest
 	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
@@ -224,16 +340,20 @@ func validateCreateRentalRequest(r *http.Request) (error, models.CreateRentalReq
 
 // Документация для метода CreateRental
 // @Summary Создание новой аренды
-// @Description Создание новой аренды
+// @Description Создание новой аренды. Если передано правило повторения, создается серия аренд (models.RentalSeriesView)
 // @Tags Аренда
 // @Param createRental body models.CreateRentalRequest true "Данные для создания новой аренды"
 // @Consumes application/json
 // @Produces application/json
 // @Success 201 {object} models.RentalView
+// @Failure 409 {object} models.RentalConflictResponse
 // @Failure 422 Unprocessable Entity
 // @Router /api/rentals [post]
 func CreateRental() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -252,51 +372,92 @@ func CreateRental() http.HandlerFunc {
 				return
 			}
 
-			var rental models.Rental
-			var status int
-
 			// Указываем формат
 			layout := "2006-01-02 15:04:05"
-			startDate, errTime := time.Parse(layout, rentalRequest.StartDate)
+			startDate, errTime := time.ParseInLocation(layout, rentalRequest.StartDate, appLocation())
 			if errTime != nil {
 				SendJSONError(w, http.StatusBadRequest, errTime.Error())
 				return
 			}
-			rental.StartDate = startDate
 
-			endDate, errTime := time.Parse(layout, rentalRequest.EndDate)
+			endDate, errTime := time.ParseInLocation(layout, rentalRequest.EndDate, appLocation())
 			if errTime != nil {
 				SendJSONError(w, http.StatusBadRequest, errTime.Error())
 				return
 			}
-			rental.EndDate = endDate
-
-			rental.FieldID = rentalRequest.FieldID
-			rental.TeamID = rentalRequest.TeamID
-			rental.Comment = rentalRequest.Comment
-
-			// Вычисляем разницу между двумя временами
-			duration := rental.EndDate.Sub(rental.StartDate)
-			// Преобразуем разницу в секунды
-			rental.Duration = int(duration.Seconds())
-
-			err := database.DB.QueryRow("INSERT INTO rentals (field_id, team_id, user_id, comment, start_date, end_date, duration, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
-				rental.FieldID,
-				rental.TeamID,
-				AUTH.ID,
-				rental.Comment,
-				rental.StartDate,
-				rental.EndDate,
-				rental.Duration,
-				&status,
-			).Scan(&rental.ID)
-			if err != nil {
-				log.Println(err)
+
+			errOccurrences, occurrences := buildRentalOccurrences(startDate, endDate, rentalRequest.Recurrence)
+			if errOccurrences != nil {
+				SendJSONError(w, http.StatusUnprocessableEntity, errOccurrences.Error())
+				return
 			}
 
-			errrental, rentalView := getOneRentalById(int64(rental.ID))
+			if errField, _ := getOneFieldById(ctx, rentalRequest.FieldID); errField != nil {
+				sendDatabaseError(w, errField, http.StatusNotFound, "Площадка не найдена")
+				return
+			}
+
+			// Каждая аренда серии проходит те же проверки, что и одиночная аренда
+			conflicts := []models.RentalView{}
+			for _, occurrence := range occurrences {
+				if errPeriod := validateRentalPeriod(occurrence.StartDate, occurrence.EndDate); errPeriod != nil {
+					SendJSONError(w, http.StatusUnprocessableEntity, occurrence.StartDate.Format(layout)+": "+errPeriod.Error())
+					return
+				}
+
+				errSchedule, isOpen := isWithinFieldSchedule(ctx, rentalRequest.FieldID, occurrence.StartDate, occurrence.EndDate)
+				if errSchedule != nil {
+					log.Println(errSchedule)
+					sendDatabaseError(w, errSchedule, http.StatusInternalServerError, "Ошибка при проверке расписания площадки")
+					return
+				}
+				if !isOpen {
+					SendJSONError(w, http.StatusUnprocessableEntity, occurrence.StartDate.Format(layout)+": Площадка не работает в выбранное время")
+					return
+				}
+
+				errConflicts, occurrenceConflicts := getConflictingRentals(ctx, rentalRequest.FieldID, occurrence.StartDate, occurrence.EndDate)
+				if errConflicts != nil {
+					log.Println(errConflicts)
+					sendDatabaseError(w, errConflicts, http.StatusInternalServerError, "Ошибка при проверке занятости площадки")
+					return
+				}
+				conflicts = append(conflicts, occurrenceConflicts...)
+			}
+			if len(conflicts) > 0 {
+				sendRentalConflict(w, conflicts)
+				return
+			}
+
+			errCreate, seriesId, rentalIds := createRentals(ctx, rentalRequest, occurrences, auth.ID)
+			if errCreate != nil {
+				// Параллельный запрос мог занять это время между проверкой и вставкой
+				if isRentalOverlapError(errCreate) {
+					for _, occurrence := range occurrences {
+						_, occurrenceConflicts := getConflictingRentals(ctx, rentalRequest.FieldID, occurrence.StartDate, occurrence.EndDate)
+						conflicts = append(conflicts, occurrenceConflicts...)
+					}
+					sendRentalConflict(w, conflicts)
+					return
+				}
+				log.Println(errCreate)
+				sendDatabaseError(w, errCreate, http.StatusInternalServerError, "Не удалось создать аренду")
+				return
+			}
+
+			if seriesId != nil {
+				errSeries, seriesView := getOneRentalSeriesById(ctx, *seriesId)
+				if errSeries != nil {
+					sendDatabaseError(w, errSeries, http.StatusBadRequest, errSeries.Error())
+					return
+				}
+				json.NewEncoder(w).Encode(seriesView)
+				return
+			}
+
+			errrental, rentalView := getOneRentalById(ctx, rentalIds[0])
 			if errrental != nil {
-				SendJSONError(w, http.StatusBadRequest, errrental.Error())
+				sendDatabaseError(w, errrental, http.StatusBadRequest, errrental.Error())
 				return
 			}
 			json.NewEncoder(w).Encode(rentalView)
@@ -311,7 +472,7 @@ func CreateRental() http.HandlerFunc {
 
 // Документация для метода Deleterental
 // @Summary Удаляет аренду по ID
-// @Description Удаление аренды по идентификатору
+// @Description Мягкое удаление аренды по идентификатору
 // @Tags Аренда
 // @Param id path int true "ID аренды"
 // @Success 204 No Content
@@ -319,16 +480,23 @@ func CreateRental() http.HandlerFunc {
 // @Router /api/rentals/{id} [delete]
 func DeleteRental() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		vars := mux.Vars(r)
 		paramId, _ := strconv.Atoi(vars["id"])
-		errorResponse, rentalView := getOneRentalById(int64(paramId))
+		errorResponse, rentalView := getOneRentalById(ctx, int64(paramId))
 		if errorResponse != nil {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		} else {
-			_, err := database.DB.Exec("DELETE FROM rentals WHERE id = $1 and user_id = $2", rentalView.ID, AUTH.ID)
-			if err != nil {
-				w.WriteHeader(http.StatusNotFound)
+			if !Can(*auth, models.PermissionRentalsDelete, rentalView.User.ID) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на удаление этой аренды")
+				return
+			}
+			if err, _ := softDeleteRow(database.Conn(ctx), "rentals", rentalView.ID, time.Now()); err != nil {
+				log.Println("Ошибка при удалении аренды", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при удалении аренды")
 				return
 			}
 
@@ -336,3 +504,25 @@ func DeleteRental() http.HandlerFunc {
 		}
 	}
 }
+
+// Документация для метода RestoreRental
+// @Summary Восстановление удаленной аренды
+// @Description Восстановление аренды, удаленной мягким удалением. Невозможно, если время уже занято другой арендой
+// @Tags Аренда
+// @Param id path int true "ID аренды"
+// @Produces application/json
+// @Success 200 {object} models.RentalView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/rentals/{id}/restore [post]
+func RestoreRental() http.HandlerFunc {
+	return restoreHandler(
+		deletedIdFromPath,
+		restoreById("rentals"),
+		func(ctx context.Context, id int64) (error, interface{}) {
+			return getOneRentalById(ctx, id)
+		},
+	)
+}
@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+)
+
+// scheduleTimeLayout - формат времени в расписании
+const scheduleTimeLayout = "15:04"
+
+// scheduleDateLayout - формат даты исключений расписания
+const scheduleDateLayout = "2006-01-02"
+
+// scheduleWindow - часы работы в течение суток в минутах от их начала
+type scheduleWindow struct {
+	open  int
+	close int
+}
+
+// parseScheduleTime переводит время "15:04" в количество минут от начала суток
+func parseScheduleTime(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse(scheduleTimeLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("Неверный формат времени '%s', ожидается '15:04'", value)
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// parseScheduleWindow разбирает и проверяет пару времени открытия и закрытия
+func parseScheduleWindow(openTime string, closeTime string) (scheduleWindow, error) {
+	var window scheduleWindow
+	var err error
+	if window.open, err = parseScheduleTime(openTime); err != nil {
+		return window, err
+	}
+	if window.close, err = parseScheduleTime(closeTime); err != nil {
+		return window, err
+	}
+	if window.close <= window.open {
+		return window, fmt.Errorf("Время закрытия '%s' должно быть позже времени открытия '%s'", closeTime, openTime)
+	}
+
+	return window, nil
+}
+
+// isoWeekday возвращает номер дня недели, где 1 - понедельник, 7 - воскресенье
+func isoWeekday(day time.Time) int {
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		return 7
+	}
+	return weekday
+}
+
+// getFieldSchedule получает расписание площадки
+func getFieldSchedule(ctx context.Context, fieldId int64) (error, models.FieldSchedule) {
+	schedule := models.FieldSchedule{
+		FieldID:    fieldId,
+		Days:       []models.FieldScheduleDay{},
+		Exceptions: []models.FieldScheduleException{},
+	}
+
+	rows, err := database.Conn(ctx).Query(
+		"SELECT weekday, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI') "+
+			"FROM field_schedules WHERE field_id = $1 ORDER BY weekday", fieldId)
+	if err != nil {
+		return err, schedule
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day models.FieldScheduleDay
+		if err := rows.Scan(&day.Weekday, &day.OpenTime, &day.CloseTime); err != nil {
+			return err, schedule
+		}
+		schedule.Days = append(schedule.Days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return err, schedule
+	}
+
+	exceptionRows, err := database.Conn(ctx).Query(
+		"SELECT to_char(date, 'YYYY-MM-DD'), is_closed, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI'), comment "+
+			"FROM field_schedule_exceptions WHERE field_id = $1 ORDER BY date", fieldId)
+	if err != nil {
+		return err, schedule
+	}
+	defer exceptionRows.Close()
+	for exceptionRows.Next() {
+		var exception models.FieldScheduleException
+		if err := exceptionRows.Scan(
+			&exception.Date,
+			&exception.IsClosed,
+			&exception.OpenTime,
+			&exception.CloseTime,
+			&exception.Comment,
+		); err != nil {
+			return err, schedule
+		}
+		schedule.Exceptions = append(schedule.Exceptions, exception)
+	}
+
+	return exceptionRows.Err(), schedule
+}
+
+// getFieldOpenIntervals возвращает интервалы работы площадки в периоде [from, to).
+// Если расписание не задано, площадка считается открытой круглосуточно и возвращается false.
+// Если заданы только исключения, в остальные дни площадка открыта круглосуточно.
+func getFieldOpenIntervals(ctx context.Context, fieldId int64, from time.Time, to time.Time) (error, []models.AvailabilityInterval, bool) {
+	intervals := []models.AvailabilityInterval{}
+
+	errSchedule, schedule := getFieldSchedule(ctx, fieldId)
+	if errSchedule != nil {
+		return errSchedule, intervals, false
+	}
+	if len(schedule.Days) == 0 && len(schedule.Exceptions) == 0 {
+		return nil, intervals, false
+	}
+
+	weekly := map[int]scheduleWindow{}
+	for _, day := range schedule.Days {
+		window, err := parseScheduleWindow(day.OpenTime, day.CloseTime)
+		if err != nil {
+			return err, intervals, true
+		}
+		weekly[day.Weekday] = window
+	}
+
+	// nil означает, что площадка закрыта весь день
+	exceptions := map[string]*scheduleWindow{}
+	for _, exception := range schedule.Exceptions {
+		if exception.IsClosed || exception.OpenTime == nil || exception.CloseTime == nil {
+			exceptions[exception.Date] = nil
+			continue
+		}
+		window, err := parseScheduleWindow(*exception.OpenTime, *exception.CloseTime)
+		if err != nil {
+			return err, intervals, true
+		}
+		exceptions[exception.Date] = &window
+	}
+
+	// Часы работы и даты исключений задаются в часовом поясе приложения,
+	// поэтому границы суток и время открытия строятся в нем, а не в UTC
+	loc := appLocation()
+	localFrom := from.In(loc)
+	for day := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		var window scheduleWindow
+		if exception, exists := exceptions[day.Format(scheduleDateLayout)]; exists {
+			if exception == nil {
+				continue
+			}
+			window = *exception
+		} else if len(weekly) == 0 {
+			window = scheduleWindow{open: 0, close: 24 * 60}
+		} else if weeklyWindow, exists := weekly[isoWeekday(day)]; exists {
+			window = weeklyWindow
+		} else {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, window.open, 0, 0, loc).UTC()
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, window.close, 0, 0, loc).UTC()
+		if start.Before(from) {
+			start = from.UTC()
+		}
+		if end.After(to) {
+			end = to.UTC()
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		// Склеиваем смежные интервалы, например работу до 24:00 и с 00:00 следующего дня
+		if last := len(intervals) - 1; last >= 0 && intervals[last].End.Equal(start) {
+			intervals[last].End = end
+			continue
+		}
+		intervals = append(intervals, models.AvailabilityInterval{
+			Start:  start,
+			End:    end,
+			Status: models.AvailabilityFree,
+		})
+	}
+
+	return nil, intervals, true
+}
+
+// isWithinFieldSchedule проверяет, что период [start, end) целиком попадает в часы работы площадки
+func isWithinFieldSchedule(ctx context.Context, fieldId int64, start time.Time, end time.Time) (error, bool) {
+	errOpen, openIntervals, hasSchedule := getFieldOpenIntervals(ctx, fieldId, start, end)
+	if errOpen != nil {
+		return errOpen, false
+	}
+	if !hasSchedule {
+		return nil, true
+	}
+
+	for _, interval := range openIntervals {
+		if !interval.Start.After(start.UTC()) && !interval.End.Before(end.UTC()) {
+			return nil, true
+		}
+	}
+
+	return nil, false
+}
+
+// getFieldClosedIntervals возвращает интервалы периода [from, to), когда площадка закрыта по расписанию
+func getFieldClosedIntervals(ctx context.Context, fieldId int64, from time.Time, to time.Time) (error, []models.AvailabilityInterval) {
+	closed := []models.AvailabilityInterval{}
+
+	errOpen, openIntervals, hasSchedule := getFieldOpenIntervals(ctx, fieldId, from, to)
+	if errOpen != nil || !hasSchedule {
+		return errOpen, closed
+	}
+
+	cursor := from
+	for _, interval := range openIntervals {
+		if interval.Start.After(cursor) {
+			closed = append(closed, models.AvailabilityInterval{
+				Start:  cursor,
+				End:    interval.Start,
+				Status: models.AvailabilityClosed,
+			})
+		}
+		cursor = interval.End
+	}
+	if to.After(cursor) {
+		closed = append(closed, models.AvailabilityInterval{
+			Start:  cursor,
+			End:    to,
+			Status: models.AvailabilityClosed,
+		})
+	}
+
+	return nil, closed
+}
+
+// validateUpdateFieldScheduleRequest проверяет данные для замены расписания площадки
+func validateUpdateFieldScheduleRequest(r *http.Request) (error, models.UpdateFieldScheduleRequest) {
+	var req models.UpdateFieldScheduleRequest
+	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
+		return validation, req
+	}
+	validate := validator.New()
+	if validation := validate.Struct(req); validation != nil {
+		return validation, req
+	}
+
+	weekdays := map[int]bool{}
+	for _, day := range req.Days {
+		if weekdays[day.Weekday] {
+			return fmt.Errorf("День недели %d указан несколько раз", day.Weekday), req
+		}
+		weekdays[day.Weekday] = true
+		if _, err := parseScheduleWindow(day.OpenTime, day.CloseTime); err != nil {
+			return err, req
+		}
+	}
+
+	dates := map[string]bool{}
+	for _, exception := range req.Exceptions {
+		if _, err := time.Parse(scheduleDateLayout, exception.Date); err != nil {
+			return fmt.Errorf("Неверный формат даты '%s', ожидается '2006-01-02'", exception.Date), req
+		}
+		if dates[exception.Date] {
+			return fmt.Errorf("Дата %s указана несколько раз", exception.Date), req
+		}
+		dates[exception.Date] = true
+		if exception.IsClosed {
+			continue
+		}
+		if exception.OpenTime == nil || exception.CloseTime == nil {
+			return fmt.Errorf("Для даты %s необходимо указать время работы или отметить её как выходной", exception.Date), req
+		}
+		if _, err := parseScheduleWindow(*exception.OpenTime, *exception.CloseTime); err != nil {
+			return err, req
+		}
+	}
+
+	return nil, req
+}
+
+// saveFieldSchedule заменяет расписание площадки
+func saveFieldSchedule(ctx context.Context, fieldId int64, req models.UpdateFieldScheduleRequest) error {
+	return database.WithTx(ctx, func(tx *database.Tx) error {
+		if _, err := tx.Exec("DELETE FROM field_schedules WHERE field_id = $1", fieldId); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM field_schedule_exceptions WHERE field_id = $1", fieldId); err != nil {
+			return err
+		}
+
+		for _, day := range req.Days {
+			if _, err := tx.Exec("INSERT INTO field_schedules (field_id, weekday, open_time, close_time) VALUES ($1, $2, $3, $4)",
+				fieldId,
+				day.Weekday,
+				day.OpenTime,
+				day.CloseTime,
+			); err != nil {
+				return err
+			}
+		}
+
+		for _, exception := range req.Exceptions {
+			openTime := exception.OpenTime
+			closeTime := exception.CloseTime
+			if exception.IsClosed {
+				openTime = nil
+				closeTime = nil
+			}
+			if _, err := tx.Exec("INSERT INTO field_schedule_exceptions (field_id, date, is_closed, open_time, close_time, comment) VALUES ($1, $2, $3, $4, $5, $6)",
+				fieldId,
+				exception.Date,
+				exception.IsClosed,
+				openTime,
+				closeTime,
+				exception.Comment,
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Документация для метода GetFieldSchedule
+// @Summary Расписание площадки
+// @Description Получение часов работы площадки по дням недели и исключений
+// @Tags Площадки
+// @Param slug path string true "Slug площадки"
+// @Produces application/json
+// @Success 200 {object} models.FieldSchedule
+// @Failure 404 Not Found
+// @Router /api/fields/{slug}/schedule [get]
+func GetFieldSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+		slug := vars["slug"]
+
+		errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
+		if errorResponse != nil {
+			sendDatabaseError(w, errorResponse, http.StatusNotFound, "Не смог найти площадку: "+slug)
+			return
+		}
+
+		errSchedule, schedule := getFieldSchedule(ctx, fieldView.ID)
+		if errSchedule != nil {
+			log.Println("Ошибка при получении расписания площадки", errSchedule)
+			sendDatabaseError(w, errSchedule, http.StatusInternalServerError, "Ошибка при получении расписания площадки")
+			return
+		}
+
+		json.NewEncoder(w).Encode(schedule)
+	}
+}
+
+// Документация для метода UpdateFieldSchedule
+// @Summary Изменение расписания площадки
+// @Description Полная замена часов работы площадки и исключений. Дни недели, которых нет в расписании, считаются выходными.
+// @Tags Площадки
+// @Param slug path string true "Slug площадки"
+// @Param updateFieldSchedule body models.UpdateFieldScheduleRequest true "Расписание площадки"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} models.FieldSchedule
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Router /api/fields/{slug}/schedule [put]
+func UpdateFieldSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			vars := mux.Vars(r)
+			slug := vars["slug"]
+
+			errorResponse, fieldView := getOneFieldBySlug(ctx, slug)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Не смог найти площадку: "+slug)
+				return
+			}
+
+			// Проверка на право редактирования
+			if !Can(*auth, models.PermissionFieldsUpdate, fieldView.Responsible.ID) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на редактирование этой площадки")
+				return
+			}
+
+			validation, scheduleRequest := validateUpdateFieldScheduleRequest(r)
+			if validation != nil {
+				SendJSONError(w, http.StatusBadRequest, validation.Error())
+				return
+			}
+
+			if errSave := saveFieldSchedule(ctx, fieldView.ID, scheduleRequest); errSave != nil {
+				log.Println("Ошибка при сохранении расписания площадки", errSave)
+				sendDatabaseError(w, errSave, http.StatusInternalServerError, "Ошибка при сохранении расписания площадки")
+				return
+			}
+
+			errSchedule, schedule := getFieldSchedule(ctx, fieldView.ID)
+			if errSchedule != nil {
+				sendDatabaseError(w, errSchedule, http.StatusInternalServerError, errSchedule.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(schedule)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scheduleDB отвечает на запросы расписания: площадка работает ежедневно с 08:00 до 23:00,
+// в дни closedDates закрыта
+func scheduleDB(tb testing.TB, closedDates ...string) *dbtest.DB {
+	return dbtest.Open(tb, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.Contains(query, "FROM field_schedules"):
+			var result dbtest.Result
+			for weekday := int64(1); weekday <= 7; weekday++ {
+				result.Rows = append(result.Rows, []driver.Value{weekday, "08:00", "23:00"})
+			}
+			return result, nil
+		case strings.Contains(query, "FROM field_schedule_exceptions"):
+			result := dbtest.Result{Columns: []string{"date", "is_closed", "open_time", "close_time", "comment"}}
+			for _, date := range closedDates {
+				result.Rows = append(result.Rows, []driver.Value{date, true, nil, nil, nil})
+			}
+			return result, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+}
+
+func mustParseTime(tb testing.TB, value string) time.Time {
+	tb.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return parsed
+}
+
+func TestGetFieldOpenIntervalsUsesAppTimezone(t *testing.T) {
+	cases := []struct {
+		name     string
+		timezone string
+		closed   []string
+		from     string
+		to       string
+		want     []string
+	}{
+		{"moscow", "Europe/Moscow", nil, "2026-10-18T21:00:00Z", "2026-10-19T21:00:00Z",
+			[]string{"2026-10-19T05:00:00Z", "2026-10-19T20:00:00Z"}},
+		{"utc by default", "", nil, "2026-10-19T00:00:00Z", "2026-10-20T00:00:00Z",
+			[]string{"2026-10-19T08:00:00Z", "2026-10-19T23:00:00Z"}},
+		// Выходной начинается в полночь по местному времени, а не в 03:00
+		{"closed day", "Europe/Moscow", []string{"2026-10-19"}, "2026-10-18T21:00:00Z", "2026-10-20T21:00:00Z",
+			[]string{"2026-10-20T05:00:00Z", "2026-10-20T20:00:00Z"}},
+		{"period inside the day", "Europe/Moscow", nil, "2026-10-19T04:00:00Z", "2026-10-19T06:00:00Z",
+			[]string{"2026-10-19T05:00:00Z", "2026-10-19T06:00:00Z"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("APP_TIMEZONE", tc.timezone)
+			scheduleDB(t, tc.closed...)
+
+			err, intervals, hasSchedule := getFieldOpenIntervals(context.Background(), 1, mustParseTime(t, tc.from), mustParseTime(t, tc.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !hasSchedule {
+				t.Fatal("hasSchedule = false")
+			}
+
+			var got []string
+			for _, interval := range intervals {
+				got = append(got, interval.Start.Format(time.RFC3339), interval.End.Format(time.RFC3339))
+			}
+			if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+				t.Fatalf("intervals = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsWithinFieldScheduleParsesLocalRentalTime(t *testing.T) {
+	t.Setenv("APP_TIMEZONE", "Europe/Moscow")
+	scheduleDB(t)
+
+	cases := []struct {
+		start  string
+		end    string
+		within bool
+	}{
+		{"2026-10-19 08:00:00", "2026-10-19 10:00:00", true},
+		{"2026-10-19 21:00:00", "2026-10-19 23:00:00", true},
+		{"2026-10-19 07:00:00", "2026-10-19 09:00:00", false},
+		{"2026-10-19 22:00:00", "2026-10-20 00:00:00", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.start, func(t *testing.T) {
+			start, err := parseDateParam(tc.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			end, err := parseDateParam(tc.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err, within := isWithinFieldSchedule(context.Background(), 1, start, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if within != tc.within {
+				t.Fatalf("isWithinFieldSchedule = %v, want %v", within, tc.within)
+			}
+		})
+	}
+}
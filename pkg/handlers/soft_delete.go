@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"goland_api/pkg/database"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// errRestoreConflict - восстановление невозможно, т.к. запись конфликтует с существующими
+var errRestoreConflict = errors.New("Восстановление невозможно: запись конфликтует с существующими")
+
+// softDeleteRow помечает запись таблицы table удаленной в момент deletedAt.
+// Возвращает false, если запись не найдена или уже удалена.
+// table передается только из кода, пользовательский ввод сюда не попадает.
+func softDeleteRow(executor sqlExecutor, table string, id int64, deletedAt time.Time) (error, bool) {
+	result, err := executor.Exec("UPDATE "+table+" SET deleted_at = $1, updated_at = now() WHERE id = $2 AND deleted_at IS NULL", deletedAt, id)
+	if err != nil {
+		return err, false
+	}
+	affected, err := result.RowsAffected()
+	return err, affected > 0
+}
+
+// restoreRow снимает пометку удаления с записи таблицы table и возвращает момент, когда она была удалена.
+// Возвращает false, если удаленная запись не найдена.
+func restoreRow(tx *database.Tx, table string, id int64) (error, time.Time, bool) {
+	var deletedAt time.Time
+	err := tx.QueryRow("SELECT deleted_at FROM "+table+" WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE", id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return nil, deletedAt, false
+	}
+	if err != nil {
+		return err, deletedAt, false
+	}
+
+	_, err = tx.Exec("UPDATE "+table+" SET deleted_at = NULL, updated_at = now() WHERE id = $1", id)
+	if err != nil {
+		return err, deletedAt, false
+	}
+	return nil, deletedAt, true
+}
+
+// restoreById возвращает функцию восстановления одной записи таблицы table без связанных данных
+func restoreById(table string) func(tx *database.Tx, id int64) (error, bool) {
+	return func(tx *database.Tx, id int64) (error, bool) {
+		err, _, restored := restoreRow(tx, table, id)
+		return err, restored
+	}
+}
+
+// deletedIdFromPath возвращает ID удаленной записи из параметра пути id
+func deletedIdFromPath(ctx context.Context, vars map[string]string) (error, int64) {
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		return sql.ErrNoRows, 0
+	}
+	return nil, id
+}
+
+// isRestoreConflict проверяет, что восстановление нарушило уникальность или пересечение аренд
+func isRestoreConflict(err error) bool {
+	if err == errRestoreConflict {
+		return true
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == "23505" || pqErr.Code == "23P01"
+	}
+	return false
+}
+
+// restoreHandler возвращает обработчик восстановления удаленной записи.
+// findDeleted находит ID удаленной записи по параметрам пути, restore восстанавливает её в транзакции,
+// load получает восстановленную запись для ответа.
+func restoreHandler(
+	findDeleted func(ctx context.Context, vars map[string]string) (error, int64),
+	restore func(tx *database.Tx, id int64) (error, bool),
+	load func(ctx context.Context, id int64) (error, interface{}),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			ctx := r.Context()
+			errFind, id := findDeleted(ctx, mux.Vars(r))
+			if errFind == sql.ErrNoRows {
+				SendJSONError(w, http.StatusNotFound, "Удаленная запись не найдена")
+				return
+			}
+			if errFind != nil {
+				log.Println("Ошибка при поиске удаленной записи", errFind)
+				sendDatabaseError(w, errFind, http.StatusInternalServerError, "Ошибка при восстановлении")
+				return
+			}
+
+			restored := false
+			errRestore := database.WithTx(ctx, func(tx *database.Tx) error {
+				var err error
+				err, restored = restore(tx, id)
+				return err
+			})
+			if errRestore != nil {
+				if isRestoreConflict(errRestore) {
+					SendJSONError(w, http.StatusConflict, errRestoreConflict.Error())
+					return
+				}
+				log.Println("Ошибка при восстановлении", errRestore)
+				sendDatabaseError(w, errRestore, http.StatusInternalServerError, "Ошибка при восстановлении")
+				return
+			}
+			if !restored {
+				SendJSONError(w, http.StatusNotFound, "Удаленная запись не найдена")
+				return
+			}
+
+			errLoad, item := load(ctx, id)
+			if errLoad != nil {
+				sendDatabaseError(w, errLoad, http.StatusBadRequest, errLoad.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(item)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
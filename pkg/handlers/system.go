@@ -1,107 +1,175 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"log"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
-// AUTH - глобальная переменная с авторизацией
-var AUTH *models.UserView
+// authUserKey - ключ контекста запроса, в котором хранится авторизованный пользователь
+type authUserKey struct{}
 
-// Массив допустимых ролей
-var UserRoles = map[int]bool{
-	1: true,
-	2: true,
+// authSessionKey - ключ контекста запроса, в котором хранится идентификатор сессии
+type authSessionKey struct{}
+
+// getAuthUser возвращает пользователя, авторизованного в текущем запросе.
+// Возвращает nil, если запрос не прошел через AuthMiddleware.
+func getAuthUser(r *http.Request) *models.UserView {
+	user, _ := r.Context().Value(authUserKey{}).(*models.UserView)
+	return user
+}
+
+// getAuthSessionID возвращает идентификатор сессии, к которой привязан токен текущего запроса
+func getAuthSessionID(r *http.Request) string {
+	sessionId, _ := r.Context().Value(authSessionKey{}).(string)
+	return sessionId
+}
+
+// trustedProxies возвращает сети доверенных прокси-серверов из TRUSTED_PROXIES:
+// IP-адреса и подсети CIDR через запятую. Неверные значения пропускаются.
+func trustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, value := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil {
+				proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			}
+			continue
+		}
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+	return proxies
+}
+
+// isTrustedProxy проверяет, что адрес ip принадлежит одной из сетей proxies
+func isTrustedProxy(ip string, proxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
 }
-var AdminRoles = map[int]bool{
-	10: true,
-	11: true,
+
+// getClientIP возвращает IP-адрес клиента. Заголовок X-Forwarded-For учитывается, только если
+// запрос пришел от доверенного прокси из TRUSTED_PROXIES, иначе клиент может подставить любой адрес.
+// Цепочка адресов читается справа налево до первого адреса, не принадлежащего доверенным прокси.
+func getClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	proxies := trustedProxies()
+	if !isTrustedProxy(ip, proxies) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		// Адрес, который не удалось разобрать, добавлен не доверенным прокси
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !isTrustedProxy(hop, proxies) {
+			break
+		}
+	}
+	return ip
 }
 
-// Middleware для проверки авторизации
-func getAuth(w http.ResponseWriter, r *http.Request) {
+// getAuth проверяет токен запроса и возвращает запрос, в контексте которого сохранены
+// авторизованный пользователь и его сессия. При ошибке отправляет ответ клиенту и возвращает nil.
+func getAuth(w http.ResponseWriter, r *http.Request) *http.Request {
+	ctx := r.Context()
 	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") || len(authHeader) == len("Bearer ") {
+		SendJSONError(w, http.StatusUnauthorized, "Требуется авторизация")
+		return nil
+	}
 	tokenString := authHeader[len("Bearer "):]
 	token, errToken := ParseToken(tokenString)
 	if errToken != nil {
-		SendJSONError(w, http.StatusBadRequest, "Неверный токен")
-		return
+		SendJSONError(w, http.StatusUnauthorized, "Неверный токен")
+		return nil
 	}
 
-	if !token.Valid {
-		SendJSONError(w, http.StatusUnauthorized, "Invalid token")
-		return
+	errorResponse, userView := getUserFromToken(ctx, token)
+	if errorResponse != nil {
+		sendDatabaseError(w, errorResponse, http.StatusUnauthorized, "Неверный токен")
+		return nil
 	}
 
-	errorResponse, userView := getUserFromToken(token)
-	if errorResponse != nil {
-		SendJSONError(w, http.StatusBadRequest, "Неверный токен")
-		return
+	if userView.Status == models.UserStatusBlocked {
+		SendJSONError(w, http.StatusForbidden, "Пользователь заблокирован")
+		return nil
 	}
-	AUTH = userView
-	return
-}
 
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		getAuth(w, r)
-		// Если токен валиден, передаем запрос следующему обработчику
-		next.ServeHTTP(w, r)
-	})
-}
+	// Токены завершенной сессии больше не принимаются
+	sessionId := token.Claims.(*models.Claims).SessionID
+	errSession, active := isSessionActive(ctx, sessionId, userView.ID)
+	if errSession != nil {
+		sendDatabaseError(w, errSession, http.StatusInternalServerError, "Ошибка при проверке сессии")
+		return nil
+	}
+	if !active {
+		SendJSONError(w, http.StatusUnauthorized, "Сессия завершена")
+		return nil
+	}
 
-func AuthUserMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		getAuth(w, r)
-		if _, exists := UserRoles[AUTH.Role.ID]; !exists {
-			SendJSONError(w, http.StatusBadRequest, "Роль пользователя недопустима")
-			return
-		}
-		// Если токен валиден, передаем запрос следующему обработчику
-		next.ServeHTTP(w, r)
-	})
+	// Права роли загружаются при каждом запросе, чтобы изменения ролей действовали сразу
+	errPermissions, permissions := getRolePermissions(ctx, userView.Role.ID)
+	if errPermissions != nil {
+		sendDatabaseError(w, errPermissions, http.StatusInternalServerError, "Ошибка при получении прав пользователя")
+		return nil
+	}
+	userView.Role.Permissions = permissions
+
+	ctx = context.WithValue(ctx, authUserKey{}, userView)
+	ctx = context.WithValue(ctx, authSessionKey{}, sessionId)
+	return r.WithContext(ctx)
 }
 
-func AuthAdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// Middleware для проверки авторизации.
+// Авторизованный пользователь передается обработчику в контексте запроса.
+func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		getAuth(w, r)
-		if _, exists := AdminRoles[AUTH.Role.ID]; !exists {
-			SendJSONError(w, http.StatusBadRequest, "Роль пользователя недопустима")
+		authRequest := getAuth(w, r)
+		if authRequest == nil {
 			return
 		}
 		// Если токен валиден, передаем запрос следующему обработчику
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, authRequest)
 	})
 }
 
-/**
-* Функция проверяет, что пользователь является клиентов
- */
-func IsUser(auth models.UserView) bool {
-	if _, exists := UserRoles[auth.Role.ID]; !exists {
-		return false
-	}
-	return true
-}
-
-/**
-* Функция проверяет, что пользователь является админом
- */
-func IsAdmin(auth models.UserView) bool {
-	if _, exists := AdminRoles[auth.Role.ID]; !exists {
-		return false
-	}
-	return true
-}
-
 // Middleware для обработки CORS
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -109,10 +177,13 @@ func CORS(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
 		// Разрешаем методы, которые могут быть использованы
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, PATCH, HEAD")
 
 		// Разрешаем заголовки, которые могут быть отправлены
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Offset")
+
+		// Разрешаем читать заголовки загрузки по частям
+		w.Header().Set("Access-Control-Expose-Headers", "Location, Upload-Offset, Upload-Length")
 
 		// Если это OPTIONS запрос, просто завершаем его
 		if r.Method == http.MethodOptions {
@@ -148,6 +219,20 @@ func SendJSONError(w http.ResponseWriter, code int, message string) {
 	}
 }
 
+// sendDatabaseError отправляет ответ об ошибке, возникшей при обращении к базе данных.
+// Превышение времени запроса возвращается как 504, недоступность базы данных - как 503,
+// остальные ошибки - с кодом code и сообщением message.
+func sendDatabaseError(w http.ResponseWriter, err error, code int, message string) {
+	switch {
+	case database.IsTimeout(err):
+		SendJSONError(w, http.StatusGatewayTimeout, "Превышено время ожидания ответа базы данных")
+	case database.IsUnavailable(err):
+		SendJSONError(w, http.StatusServiceUnavailable, "База данных временно недоступна")
+	default:
+		SendJSONError(w, code, message)
+	}
+}
+
 var (
 	validate *validator.Validate
 	trans    ut.Translator
@@ -167,6 +252,159 @@ func getIntParam(params url.Values, key string, defaultValue int) int {
 	return result
 }
 
+// getBoolParam возвращает логическое значение параметра или nil, если параметр не передан
+func getBoolParam(params url.Values, key string) (*bool, error) {
+	value := params.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("Неверное значение параметра '%s': %s", key, value)
+	}
+
+	return &result, nil
+}
+
+// getFloatParam возвращает числовое значение параметра или nil, если параметр не передан
+func getFloatParam(params url.Values, key string) (*float64, error) {
+	value := params.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(result) || math.IsInf(result, 0) {
+		return nil, fmt.Errorf("Неверное значение параметра '%s': %s", key, value)
+	}
+
+	return &result, nil
+}
+
+// getListParam возвращает значения параметра, переданные через запятую или повтором параметра
+func getListParam(params url.Values, key string) []string {
+	var result []string
+	for _, value := range params[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
+}
+
+// getInt64ListParam возвращает список идентификаторов из параметра запроса
+func getInt64ListParam(params url.Values, key string) ([]int64, error) {
+	var result []int64
+	for _, item := range getListParam(params, key) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Неверное значение параметра '%s': %s", key, item)
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+// getPagination возвращает номер страницы и размер страницы из параметров запроса
+func getPagination(params url.Values) (int, int) {
+	page := getIntParam(params, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := getIntParam(params, "per_page", 10)
+	if perPage < 1 || perPage > 100 {
+		perPage = 10
+	}
+
+	return page, perPage
+}
+
+// sqlConditions собирает условия WHERE с позиционными параметрами
+type sqlConditions struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add добавляет условие, каждый символ ? заменяется на номер очередного параметра
+func (c *sqlConditions) add(condition string, args ...interface{}) {
+	for _, arg := range args {
+		condition = strings.Replace(condition, "?", c.arg(arg), 1)
+	}
+	c.conditions = append(c.conditions, condition)
+}
+
+// arg добавляет параметр и возвращает его плейсхолдер
+func (c *sqlConditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+// where возвращает секцию WHERE или пустую строку, если условий нет
+func (c *sqlConditions) where() string {
+	if len(c.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.conditions, " AND ")
+}
+
+// likeEscaper экранирует служебные символы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern возвращает шаблон ILIKE для поиска подстроки search.
+// Символы %, _ и \ в search ищутся буквально, в условии указывается ESCAPE '\'.
+func containsPattern(search string) string {
+	return "%" + likeEscaper.Replace(search) + "%"
+}
+
+// Допустимые форматы дат в параметрах запроса
+var dateParamLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// appLocations - загруженные часовые пояса по названию
+var appLocations sync.Map
+
+// appLocation возвращает часовой пояс приложения из APP_TIMEZONE, например Europe/Moscow.
+// В нем задаются расписания площадок и разбираются даты без указания смещения.
+// Если пояс не задан или неизвестен, используется UTC.
+func appLocation() *time.Location {
+	name := os.Getenv("APP_TIMEZONE")
+	if name == "" {
+		return time.UTC
+	}
+	if loc, ok := appLocations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Println("Неизвестный часовой пояс APP_TIMEZONE, используется UTC", name, err)
+		loc = time.UTC
+	}
+	appLocations.Store(name, loc)
+	return loc
+}
+
+// parseDateParam разбирает дату из параметра запроса в одном из допустимых форматов.
+// Дата без смещения считается указанной в часовом поясе приложения.
+func parseDateParam(value string) (time.Time, error) {
+	for _, layout := range dateParamLayouts {
+		if result, err := time.ParseInLocation(layout, value, appLocation()); err == nil {
+			return result, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Неверный формат даты '%s', ожидается '2006-01-02 15:04:05'", value)
+}
+
 // varDump will print out any number of variables given to it
 // e.g. varDump("test", 1234)
 func varDump(myVar ...interface{}) {
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql/driver"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// Секрет подписи токенов в тестах
+const testJWTSecret = "test-secret"
+
+// authDB отвечает на запросы getAuth: пользователь с любым ID существует и не заблокирован,
+// сессии из revoked завершены, у роли нет прав
+func authDB(tb testing.TB, revoked ...string) *dbtest.DB {
+	return dbtest.Open(tb, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.Contains(query, "FROM users u") && strings.Contains(query, "u.id = $1"):
+			userId := args[0].(int64)
+			return dbtest.Result{Rows: [][]driver.Value{{
+				userId, fmt.Sprintf("User %d", userId), fmt.Sprintf("user%d@example.com", userId),
+				nil, nil, nil, nil, int64(models.UserStatusVerified), time.Now(), int64(2), "user",
+			}}}, nil
+		case strings.Contains(query, "FROM refresh_tokens"):
+			active := true
+			for _, sessionId := range revoked {
+				if args[0] == sessionId {
+					active = false
+				}
+			}
+			return dbtest.Result{Rows: [][]driver.Value{{active}}}, nil
+		case strings.Contains(query, "FROM role_permissions"):
+			return dbtest.Result{Columns: []string{"code"}}, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+}
+
+// signTestToken подписывает токен с claims методом method
+func signTestToken(tb testing.TB, method jwt.SigningMethod, key interface{}, claims models.Claims) string {
+	tb.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return token
+}
+
+// testClaims возвращает claims действующего токена пользователя userId в сессии sessionId
+func testClaims(userId int64, sessionId string) models.Claims {
+	now := time.Now()
+	return models.Claims{
+		Username:  fmt.Sprintf("User %d", userId),
+		SessionID: sessionId,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.FormatInt(userId, 10),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Minute).Unix(),
+		},
+	}
+}
+
+type forgedToken struct {
+	name  string
+	token string
+}
+
+// forgedTokens возвращает токены, которые не должны приниматься
+func forgedTokens(tb testing.TB) []forgedToken {
+	tb.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	expired := testClaims(1, "session")
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	noExpiry := testClaims(1, "session")
+	noExpiry.ExpiresAt = 0
+	nonNumericSubject := testClaims(1, "session")
+	nonNumericSubject.Subject = "user@example.com"
+
+	return []forgedToken{
+		{"alg none", signTestToken(tb, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testClaims(1, "session"))},
+		{"RS256", signTestToken(tb, jwt.SigningMethodRS256, rsaKey, testClaims(1, "session"))},
+		{"HS512", signTestToken(tb, jwt.SigningMethodHS512, []byte(testJWTSecret), testClaims(1, "session"))},
+		{"wrong secret", signTestToken(tb, jwt.SigningMethodHS256, []byte("wrong-secret"), testClaims(1, "session"))},
+		{"expired", signTestToken(tb, jwt.SigningMethodHS256, []byte(testJWTSecret), expired)},
+		{"no exp", signTestToken(tb, jwt.SigningMethodHS256, []byte(testJWTSecret), noExpiry)},
+		{"non-numeric sub", signTestToken(tb, jwt.SigningMethodHS256, []byte(testJWTSecret), nonNumericSubject)},
+		{"garbage", "not.a.token"},
+	}
+}
+
+func TestAuthMiddlewareRejectsForgedTokens(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	authDB(t, "revoked")
+
+	valid := signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), testClaims(1, "session"))
+	cases := []struct {
+		name          string
+		authorization string
+	}{
+		{"no header", ""},
+		{"empty bearer", "Bearer "},
+		{"basic scheme", "Basic " + valid},
+		{"token without scheme", valid},
+		{"revoked session", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), testClaims(1, "revoked"))},
+		{"no session", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), testClaims(1, ""))},
+	}
+	for _, forged := range forgedTokens(t) {
+		cases = append(cases, struct {
+			name          string
+			authorization string
+		}{forged.name, "Bearer " + forged.token})
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			handler := AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+			if called {
+				t.Error("next handler was called")
+			}
+		})
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		var user *models.UserView
+		handler := AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			user = getAuthUser(r)
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+		r.Header.Set("Authorization", "Bearer "+valid)
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		if user == nil || user.ID != 1 {
+			t.Fatalf("auth user = %+v, want user 1", user)
+		}
+	})
+}
+
+// Пользователь сохраняется в контексте запроса, поэтому параллельные запросы
+// разных пользователей не видят чужого пользователя. Запускается с -race.
+func TestAuthMiddlewareConcurrentUsers(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	authDB(t)
+
+	handler := AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUser(r)
+		if user == nil {
+			http.Error(w, "no auth user", http.StatusInternalServerError)
+			return
+		}
+		// Уступаем другим горутинам, пока запрос обрабатывается
+		time.Sleep(time.Millisecond)
+		w.Write([]byte(strconv.FormatInt(user.ID, 10)))
+	})
+
+	const users = 20
+	const requestsPerUser = 10
+	tokens := make([]string, users+1)
+	for userId := 1; userId <= users; userId++ {
+		tokens[userId] = signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret),
+			testClaims(int64(userId), fmt.Sprintf("session-%d", userId)))
+	}
+
+	var wg sync.WaitGroup
+	for userId := 1; userId <= users; userId++ {
+		for i := 0; i < requestsPerUser; i++ {
+			wg.Add(1)
+			go func(userId int) {
+				defer wg.Done()
+
+				r := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+				r.Header.Set("Authorization", "Bearer "+tokens[userId])
+				w := httptest.NewRecorder()
+				handler(w, r)
+
+				if w.Code != http.StatusOK {
+					t.Errorf("user %d: status = %d, body = %s", userId, w.Code, w.Body.String())
+					return
+				}
+				if got := w.Body.String(); got != strconv.Itoa(userId) {
+					t.Errorf("user %d: handler saw user %s", userId, got)
+				}
+			}(userId)
+		}
+	}
+	wg.Wait()
+}
+
+func TestGetClientIP(t *testing.T) {
+	cases := []struct {
+		name       string
+		proxies    string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"no proxies", "", "203.0.113.5:1234", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"untrusted peer", "10.0.0.0/8", "203.0.113.5:1234", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"trusted peer", "10.0.0.0/8", "10.0.0.2:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"trusted peer without header", "10.0.0.0/8", "10.0.0.2:1234", nil, "10.0.0.2"},
+		{"spoofed chain", "10.0.0.0/8", "10.0.0.2:1234", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", "10.0.0.1,10.0.0.2", "10.0.0.2:1234", []string{"198.51.100.1, 10.0.0.1"}, "198.51.100.1"},
+		{"several headers", "10.0.0.0/8", "10.0.0.2:1234", []string{"1.1.1.1", "198.51.100.1"}, "198.51.100.1"},
+		{"garbage hop", "10.0.0.0/8", "10.0.0.2:1234", []string{"198.51.100.1, garbage"}, "10.0.0.2"},
+		{"ipv6", "2001:db8::/32", "[2001:db8::1]:1234", []string{"2001:db9::1, 2001:db8::2"}, "2001:db9::1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tc.proxies)
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := getClientIP(r); got != tc.want {
+				t.Fatalf("getClientIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,836 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Срок действия приглашения в команду
+const teamInvitationTTL = 7 * 24 * time.Hour
+
+// teamInvitationColumns - колонки таблицы team_invitations, необходимые для заполнения TeamInvitationView
+const teamInvitationColumns = "id, team_id, email, phone, invitee_id, role, status, invited_by, user_id, expires_at, responded_at, created_at"
+
+// canManageTeam проверяет, что пользователь может управлять составом команды.
+// Составом управляет капитан (ответственный за команду) или администратор.
+func canManageTeam(auth models.UserView, teamView models.TeamView) bool {
+	return Can(auth, models.PermissionTeamsUpdate, teamView.Responsible.ID)
+}
+
+// getTeamMembers получает состав команды, капитан идет первым
+func getTeamMembers(ctx context.Context, teamId int64) (error, []models.TeamMemberView) {
+	members := []models.TeamMemberView{}
+
+	rows, err := database.Conn(ctx).Query("SELECT tm.id, tm.team_id, tm.user_id, tm.role, tm.created_at FROM team_members tm "+
+		"JOIN users u ON u.id = tm.user_id "+
+		"WHERE tm.team_id = $1 AND u.deleted_at IS NULL "+
+		"ORDER BY tm.role = $2 DESC, tm.created_at, tm.id", teamId, models.TeamRoleCaptain)
+	if err != nil {
+		return err, members
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.TeamMemberView
+		if err := rows.Scan(&member.ID, &member.TeamID, &member.User.ID, &member.Role, &member.CreatedAt); err != nil {
+			return err, members
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return err, members
+	}
+
+	userIds := make([]int64, len(members))
+	for i := range members {
+		userIds[i] = members[i].User.ID
+	}
+	errUsers, users := repository.UserViewsByIds(ctx, userIds)
+	if errUsers != nil {
+		return errUsers, members
+	}
+	for i := range members {
+		userView, ok := users[members[i].User.ID]
+		if !ok {
+			log.Println("Ошибка при получении участника команды", members[i].User.ID)
+			continue
+		}
+		members[i].User = userView
+	}
+
+	return nil, members
+}
+
+// getTeamMember получает участника команды по идентификатору пользователя
+func getTeamMember(ctx context.Context, teamId int64, userId int64) (error, models.TeamMemberView) {
+	var member models.TeamMemberView
+
+	err := database.Conn(ctx).QueryRow("SELECT id, team_id, user_id, role, created_at FROM team_members WHERE team_id = $1 AND user_id = $2", teamId, userId).Scan(
+		&member.ID,
+		&member.TeamID,
+		&member.User.ID,
+		&member.Role,
+		&member.CreatedAt,
+	)
+	if err != nil {
+		return err, member
+	}
+
+	errorUser, userView := getUserViewById(ctx, member.User.ID)
+	if errorUser != nil {
+		log.Println("Ошибка при получении участника команды", member.User.ID, errorUser.Error())
+	} else {
+		member.User = userView
+	}
+
+	return nil, member
+}
+
+// transferTeamCaptain передает управление командой другому участнику.
+// Прежний капитан остается в команде игроком. Транзакция сериализуемая: при параллельной передаче
+// управления прежнего капитана нашла бы только одна из транзакций, и в команде оказалось бы два капитана.
+func transferTeamCaptain(ctx context.Context, teamId int64, userId int64) error {
+	return database.WithTxOptions(ctx, database.Serializable, func(tx *database.Tx) error {
+		_, err := tx.Exec("UPDATE team_members SET role = $1, updated_at = now() WHERE team_id = $2 AND role = $3",
+			models.TeamRolePlayer, teamId, models.TeamRoleCaptain)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE team_members SET role = $1, updated_at = now() WHERE team_id = $2 AND user_id = $3",
+			models.TeamRoleCaptain, teamId, userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE teams SET responsible_id = $1, updated_at = now() WHERE id = $2", userId, teamId)
+		return err
+	})
+}
+
+// expireTeamInvitations переводит просроченные приглашения в статус "истекло"
+func expireTeamInvitations(ctx context.Context) error {
+	_, err := database.Conn(ctx).Exec("UPDATE team_invitations SET status = $1, updated_at = now() WHERE status = $2 AND expires_at <= now()",
+		models.TeamInvitationExpired, models.TeamInvitationPending)
+	return err
+}
+
+// scanTeamInvitation считывает приглашение из строки результата и подгружает команду.
+// Строка должна содержать колонки teamInvitationColumns.
+func scanTeamInvitation(ctx context.Context, row rowScanner) (error, models.TeamInvitationView) {
+	var invitation models.TeamInvitationView
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Team.ID,
+		&invitation.Email,
+		&invitation.Phone,
+		&invitation.InviteeID,
+		&invitation.Role,
+		&invitation.Status,
+		&invitation.InvitedBy,
+		&invitation.UserID,
+		&invitation.ExpiresAt,
+		&invitation.RespondedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return err, invitation
+	}
+	invitation.StatusName = models.TeamInvitationStatusNames[invitation.Status]
+
+	errorTeam, teamView := getOneTeamById(ctx, invitation.Team.ID)
+	if errorTeam != nil {
+		log.Println("Ошибка при получении команды приглашения", invitation.Team.ID, errorTeam.Error())
+	} else {
+		invitation.Team = teamView
+	}
+
+	return nil, invitation
+}
+
+// getOneTeamInvitationById получает приглашение по его ID
+func getOneTeamInvitationById(ctx context.Context, paramId int64) (error, models.TeamInvitationView) {
+	return scanTeamInvitation(ctx, database.Conn(ctx).QueryRow("SELECT "+teamInvitationColumns+" FROM team_invitations WHERE id = $1", paramId))
+}
+
+// getTeamInvitations получает приглашения, удовлетворяющие условиям
+func getTeamInvitations(ctx context.Context, conditions sqlConditions) (error, []models.TeamInvitationView) {
+	invitations := []models.TeamInvitationView{}
+
+	rows, err := database.Conn(ctx).Query("SELECT "+teamInvitationColumns+" FROM team_invitations"+conditions.where()+" ORDER BY created_at DESC, id DESC", conditions.args...)
+	if err != nil {
+		return err, invitations
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		errScan, invitation := scanTeamInvitation(ctx, rows)
+		if errScan != nil {
+			return errScan, invitations
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return rows.Err(), invitations
+}
+
+// isInvitationAddressedTo проверяет, что приглашение адресовано пользователю.
+// Приглашение по телефону адресовано пользователю, найденному по телефону при создании,
+// приглашение по email - владельцу подтвержденного email.
+func isInvitationAddressedTo(invitation models.TeamInvitationView, auth models.UserView) bool {
+	if invitation.InviteeID != nil {
+		return *invitation.InviteeID == auth.ID
+	}
+	if auth.Status != models.UserStatusVerified {
+		return false
+	}
+	return invitation.Email != nil && strings.EqualFold(*invitation.Email, auth.Email)
+}
+
+// getInviteeIdByPhone находит пользователя, которому адресуется приглашение по телефону
+func getInviteeIdByPhone(ctx context.Context, phone string) (error, int64) {
+	var userId int64
+	err := database.Conn(ctx).QueryRow("SELECT id FROM users WHERE phone = $1 AND deleted_at IS NULL", phone).Scan(&userId)
+	return err, userId
+}
+
+// isTeamMemberByContact проверяет, состоит ли в команде пользователь с указанным email или телефоном
+func isTeamMemberByContact(ctx context.Context, teamId int64, email *string, phone *string) (error, bool) {
+	var exists bool
+	err := database.Conn(ctx).QueryRow("SELECT EXISTS (SELECT 1 FROM team_members tm JOIN users u ON u.id = tm.user_id "+
+		"WHERE tm.team_id = $1 AND u.deleted_at IS NULL AND (LOWER(u.email) = LOWER($2) OR u.phone = $3))", teamId, email, phone).Scan(&exists)
+	return err, exists
+}
+
+// validateCreateTeamInvitationRequest проверяет данные приглашения в команду
+func validateCreateTeamInvitationRequest(r *http.Request) (error, models.CreateTeamInvitationRequest) {
+	var req models.CreateTeamInvitationRequest
+	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
+		return validation, req
+	}
+	validate := validator.New()
+	if validation := validate.Struct(req); validation != nil {
+		return validation, req
+	}
+
+	if req.Email != nil && strings.TrimSpace(*req.Email) == "" {
+		req.Email = nil
+	}
+	if req.Phone != nil && strings.TrimSpace(*req.Phone) == "" {
+		req.Phone = nil
+	}
+	if req.Email == nil && req.Phone == nil {
+		return fmt.Errorf("Необходимо указать email или телефон приглашаемого"), req
+	}
+	if req.Role == "" {
+		req.Role = models.TeamRolePlayer
+	}
+
+	return nil, req
+}
+
+// Документация для метода GetTeamMembers
+// @Summary Состав команды
+// @Description Получение списка участников команды
+// @Tags Команды
+// @Param id path int true "ID команды"
+// @Produces application/json
+// @Success 200 {object} []models.TeamMemberView
+// @Failure 404 Not Found
+// @Router /api/teams/{id}/members [get]
+func GetTeamMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+		paramId, _ := strconv.Atoi(vars["id"])
+
+		errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
+		if errorResponse != nil {
+			sendDatabaseError(w, errorResponse, http.StatusNotFound, "Команда не найдена")
+			return
+		}
+
+		errMembers, members := getTeamMembers(ctx, teamView.ID)
+		if errMembers != nil {
+			log.Println("Ошибка при получении состава команды", errMembers)
+			sendDatabaseError(w, errMembers, http.StatusInternalServerError, "Ошибка при получении состава команды")
+			return
+		}
+
+		json.NewEncoder(w).Encode(members)
+	}
+}
+
+// Документация для метода InviteTeamMember
+// @Summary Приглашение в команду
+// @Description Приглашение пользователя в команду по email или телефону. Приглашать может капитан команды. Приглашение по email принимает владелец подтвержденного email, приглашение только по телефону адресуется зарегистрированному пользователю с этим телефоном
+// @Tags Команды
+// @Param id path int true "ID команды"
+// @Param createTeamInvitation body models.CreateTeamInvitationRequest true "Данные приглашения"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} models.TeamInvitationView
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/teams/{id}/members [post]
+func InviteTeamMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Команда не найдена")
+				return
+			}
+
+			if !canManageTeam(*auth, teamView) {
+				SendJSONError(w, http.StatusForbidden, "Приглашать в команду может только её капитан")
+				return
+			}
+
+			validation, invitationRequest := validateCreateTeamInvitationRequest(r)
+			if validation != nil {
+				SendJSONError(w, http.StatusBadRequest, validation.Error())
+				return
+			}
+
+			errMember, isMember := isTeamMemberByContact(ctx, teamView.ID, invitationRequest.Email, invitationRequest.Phone)
+			if errMember != nil {
+				log.Println("Ошибка при проверке состава команды", errMember)
+				sendDatabaseError(w, errMember, http.StatusInternalServerError, "Ошибка при создании приглашения")
+				return
+			}
+			if isMember {
+				SendJSONError(w, http.StatusConflict, "Пользователь уже состоит в команде")
+				return
+			}
+
+			// Телефон не подтверждается, поэтому приглашение по телефону адресуется
+			// пользователю, которому телефон принадлежит на момент приглашения
+			var inviteeId *int64
+			if invitationRequest.Email == nil {
+				errInvitee, userId := getInviteeIdByPhone(ctx, *invitationRequest.Phone)
+				if errInvitee != nil {
+					sendDatabaseError(w, errInvitee, http.StatusNotFound, "Пользователь с таким телефоном не зарегистрирован, пригласите его по email")
+					return
+				}
+				inviteeId = &userId
+			}
+
+			if err := expireTeamInvitations(ctx); err != nil {
+				log.Println("Ошибка при обновлении просроченных приглашений", err)
+			}
+
+			var invitationId int64
+			err := database.Conn(ctx).QueryRow("INSERT INTO team_invitations (team_id, email, phone, invitee_id, role, status, invited_by, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+				teamView.ID,
+				invitationRequest.Email,
+				invitationRequest.Phone,
+				inviteeId,
+				invitationRequest.Role,
+				models.TeamInvitationPending,
+				auth.ID,
+				time.Now().Add(teamInvitationTTL),
+			).Scan(&invitationId)
+			if err != nil {
+				if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+					SendJSONError(w, http.StatusConflict, "Приглашение этому пользователю уже отправлено")
+					return
+				}
+				log.Println("Ошибка при создании приглашения", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при создании приглашения")
+				return
+			}
+
+			errInvitation, invitation := getOneTeamInvitationById(ctx, invitationId)
+			if errInvitation != nil {
+				sendDatabaseError(w, errInvitation, http.StatusBadRequest, errInvitation.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(invitation)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода UpdateTeamMember
+// @Summary Изменение роли участника команды
+// @Description Изменение роли участника. Назначение капитаном передает ему управление командой
+// @Tags Команды
+// @Param id path int true "ID команды"
+// @Param user_id path int true "ID пользователя"
+// @Param updateTeamMember body models.UpdateTeamMemberRequest true "Новая роль"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} []models.TeamMemberView
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/teams/{id}/members/{user_id} [put]
+func UpdateTeamMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+			userId, _ := strconv.Atoi(vars["user_id"])
+
+			errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Команда не найдена")
+				return
+			}
+
+			if !canManageTeam(*auth, teamView) {
+				SendJSONError(w, http.StatusForbidden, "Изменять состав команды может только её капитан")
+				return
+			}
+
+			errMember, member := getTeamMember(ctx, teamView.ID, int64(userId))
+			if errMember != nil {
+				sendDatabaseError(w, errMember, http.StatusNotFound, "Пользователь не состоит в команде")
+				return
+			}
+
+			var memberRequest models.UpdateTeamMemberRequest
+			if err := json.NewDecoder(r.Body).Decode(&memberRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(memberRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if member.Role == models.TeamRoleCaptain && memberRequest.Role != models.TeamRoleCaptain {
+				SendJSONError(w, http.StatusConflict, "Чтобы сменить роль капитана, назначьте капитаном другого участника")
+				return
+			}
+
+			var err error
+			if memberRequest.Role == models.TeamRoleCaptain {
+				if member.Role != models.TeamRoleCaptain {
+					err = transferTeamCaptain(ctx, teamView.ID, member.User.ID)
+				}
+			} else {
+				_, err = database.Conn(ctx).Exec("UPDATE team_members SET role = $1, updated_at = now() WHERE id = $2", memberRequest.Role, member.ID)
+			}
+			if err != nil {
+				log.Println("Ошибка при изменении роли участника команды", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при изменении роли участника команды")
+				return
+			}
+
+			errMembers, members := getTeamMembers(ctx, teamView.ID)
+			if errMembers != nil {
+				sendDatabaseError(w, errMembers, http.StatusBadRequest, errMembers.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(members)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода DeleteTeamMember
+// @Summary Исключение из команды
+// @Description Исключение участника капитаном или выход участника из команды. Капитан не может покинуть команду, не передав управление
+// @Tags Команды
+// @Param id path int true "ID команды"
+// @Param user_id path int true "ID пользователя"
+// @Produces application/json
+// @Success 200 {object} []models.TeamMemberView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/teams/{id}/members/{user_id} [delete]
+func DeleteTeamMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+			userId, _ := strconv.Atoi(vars["user_id"])
+
+			errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Команда не найдена")
+				return
+			}
+
+			// Участник может покинуть команду сам
+			if !canManageTeam(*auth, teamView) && int64(userId) != auth.ID {
+				SendJSONError(w, http.StatusForbidden, "Исключать участников может только капитан команды")
+				return
+			}
+
+			errMember, member := getTeamMember(ctx, teamView.ID, int64(userId))
+			if errMember != nil {
+				sendDatabaseError(w, errMember, http.StatusNotFound, "Пользователь не состоит в команде")
+				return
+			}
+
+			if member.Role == models.TeamRoleCaptain {
+				SendJSONError(w, http.StatusConflict, "Капитан не может покинуть команду, сначала назначьте капитаном другого участника")
+				return
+			}
+
+			_, err := database.Conn(ctx).Exec("DELETE FROM team_members WHERE id = $1", member.ID)
+			if err != nil {
+				log.Println("Ошибка при исключении участника команды", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при исключении участника команды")
+				return
+			}
+
+			errMembers, members := getTeamMembers(ctx, teamView.ID)
+			if errMembers != nil {
+				sendDatabaseError(w, errMembers, http.StatusBadRequest, errMembers.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(members)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода GetInvitations
+// @Summary Список приглашений в команды
+// @Description Без параметров возвращает приглашения текущему пользователю: по телефону и на подтвержденный email. С параметром team_id - все приглашения команды (для капитана)
+// @Tags Приглашения
+// @Param team_id query int false "ID команды"
+// @Param status query string false "Статус приглашения (pending, accepted, declined, expired, cancelled)"
+// @Produces application/json
+// @Success 200 {object} []models.TeamInvitationView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Router /api/invitations [get]
+func GetInvitations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		queryParams := r.URL.Query()
+
+		if err := expireTeamInvitations(ctx); err != nil {
+			log.Println("Ошибка при обновлении просроченных приглашений", err)
+		}
+
+		var conditions sqlConditions
+		if value := queryParams.Get("team_id"); value != "" {
+			teamId, err := strconv.Atoi(value)
+			if err != nil {
+				SendJSONError(w, http.StatusBadRequest, "Неверное значение параметра 'team_id': "+value)
+				return
+			}
+			errorResponse, teamView := getOneTeamById(ctx, int64(teamId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Команда не найдена")
+				return
+			}
+			if !canManageTeam(*auth, teamView) {
+				SendJSONError(w, http.StatusForbidden, "Приглашения команды доступны только её капитану")
+				return
+			}
+			conditions.add("team_id = ?", teamView.ID)
+		} else {
+			// Приглашения на email видны только после его подтверждения
+			if auth.Status == models.UserStatusVerified {
+				conditions.add("(invitee_id = ? OR (invitee_id IS NULL AND LOWER(email) = LOWER(?)))", auth.ID, auth.Email)
+			} else {
+				conditions.add("invitee_id = ?", auth.ID)
+			}
+		}
+
+		if value := queryParams.Get("status"); value != "" {
+			status := 0
+			for id, name := range models.TeamInvitationStatusNames {
+				if name == value || strconv.Itoa(id) == value {
+					status = id
+				}
+			}
+			if status == 0 {
+				SendJSONError(w, http.StatusBadRequest, "Неверное значение параметра 'status': "+value)
+				return
+			}
+			conditions.add("status = ?", status)
+		}
+
+		errInvitations, invitations := getTeamInvitations(ctx, conditions)
+		if errInvitations != nil {
+			log.Println("Ошибка при получении приглашений", errInvitations)
+			sendDatabaseError(w, errInvitations, http.StatusInternalServerError, "Ошибка при получении приглашений")
+			return
+		}
+
+		json.NewEncoder(w).Encode(invitations)
+	}
+}
+
+// respondTeamInvitation сохраняет ответ пользователя на приглашение.
+// При принятии приглашения пользователь добавляется в команду.
+// Возвращает false, если приглашение уже не ожидает ответа.
+func respondTeamInvitation(ctx context.Context, invitation models.TeamInvitationView, status int, userId int64) (error, bool) {
+	responded := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		result, err := tx.Exec("UPDATE team_invitations SET status = $1, user_id = $2, responded_at = now(), updated_at = now() "+
+			"WHERE id = $3 AND status = $4 AND expires_at > now()",
+			status, userId, invitation.ID, models.TeamInvitationPending)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		if status == models.TeamInvitationAccepted {
+			_, err = tx.Exec("INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (team_id, user_id) DO NOTHING",
+				invitation.Team.ID, userId, invitation.Role)
+			if err != nil {
+				return err
+			}
+		}
+		responded = true
+		return nil
+	})
+
+	return err, err == nil && responded
+}
+
+// respondTeamInvitationHandler возвращает обработчик ответа на приглашение
+func respondTeamInvitationHandler(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			if err := expireTeamInvitations(ctx); err != nil {
+				log.Println("Ошибка при обновлении просроченных приглашений", err)
+			}
+
+			errorResponse, invitation := getOneTeamInvitationById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Приглашение не найдено")
+				return
+			}
+
+			if !isInvitationAddressedTo(invitation, *auth) {
+				SendJSONError(w, http.StatusForbidden, "Приглашение отправлено другому пользователю")
+				return
+			}
+
+			if invitation.Status != models.TeamInvitationPending {
+				SendJSONError(w, http.StatusConflict, "Приглашение уже не действительно: "+invitation.StatusName)
+				return
+			}
+
+			errRespond, responded := respondTeamInvitation(ctx, invitation, status, auth.ID)
+			if errRespond != nil {
+				log.Println("Ошибка при ответе на приглашение", errRespond)
+				sendDatabaseError(w, errRespond, http.StatusInternalServerError, "Ошибка при ответе на приглашение")
+				return
+			}
+			if !responded {
+				SendJSONError(w, http.StatusConflict, "Приглашение уже не действительно")
+				return
+			}
+
+			errorResponse, invitation = getOneTeamInvitationById(ctx, invitation.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(invitation)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода AcceptInvitation
+// @Summary Принятие приглашения в команду
+// @Description Принятие приглашения, отправленного текущему пользователю по телефону или на подтвержденный email
+// @Tags Приглашения
+// @Param id path int true "ID приглашения"
+// @Produces application/json
+// @Success 200 {object} models.TeamInvitationView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/invitations/{id}/accept [post]
+func AcceptInvitation() http.HandlerFunc {
+	return respondTeamInvitationHandler(models.TeamInvitationAccepted)
+}
+
+// Документация для метода DeclineInvitation
+// @Summary Отклонение приглашения в команду
+// @Description Отклонение приглашения, отправленного текущему пользователю по телефону или на подтвержденный email
+// @Tags Приглашения
+// @Param id path int true "ID приглашения"
+// @Produces application/json
+// @Success 200 {object} models.TeamInvitationView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/invitations/{id}/decline [post]
+func DeclineInvitation() http.HandlerFunc {
+	return respondTeamInvitationHandler(models.TeamInvitationDeclined)
+}
+
+// Документация для метода CancelInvitation
+// @Summary Отзыв приглашения в команду
+// @Description Отзыв приглашения капитаном команды
+// @Tags Приглашения
+// @Param id path int true "ID приглашения"
+// @Produces application/json
+// @Success 200 {object} models.TeamInvitationView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/invitations/{id} [delete]
+func CancelInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			vars := mux.Vars(r)
+			paramId, _ := strconv.Atoi(vars["id"])
+
+			errorResponse, invitation := getOneTeamInvitationById(ctx, int64(paramId))
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusNotFound, "Приглашение не найдено")
+				return
+			}
+
+			if !canManageTeam(*auth, invitation.Team) {
+				SendJSONError(w, http.StatusForbidden, "Отозвать приглашение может только капитан команды")
+				return
+			}
+
+			result, err := database.Conn(ctx).Exec("UPDATE team_invitations SET status = $1, updated_at = now() WHERE id = $2 AND status = $3",
+				models.TeamInvitationCancelled, invitation.ID, models.TeamInvitationPending)
+			if err != nil {
+				log.Println("Ошибка при отзыве приглашения", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при отзыве приглашения")
+				return
+			}
+			if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+				SendJSONError(w, http.StatusConflict, "Приглашение уже не ожидает ответа")
+				return
+			}
+
+			errorResponse, invitation = getOneTeamInvitationById(ctx, invitation.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(invitation)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
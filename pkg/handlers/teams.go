@@ -1,14 +1,16 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
 	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
@@ -27,136 +29,39 @@ import (
 // @Router /api/teams [get]
 func GetTeams() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := database.DB.Query("SELECT id, name, description, city, uniform_color, participant_count, responsible_id, logo, media, status, created_at  FROM teams")
+		ctx := r.Context()
+		rows, err := database.Conn(ctx).Query("SELECT " + repository.TeamViewColumns + " FROM teams WHERE deleted_at IS NULL ORDER BY id")
 		if err != nil {
 			log.Println(err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка команд")
+			return
 		}
 		defer rows.Close()
-		teams := []models.TeamView{}
+
+		var loader repository.TeamLoader
 		for rows.Next() {
-			var teamView models.TeamView
-			var responsible int
-			var logo sql.NullString
-			var media sql.NullString
-
-			if err := rows.Scan(
-				&teamView.ID,
-				&teamView.Name,
-				&teamView.Description,
-				&teamView.City,
-				&teamView.UniformColor,
-				&teamView.ParticipantCount,
-				&responsible,
-				&logo,
-				&media,
-				&teamView.Status,
-				&teamView.CreatedAt,
-			); err != nil {
+			if err := loader.Scan(rows); err != nil {
 				log.Println(err)
 			}
-			if responsible != 0 {
-				errorResponsible, responsibleUser := getUserViewById(int64(responsible))
-				if errorResponsible != nil {
-					log.Println(errorResponsible.Error())
-				} else {
-					teamView.Responsible = responsibleUser
-				}
-			}
-			if logo.Valid {
-				var logoFile models.Media
-				errorMedia, logoFile := getOneMedia(logo.String)
-				if errorMedia != nil {
-					log.Println(errorMedia.Error())
-				} else {
-					teamView.Logo = &logoFile
-				}
-			}
-			if media.Valid && len(media.String) > 0 {
-				var mediaList []models.Media
-				var mediaFiles []string
-				err := json.Unmarshal([]byte(media.String), &mediaFiles)
-				if err != nil {
-					log.Println("Ошибка при парсинге JSON:", err)
-				}
-				for _, mediaFile := range mediaFiles {
-					errorMedia, mediaFile := getOneMedia(mediaFile)
-					if errorMedia != nil {
-						log.Println(errorMedia.Error())
-					} else {
-						mediaList = append(mediaList, mediaFile)
-					}
-				}
-				teamView.Media = &mediaList
-			}
-			teams = append(teams, teamView)
 		}
 		if err := rows.Err(); err != nil {
 			log.Println(err)
 		}
 
+		errLoad, teams := loader.Load(ctx)
+		if errLoad != nil {
+			log.Println(errLoad)
+			sendDatabaseError(w, errLoad, http.StatusInternalServerError, "Ошибка при получении списка команд")
+			return
+		}
+
 		json.NewEncoder(w).Encode(teams)
 	}
 }
 
-func getOneTeamById(paramId int64) (error, models.TeamView) {
-	var teamView models.TeamView
-	var responsible int
-	var logo sql.NullString
-	var media sql.NullString
-
-	err := database.DB.QueryRow("SELECT id, name, description, city, uniform_color, participant_count, responsible_id, logo, media, status, created_at FROM teams WHERE id = $1", int64(paramId)).Scan(
-		&teamView.ID,
-		&teamView.Name,
-		&teamView.Description,
-		&teamView.City,
-		&teamView.UniformColor,
-		&teamView.ParticipantCount,
-		&responsible,
-		&logo,
-		&media,
-		&teamView.Status,
-		&teamView.CreatedAt,
-	)
-	if err != nil {
-		return err, teamView
-	}
-
-	if responsible != 0 {
-		errorResponsible, responsibleUser := getUserViewById(int64(responsible))
-		if errorResponsible != nil {
-			log.Println(errorResponsible.Error())
-		} else {
-			teamView.Responsible = responsibleUser
-		}
-	}
-	if logo.Valid {
-		var logoFile models.Media
-		errorMedia, logoFile := getOneMedia(logo.String)
-		if errorMedia != nil {
-			log.Println(errorMedia.Error())
-		} else {
-			teamView.Logo = &logoFile
-		}
-	}
-	if media.Valid && len(media.String) > 0 {
-		var mediaList []models.Media
-		var mediaFiles []string
-		err := json.Unmarshal([]byte(media.String), &mediaFiles)
-		if err != nil {
-			log.Println("Ошибка при парсинге JSON:", err)
-		}
-		for _, mediaFile := range mediaFiles {
-			errorMedia, mediaFile := getOneMedia(mediaFile)
-			if errorMedia != nil {
-				log.Println(errorMedia.Error())
-			} else {
-				mediaList = append(mediaList, mediaFile)
-			}
-		}
-		teamView.Media = &mediaList
-	}
-
-	return err, teamView
+// getOneTeamById получает команду по ID. Удаленные команды не возвращаются.
+func getOneTeamById(ctx context.Context, paramId int64) (error, models.TeamView) {
+	return repository.TeamViewById(ctx, paramId)
 }
 
 // Документация для метода GetTeam
@@ -170,12 +75,13 @@ func getOneTeamById(paramId int64) (error, models.TeamView) {
 // @Router /api/teams/{id} [get]
 func GetTeam() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		vars := mux.Vars(r)
 		paramId, _ := strconv.Atoi(vars["id"])
 
-		errorResponse, teamView := getOneTeamById(int64(paramId))
+		errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 			return
 		}
 
@@ -184,6 +90,7 @@ func GetTeam() http.HandlerFunc {
 }
 
 func validateCreateTeamRequest(r *http.Request) (error, models.CreateTeamRequest) {
+	ctx := r.Context()
 	var req models.CreateTeamRequest
 	if validation := json.NewDecoder(r.Body).Decode(&req); validation != nil {
 		return validation, req
@@ -195,7 +102,7 @@ func validateCreateTeamRequest(r *http.Request) (error, models.CreateTeamRequest
 
 	// Check for uniqueness of name and city
 	var count int
-	err := database.DB.QueryRow("SELECT COUNT(*) FROM teams WHERE name = $1 AND city = $2", req.Name, req.City).Scan(&count)
+	err := database.Conn(ctx).QueryRow("SELECT COUNT(*) FROM teams WHERE name = $1 AND city = $2 AND deleted_at IS NULL", req.Name, req.City).Scan(&count)
 	if err != nil {
 		return err, req
 	}
@@ -220,6 +127,25 @@ func validateUpdatedAtTeamRequest(r *http.Request) (error, models.UpdateTeamRequ
 	return nil, req
 }
 
+// createTeam сохраняет команду, создатель становится её капитаном
+func createTeam(ctx context.Context, team *models.Team, userId int64) error {
+	return database.WithTx(ctx, func(tx *database.Tx) error {
+		err := tx.QueryRow("INSERT INTO teams (name, description, city, uniform_color, responsible_id) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			team.Name,
+			team.Description,
+			team.City,
+			team.UniformColor,
+			userId,
+		).Scan(&team.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)", team.ID, userId, models.TeamRoleCaptain)
+		return err
+	})
+}
+
 // Документация для метода CreateTeam
 // @Summary Создание новой команды
 // @Description Создание новой команды
@@ -232,6 +158,9 @@ func validateUpdatedAtTeamRequest(r *http.Request) (error, models.UpdateTeamRequ
 // @Router /api/teams [post]
 func CreateTeam() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -255,23 +184,16 @@ func CreateTeam() http.HandlerFunc {
 			team.Description = teamRequest.Description
 			team.City = teamRequest.City
 			team.UniformColor = teamRequest.UniformColor
-			team.ParticipantCount = teamRequest.ParticipantCount
-
-			err := database.DB.QueryRow("INSERT INTO teams (name, description, city, uniform_color, participant_count, responsible_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-				team.Name,
-				team.Description,
-				team.City,
-				team.UniformColor,
-				team.ParticipantCount,
-				AUTH.ID,
-			).Scan(&team.ID)
-			if err != nil {
-				log.Println(err)
+
+			if err := createTeam(ctx, &team, auth.ID); err != nil {
+				log.Println("Ошибка при создании команды", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при создании команды")
+				return
 			}
 
-			errTeam, teamView := getOneTeamById(int64(team.ID))
+			errTeam, teamView := getOneTeamById(ctx, int64(team.ID))
 			if errTeam != nil {
-				SendJSONError(w, http.StatusBadRequest, errTeam.Error())
+				sendDatabaseError(w, errTeam, http.StatusBadRequest, errTeam.Error())
 				return
 			}
 			json.NewEncoder(w).Encode(teamView)
@@ -294,10 +216,15 @@ func CreateTeam() http.HandlerFunc {
 // @Param id path int true "ID команды"
 // @Success 204 No Content
 // @Failure 422 Unprocessable Entity
+// @Failure 415 Unsupported Media Type
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 Not Found
 // @Router /api/teams/{id} [put]
 func UpdateTeam() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -326,23 +253,43 @@ func UpdateTeam() http.HandlerFunc {
 			paramId, _ := strconv.Atoi(vars["id"])
 			team.ID = int64(paramId)
 
-			_, errUpdate := database.DB.Exec("UPDATE teams SET name = $1, description = $2, city = $3, logo = $4, media = $5 WHERE id = $6 and responsible_id = $7",
-				team.Name,
-				team.Description,
-				team.City,
-				team.Logo,
-				team.Media,
-				paramId,
-				AUTH.ID)
+			errorTeam, currentTeam := getOneTeamById(ctx, team.ID)
+			if errorTeam != nil {
+				sendDatabaseError(w, errorTeam, http.StatusNotFound, "Команда не найдена")
+				return
+			}
+			if !Can(*auth, models.PermissionTeamsUpdate, currentTeam.Responsible.ID) {
+				SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на редактирование этой команды")
+				return
+			}
+
+			if errMedia, code := validateMediaRefs(ctx, *auth, models.MediaEntityTeam, currentTeam.ID, teamRequest.Logo, teamRequest.Media); errMedia != nil {
+				sendDatabaseError(w, errMedia, code, errMedia.Error())
+				return
+			}
+
+			errUpdate := database.WithTx(ctx, func(tx *database.Tx) error {
+				_, err := tx.Exec("UPDATE teams SET name = $1, description = $2, city = $3, logo = $4, media = $5 WHERE id = $6",
+					team.Name,
+					team.Description,
+					team.City,
+					team.Logo,
+					team.Media,
+					paramId)
+				if err != nil {
+					return err
+				}
+				return syncMediaAttachments(tx, models.MediaEntityTeam, team.ID, team.Logo, team.Media)
+			})
 			if errUpdate != nil {
 				log.Println(errUpdate)
-				SendJSONError(w, http.StatusBadRequest, errUpdate.Error())
-
+				sendDatabaseError(w, errUpdate, http.StatusBadRequest, errUpdate.Error())
+				return
 			}
 
-			errorResponse, teamView := getOneTeamById(int64(paramId))
+			errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
 			if errorResponse != nil {
-				SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 				return
 			}
 			json.NewEncoder(w).Encode(teamView)
@@ -357,7 +304,7 @@ func UpdateTeam() http.HandlerFunc {
 
 // Документация для метода DeleteTeam
 // @Summary Удаляет команду по ID
-// @Description Удаление команды по идентификатору
+// @Description Мягкое удаление команды по идентификатору. Ожидающие приглашения в команду отзываются
 // @Tags Команды
 // @Param id path int true "ID команды"
 // @Success 204 No Content
@@ -365,6 +312,9 @@ func UpdateTeam() http.HandlerFunc {
 // @Router /api/teams/{id} [delete]
 func DeleteTeam() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -379,14 +329,18 @@ func DeleteTeam() http.HandlerFunc {
 		if r.Method == http.MethodDelete {
 			vars := mux.Vars(r)
 			paramId, _ := strconv.Atoi(vars["id"])
-			errorResponse, teamView := getOneTeamById(int64(paramId))
+			errorResponse, teamView := getOneTeamById(ctx, int64(paramId))
 			if errorResponse != nil {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			} else {
-				_, err := database.DB.Exec("DELETE FROM teams WHERE id = $1 and responsible_id = $2", teamView.ID, AUTH.ID)
-				if err != nil {
-					w.WriteHeader(http.StatusNotFound)
+				if !Can(*auth, models.PermissionTeamsDelete, teamView.Responsible.ID) {
+					SendJSONError(w, http.StatusForbidden, "Пользователь не имеет прав на удаление этой команды")
+					return
+				}
+				if err := deleteTeam(ctx, teamView.ID); err != nil {
+					log.Println("Ошибка при удалении команды", err)
+					sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при удалении команды")
 					return
 				}
 
@@ -400,3 +354,37 @@ func DeleteTeam() http.HandlerFunc {
 		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
 	}
 }
+
+// deleteTeam мягко удаляет команду и отзывает ожидающие приглашения в неё
+func deleteTeam(ctx context.Context, teamId int64) error {
+	return database.WithTx(ctx, func(tx *database.Tx) error {
+		if err, _ := softDeleteRow(tx, "teams", teamId, time.Now()); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE team_invitations SET status = $1, updated_at = now() WHERE team_id = $2 AND status = $3",
+			models.TeamInvitationCancelled, teamId, models.TeamInvitationPending)
+		return err
+	})
+}
+
+// Документация для метода RestoreTeam
+// @Summary Восстановление удаленной команды
+// @Description Восстановление команды, удаленной мягким удалением. Отозванные при удалении приглашения не восстанавливаются
+// @Tags Команды
+// @Param id path int true "ID команды"
+// @Produces application/json
+// @Success 200 {object} models.TeamView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/teams/{id}/restore [post]
+func RestoreTeam() http.HandlerFunc {
+	return restoreHandler(
+		deletedIdFromPath,
+		restoreById("teams"),
+		func(ctx context.Context, id int64) (error, interface{}) {
+			return getOneTeamById(ctx, id)
+		},
+	)
+}
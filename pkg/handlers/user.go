@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"goland_api/pkg/database"
 	"goland_api/pkg/models"
+	"goland_api/pkg/repository"
+	"goland_api/pkg/services/loginguard"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator"
@@ -19,55 +25,125 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// parseUserSearchFilter разбирает параметры фильтрации списка пользователей
+func parseUserSearchFilter(queryParams url.Values) (error, models.UserSearchFilter, sqlConditions) {
+	var filter models.UserSearchFilter
+	var conditions sqlConditions
+
+	conditions.add("u.deleted_at IS NULL")
+
+	if search := strings.TrimSpace(queryParams.Get("search")); search != "" {
+		filter.Search = &search
+		pattern := containsPattern(search)
+		conditions.add("(u.name ILIKE ? ESCAPE '\\' OR u.email ILIKE ? ESCAPE '\\' OR u.phone ILIKE ? ESCAPE '\\' OR u.city ILIKE ? ESCAPE '\\')", pattern, pattern, pattern, pattern)
+	}
+
+	if status := queryParams.Get("status"); status != "" {
+		value, err := strconv.Atoi(status)
+		if err != nil {
+			return fmt.Errorf("Неверное значение параметра 'status'"), filter, conditions
+		}
+		filter.Status = &value
+		conditions.add("u.status = ?", value)
+	}
+
+	if roleId := queryParams.Get("role_id"); roleId != "" {
+		value, err := strconv.Atoi(roleId)
+		if err != nil {
+			return fmt.Errorf("Неверное значение параметра 'role_id'"), filter, conditions
+		}
+		filter.RoleID = &value
+		conditions.add("u.role_id = ?", value)
+	}
+
+	return nil, filter, conditions
+}
+
 // Документация для метода GetUsers
-// @Summary Возвращает список всех пользователей
-// @Description Получение списка всех пользователей
+// @Summary Возвращает список пользователей
+// @Description Получение списка пользователей с пагинацией и поиском по ФИО, email, телефону и городу
 // @Tags Пользователи
 // @Accept  application/json
 // @Produce  application/json
-// @Success 200 {object} []models.User
+// @Param page query int false "Номер страницы"
+// @Param per_page query int false "Количество элементов на странице"
+// @Param search query string false "Поиск по ФИО, email, телефону и городу"
+// @Param status query int false "Статус: 0 - не подтвержден, 1 - подтвержден, 2 - заблокирован"
+// @Param role_id query int false "Роль"
+// @Success 200 {object} models.PaginationResponse{data=[]models.UserView}
 // @Failure 400 Bad Request
 // @Failure 500 Internal Server Error
+// @Security BearerAuth
 // @Router /api/users [get]
 func GetUsers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := database.DB.Query("SELECT id, name, email, phone, city, logo, media, status, created_at FROM users")
+		ctx := r.Context()
+		queryParams := r.URL.Query()
+		page, perPage := getPagination(queryParams)
+		offset := (page - 1) * perPage
+
+		errFilter, filter, conditions := parseUserSearchFilter(queryParams)
+		if errFilter != nil {
+			SendJSONError(w, http.StatusBadRequest, errFilter.Error())
+			return
+		}
+
+		var totalCount int
+		err := database.Conn(ctx).QueryRow("SELECT COUNT(u.id) FROM users u"+conditions.where(), conditions.args...).Scan(&totalCount)
+		if err != nil {
+			log.Println("Ошибка в SQL запросе GetUsers", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка пользователей")
+			return
+		}
+		pages := int(math.Ceil(float64(totalCount) / float64(perPage)))
+
+		query := "SELECT " + repository.UserViewColumns + " FROM users u join roles r on r.id = u.role_id" + conditions.where() +
+			" ORDER BY u.id LIMIT " + conditions.arg(perPage) + " OFFSET " + conditions.arg(offset)
+		rows, err := database.Conn(ctx).Query(query, conditions.args...)
 		if err != nil {
-			log.Println(err)
+			log.Println("Ошибка в SQL запросе GetUsers", err)
+			sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при получении списка пользователей")
+			return
 		}
 		defer rows.Close()
 
-		users := []models.UserView{}
+		users := []interface{}{}
 		for rows.Next() {
-			var user models.UserView
-			if err := rows.Scan(
-				&user.ID,
-				&user.Name,
-				&user.Email,
-				&user.Phone,
-				&user.City,
-				&user.Logo,
-				&user.Media,
-				&user.Status,
-				&user.CreatedAt); err != nil {
-				log.Println(err)
+			errScan, userView := repository.ScanUserView(rows)
+			if errScan != nil {
+				log.Println("Ошибка в Scan", errScan)
+				continue
 			}
-			users = append(users, user)
+			users = append(users, userView)
 		}
 		if err := rows.Err(); err != nil {
-			log.Println(err)
+			log.Println("Ошибка в Row Next", err)
 		}
 
-		json.NewEncoder(w).Encode(users)
+		response := models.PaginationResponse{
+			Pagination: models.Pagination{
+				Page:       page,
+				PerPage:    perPage,
+				TotalPages: pages,
+				TotalItems: totalCount,
+			},
+			Filter: filter,
+			Data:   users,
+		}
+
+		json.NewEncoder(w).Encode(response)
 	}
 }
 
-func getUserFromToken(token *jwt.Token) (error, *models.UserView) {
+func getUserFromToken(ctx context.Context, token *jwt.Token) (error, *models.UserView) {
 	// Извлекаем claims
 	if claims, ok := token.Claims.(*models.Claims); ok && token.Valid {
-		// Получаем значение jti
-		userEmail := claims.Id // jti хранится в поле Id структуры Claims
-		errorResponse, userView := getUserViewByEmail(userEmail)
+		// ID пользователя хранится в subject
+		userId, err := strconv.ParseInt(claims.Subject, 10, 64)
+		if err != nil {
+			return err, nil
+		}
+		errorResponse, userView := getUserViewById(ctx, userId)
 		if errorResponse != nil {
 			return errorResponse, nil
 		}
@@ -78,73 +154,36 @@ func getUserFromToken(token *jwt.Token) (error, *models.UserView) {
 	return fmt.Errorf("Не смог прочитать токен"), nil
 }
 
-func getUserViewById(paramId int64) (error, models.UserView) {
-	var userView models.UserView
-	var role models.Role
-
-	err := database.DB.QueryRow(
-		"SELECT "+
-			"u.id, u.name, u.email, u.phone, u.city, u.logo, u.media, u.status, u.created_at, "+
-			"r.id, r.name "+
-			"FROM users u "+
+// getUserViewById получает пользователя по ID. Удаленные пользователи не возвращаются.
+func getUserViewById(ctx context.Context, paramId int64) (error, models.UserView) {
+	return repository.ScanUserView(database.Conn(ctx).QueryRow(
+		"SELECT "+repository.UserViewColumns+" FROM users u "+
 			"join roles r on r.id = u.role_id "+
-			"WHERE u.id = $1", paramId).Scan(
-		&userView.ID,
-		&userView.Name,
-		&userView.Email,
-		&userView.Phone,
-		&userView.City,
-		&userView.Logo,
-		&userView.Media,
-		&userView.Status,
-		&userView.CreatedAt,
-		&role.ID,
-		&role.Name,
-	)
-	userView.Role = role
-
-	return err, userView
+			"WHERE u.id = $1 AND u.deleted_at IS NULL", paramId))
 }
 
-func getUserViewByEmail(paramEmail string) (error, models.UserView) {
-	var userView models.UserView
-	var role models.Role
-
-	err := database.DB.QueryRow(
-		"SELECT "+
-			"u.id, u.name, u.email, u.phone, u.city, u.logo, u.media, u.status, u.created_at, "+
-			"r.id, r.name "+
-			"FROM users u "+
+// getUserViewByEmail получает пользователя по email. Удаленные пользователи не возвращаются.
+func getUserViewByEmail(ctx context.Context, paramEmail string) (error, models.UserView) {
+	return repository.ScanUserView(database.Conn(ctx).QueryRow(
+		"SELECT "+repository.UserViewColumns+" FROM users u "+
 			"join roles r on r.id = u.role_id "+
-			"WHERE email = $1", paramEmail).Scan(
-		&userView.ID,
-		&userView.Name,
-		&userView.Email,
-		&userView.Phone,
-		&userView.City,
-		&userView.Logo,
-		&userView.Media,
-		&userView.Status,
-		&userView.CreatedAt,
-		&role.ID,
-		&role.Name,
-	)
-	userView.Role = role
-
-	return err, userView
+			"WHERE u.email = $1 AND u.deleted_at IS NULL", paramEmail))
 }
 
-func getUserViewByIdByEmail(paramEmail string) (error, models.CreateUserRequest) {
+// getUserViewByIdByEmail получает учетные данные и статус пользователя по email для входа
+func getUserViewByIdByEmail(ctx context.Context, paramEmail string) (error, models.CreateUserRequest, int) {
 	var user models.CreateUserRequest
-	err := database.DB.QueryRow("SELECT id, name, email, phone, password FROM users WHERE email = $1", paramEmail).Scan(
+	var status int
+	err := database.Conn(ctx).QueryRow("SELECT id, name, email, phone, password, status FROM users WHERE email = $1 AND deleted_at IS NULL", paramEmail).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.Phone,
 		&user.Password,
+		&status,
 	)
 
-	return err, user
+	return err, user, status
 }
 
 // Документация для метода GetUser
@@ -158,12 +197,13 @@ func getUserViewByIdByEmail(paramEmail string) (error, models.CreateUserRequest)
 // @Router /api/users/{id} [get]
 func GetUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		vars := mux.Vars(r)
 		paramId, _ := strconv.Atoi(vars["id"])
 
-		errorResponse, userView := getUserViewById(int64(paramId))
+		errorResponse, userView := getUserViewById(ctx, int64(paramId))
 		if errorResponse != nil {
-			SendJSONError(w, http.StatusBadRequest, errorResponse.Error())
+			sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
 			return
 		}
 
@@ -185,6 +225,8 @@ func GetUser() http.HandlerFunc {
 // @Router /api/auth/info [get]
 func InfoUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -196,22 +238,24 @@ func InfoUser() http.HandlerFunc {
 			return
 		}
 
-		json.NewEncoder(w).Encode(AUTH)
+		json.NewEncoder(w).Encode(auth)
 	}
 }
 
 // @Summary Аутентификация пользователя
-// @Description Аутентификация пользователя по email и паролю с возвратом JWT токена
+// @Description Аутентификация пользователя по email и паролю. Открывает новую сессию и возвращает пару токенов
 // @Tags Аутентификация
 // @Param credentials body models.LoginUserRequest true "Учетные данные пользователя"
 // @Accept  application/json
 // @Produce  application/json
-// @Success 200 {string} string "JWT токен"
+// @Success 200 {object} models.TokenPairResponse
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Too Many Requests"
 // @Router /api/auth/login [post]
 func Login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -230,25 +274,69 @@ func Login() http.HandlerFunc {
 				return
 			}
 
-			errorQuery, user := getUserViewByIdByEmail(userRequest.Email)
-			if errorQuery != nil {
-				SendJSONError(w, http.StatusBadRequest, errorQuery.Error())
+			// Попытка учитывается до проверки пароля, поэтому параллельные запросы не обходят задержку.
+			// Пока действует задержка после неудачных попыток, пароль не проверяется.
+			ip := getClientIP(r)
+			attempt, errGuard := loginguard.Default.Reserve(ctx, userRequest.Email, ip, time.Now())
+			if errGuard != nil {
+				log.Println("Ошибка при учете попытки входа", errGuard)
+				sendDatabaseError(w, errGuard, http.StatusInternalServerError, "Ошибка при входе")
+				return
+			}
+			if attempt.RetryAfter > 0 {
+				sendTooManyLoginAttempts(w, attempt.RetryAfter)
+				return
+			}
+
+			errorQuery, user, userStatus := getUserViewByIdByEmail(ctx, userRequest.Email)
+			if errorQuery != nil && errorQuery != sql.ErrNoRows {
+				log.Println("Ошибка при поиске пользователя", errorQuery)
+				if err := loginguard.Default.Release(ctx, userRequest.Email, ip); err != nil {
+					log.Println("Ошибка при отмене попытки входа", err)
+				}
+				sendDatabaseError(w, errorQuery, http.StatusInternalServerError, "Ошибка при входе")
+				return
+			}
+
+			// Для неизвестного email пароль сверяется с фиктивным хешем,
+			// чтобы по ответу и времени ответа нельзя было узнать, зарегистрирован ли email
+			passwordHash := user.Password
+			if errorQuery == sql.ErrNoRows {
+				passwordHash = dummyPasswordHash
+			}
+			if !checkPasswordHash(userRequest.Password, passwordHash) || errorQuery == sql.ErrNoRows {
+				if attempt.Locked {
+					var userId *int64
+					if errorQuery == nil {
+						userId = &user.ID
+					}
+					writeAuditLog(r, userId, models.AuditEventLoginLocked, map[string]interface{}{
+						"email":    userRequest.Email,
+						"failures": attempt.Failures,
+						"until":    attempt.LockedUntil,
+					})
+				}
+				SendJSONError(w, http.StatusUnauthorized, "Неверный email или пароль")
 				return
 			}
 
-			checkPassword := checkPasswordHash(userRequest.Password, user.Password)
+			if err := loginguard.Default.Succeed(ctx, userRequest.Email, ip); err != nil {
+				log.Println("Ошибка при сбросе попыток входа", err)
+			}
 
-			if checkPassword != true {
-				SendJSONError(w, http.StatusBadRequest, "Invalid password")
+			if userStatus == models.UserStatusBlocked {
+				SendJSONError(w, http.StatusForbidden, "Пользователь заблокирован")
 				return
 			}
 
-			tokenString, errorToken := getNewToken(user.Name, user.Email)
+			errorToken, tokenPair := startSession(database.Conn(ctx), r, user.ID, user.Name)
 			if errorToken != nil {
-				SendJSONError(w, http.StatusBadRequest, errorToken.Error())
+				log.Println("Ошибка при создании сессии", errorToken)
+				sendDatabaseError(w, errorToken, http.StatusInternalServerError, "Ошибка при создании сессии")
+				return
 			}
 
-			json.NewEncoder(w).Encode(tokenString)
+			json.NewEncoder(w).Encode(tokenPair)
 			return
 		}
 
@@ -258,42 +346,21 @@ func Login() http.HandlerFunc {
 	}
 }
 
-// @Summary Обновление JWT токена
-// @Description Обновление JWT токена для аутентифицированного пользователя
-// @Tags Аутентификация
-// @Produce  application/json
-// @Success 200 {string} string "Новый JWT токен"
-// @Failure 400 {object} models.ErrorResponse "Bad Request"
-// @Failure 401 {object} models.ErrorResponse "Unauthorized"
-// @Security BearerAuth
-// @Router /api/auth/refresh [post]
-func Refresh() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodOptions {
-			// Устанавливаем заголовки для CORS
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			// Отправляем успешный ответ
-			w.WriteHeader(http.StatusOK)
-			return
+// registerUser сохраняет нового пользователя и открывает для него сессию одной транзакцией
+func registerUser(r *http.Request, user *models.CreateUserRequest) (error, models.TokenPairResponse) {
+	var tokenPair models.TokenPairResponse
+	err := database.WithTx(r.Context(), func(tx *database.Tx) error {
+		err := tx.QueryRow("INSERT INTO users (name, email, phone, password, status) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			user.Name, user.Email, user.Phone, user.Password, models.UserStatusUnverified).Scan(&user.ID)
+		if err != nil {
+			return err
 		}
 
-		if r.Method == http.MethodPost {
-			tokenString, errorToken := getNewToken(AUTH.Name, AUTH.Email)
-			if errorToken != nil {
-				SendJSONError(w, http.StatusBadRequest, errorToken.Error())
-			}
-
-			json.NewEncoder(w).Encode(tokenString)
-			return
-		}
+		err, tokenPair = startSession(tx, r, user.ID, user.Name)
+		return err
+	})
 
-		// Если метод не поддерживается
-		w.Header().Set("Allow", "POST, OPTIONS")
-		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
-	}
+	return err, tokenPair
 }
 
 // Документация для метода CreateUser
@@ -303,11 +370,12 @@ func Refresh() http.HandlerFunc {
 // @Param createUser body models.CreateUserRequest true "Данные для создания пользователя"
 // @Consumes application/json
 // @Produces application/json
-// @Success 201 {object} string
+// @Success 201 {object} models.TokenPairResponse
 // @Failure 422 Unprocessable Entity
 // @Router /api/auth [post]
 func CreateUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -358,19 +426,19 @@ func CreateUser() http.HandlerFunc {
 			user.Phone = userRequest.Phone
 			user.Password = getHashPassword(userRequest.Password)
 
-			err := database.DB.QueryRow("INSERT INTO users (name, email, phone, password) VALUES ($1, $2, $3, $4) RETURNING id", user.Name, user.Email, user.Phone, user.Password).Scan(&user.ID)
-			if err != nil {
-				SendJSONError(w, http.StatusBadRequest, "Возникла ошибка при регистрации")
+			errRegister, tokenPair := registerUser(r, &user)
+			if errRegister != nil {
+				log.Println("Ошибка при регистрации", errRegister)
+				sendDatabaseError(w, errRegister, http.StatusBadRequest, "Возникла ошибка при регистрации")
 				return
 			}
 
-			tokenString, errorToken := getNewToken(user.Name, user.Email)
-			if errorToken != nil {
-				SendJSONError(w, http.StatusBadRequest, "Возникла ошибка при регистрации")
-				return
+			// Письмо можно запросить повторно, поэтому ошибка отправки не прерывает регистрацию
+			if err := sendEmailVerification(ctx, user.ID, user.Name, user.Email); err != nil {
+				log.Println("Ошибка при отправке письма для подтверждения email", err)
 			}
 
-			json.NewEncoder(w).Encode(tokenString)
+			json.NewEncoder(w).Encode(tokenPair)
 			return
 		}
 
@@ -394,6 +462,9 @@ func CreateUser() http.HandlerFunc {
 // @Router /api/users [put]
 func UpdateUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := getAuthUser(r)
+
 		if r.Method == http.MethodOptions {
 			// Устанавливаем заголовки для CORS
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -438,23 +509,33 @@ func UpdateUser() http.HandlerFunc {
 			}
 
 			if userRequest != nil {
-				AUTH.Name = userRequest.Name
-				AUTH.Email = userRequest.Email
-				AUTH.Phone = userRequest.Phone
+				// Новый email требует повторного подтверждения
+				emailChanged := auth.Email != userRequest.Email
+				if emailChanged {
+					auth.Status = models.UserStatusUnverified
+				}
+				auth.Name = userRequest.Name
+				auth.Email = userRequest.Email
+				auth.Phone = userRequest.Phone
 				userPassword := getHashPassword(userRequest.Password)
 
-				_, err := database.DB.Exec("UPDATE users SET name = $1, email = $2, phone = $3, password = $4 WHERE id = $5",
-					AUTH.Name,
-					AUTH.Email,
-					AUTH.Phone,
+				_, err := database.Conn(ctx).Exec("UPDATE users SET name = $1, email = $2, phone = $3, password = $4, status = $5 WHERE id = $6",
+					auth.Name,
+					auth.Email,
+					auth.Phone,
 					userPassword,
+					auth.Status,
 					userRequest.ID)
 
 				if err != nil {
 					log.Println(err)
+				} else if emailChanged {
+					if err := sendEmailVerification(ctx, auth.ID, auth.Name, auth.Email); err != nil {
+						log.Println("Ошибка при отправке письма для подтверждения email", err)
+					}
 				}
 
-				json.NewEncoder(w).Encode(AUTH)
+				json.NewEncoder(w).Encode(auth)
 				return
 			}
 
@@ -468,42 +549,11 @@ func UpdateUser() http.HandlerFunc {
 	}
 }
 
-// Документация для метода DeleteUser
-// @Summary Удаляет пользователя по ID
-// @Description Удаление пользователя по идентификатору
-// @Tags Пользователи
-// @Param id path int true "ID пользователя"
-// @Success 204 No Content
-// @Failure 404 Not Found
-// @Router /api/users/{id} [delete]
-func DeleteUser() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		paramId, _ := strconv.Atoi(vars["id"])
-
-		var user models.User
-		err := database.DB.QueryRow("SELECT * FROM users WHERE id = $1", paramId).Scan(&user.ID, &user.Name, &user.Email, &user.Phone, &user.Status, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
-		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		} else {
-			_, err := database.DB.Exec("DELETE FROM users WHERE id = $1", paramId)
-			if err != nil {
-				//todo : fix error handling
-				w.WriteHeader(http.StatusNotFound)
-				return
-			}
-
-			json.NewEncoder(w).Encode("User deleted")
-		}
-	}
-}
-
-func isUniqueEmail(fl validator.FieldLevel) bool {
+func isUniqueEmail(ctx context.Context, fl validator.FieldLevel) bool {
 	email := fl.Field().String()
 
 	var checkUser models.CreateUserRequest
-	err := database.DB.QueryRow("SELECT id, name, email, phone, password FROM users WHERE email = $1", email).Scan(
+	err := database.Conn(ctx).QueryRow("SELECT id, name, email, phone, password FROM users WHERE email = $1 AND deleted_at IS NULL", email).Scan(
 		&checkUser.ID,
 		&checkUser.Name,
 		&checkUser.Email,
@@ -516,11 +566,11 @@ func isUniqueEmail(fl validator.FieldLevel) bool {
 	return false
 }
 
-func isUniquePhone(fl validator.FieldLevel) bool {
+func isUniquePhone(ctx context.Context, fl validator.FieldLevel) bool {
 	phone := fl.Field().String()
 
 	var checkUser models.CreateUserRequest
-	err := database.DB.QueryRow("SELECT id, name, email, phone, password FROM users WHERE phone = $1", phone).Scan(
+	err := database.Conn(ctx).QueryRow("SELECT id, name, email, phone, password FROM users WHERE phone = $1 AND deleted_at IS NULL", phone).Scan(
 		&checkUser.ID,
 		&checkUser.Name,
 		&checkUser.Email,
@@ -545,9 +595,9 @@ func validateCreateUserRequest(r *http.Request) (error, models.CreateUserRequest
 	userRequest.ID = int64(paramId)
 
 	validate := validator.New()
-	validate.RegisterValidation("email", isUniqueEmail)
-	validate.RegisterValidation("phone", isUniquePhone)
-	errValidate := validate.Struct(userRequest)
+	validate.RegisterValidationCtx("email", isUniqueEmail)
+	validate.RegisterValidationCtx("phone", isUniquePhone)
+	errValidate := validate.StructCtx(r.Context(), userRequest)
 	if errValidate != nil {
 		// Если есть ошибки валидации, выводим их
 		//for _, errValidate := range errValidate.(validator.ValidationErrors) {
@@ -561,11 +611,11 @@ func validateCreateUserRequest(r *http.Request) (error, models.CreateUserRequest
 }
 
 // isUniqueEmailFactory создает функцию isUniqueEmail с захваченной переменной
-func isUniqueEmailFactory(userRequest models.UpdateUserRequest) validator.Func {
-	return func(fl validator.FieldLevel) bool {
+func isUniqueEmailFactory(userId int64) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
 		email := fl.Field().String()
 		var checkUser models.CreateUserRequest
-		err := database.DB.QueryRow("SELECT id, name, email, phone, password FROM users WHERE email = $1 AND id <> $2", email, userRequest.ID).Scan(
+		err := database.Conn(ctx).QueryRow("SELECT id, name, email, phone, password FROM users WHERE email = $1 AND id <> $2 AND deleted_at IS NULL", email, userId).Scan(
 			&checkUser.ID,
 			&checkUser.Name,
 			&checkUser.Email,
@@ -580,11 +630,11 @@ func isUniqueEmailFactory(userRequest models.UpdateUserRequest) validator.Func {
 }
 
 // isUniquePhoneFactory создает функцию isUniqueEmail с захваченной переменной
-func isUniquePhoneFactory(userRequest models.UpdateUserRequest) validator.Func {
-	return func(fl validator.FieldLevel) bool {
+func isUniquePhoneFactory(userId int64) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
 		phone := fl.Field().String()
 		var checkUser models.CreateUserRequest
-		err := database.DB.QueryRow("SELECT id, name, email, phone, password FROM users WHERE phone = $1 AND id <> $2", phone, userRequest.ID).Scan(
+		err := database.Conn(ctx).QueryRow("SELECT id, name, email, phone, password FROM users WHERE phone = $1 AND id <> $2 AND deleted_at IS NULL", phone, userId).Scan(
 			&checkUser.ID,
 			&checkUser.Name,
 			&checkUser.Email,
@@ -599,6 +649,8 @@ func isUniquePhoneFactory(userRequest models.UpdateUserRequest) validator.Func {
 }
 
 func validateUpdatedAtUserRequest(r *http.Request) (error, *models.UpdateUserRequest) {
+	auth := getAuthUser(r)
+
 	var userRequest models.UpdateUserRequest
 	// Парсим JSON из тела запроса
 	if errJson := json.NewDecoder(r.Body).Decode(&userRequest); errJson != nil {
@@ -606,13 +658,13 @@ func validateUpdatedAtUserRequest(r *http.Request) (error, *models.UpdateUserReq
 		return errJson, nil
 	}
 
-	userRequest.ID = AUTH.ID
+	userRequest.ID = auth.ID
 
 	validate := validator.New()
-	validate.RegisterValidation("email", isUniqueEmailFactory(userRequest))
-	validate.RegisterValidation("phone", isUniquePhoneFactory(userRequest))
+	validate.RegisterValidationCtx("email", isUniqueEmailFactory(userRequest.ID))
+	validate.RegisterValidationCtx("phone", isUniquePhoneFactory(userRequest.ID))
 
-	errValidate := validate.Struct(userRequest)
+	errValidate := validate.StructCtx(r.Context(), userRequest)
 	if errValidate != nil {
 		return errValidate, nil
 	}
@@ -633,55 +685,91 @@ func validateLoginUserRequest(r *http.Request) (error, models.LoginUserRequest)
 	return nil, req
 }
 
-// getNewToken создает новый JWT-токен
-func getNewToken(name string, email string) (string, error) {
-	// ExpiresAt в миллисекундах от Unix epoch
-	expiresAt := time.Now().Add(time.Hour).UnixMilli()
-	claims := models.Claims{
-		Username: name,
-		StandardClaims: jwt.StandardClaims{
-			Id:        email,
-			Subject:   name,
-			ExpiresAt: expiresAt,
-		},
+// getJWTSecret возвращает ключ подписи токенов
+func getJWTSecret() ([]byte, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("Не задан JWT_SECRET")
 	}
+	return []byte(secret), nil
+}
 
-	jwt_secret := os.Getenv("JWT_SECRET")
-	var secretKey = []byte(jwt_secret)
-	token := jwt.New(jwt.SigningMethodHS256)
-	token.Claims = claims
-	tokenString, err := token.SignedString(secretKey)
+// getNewToken создает новый JWT-токен доступа, привязанный к сессии sessionId.
+// В subject токена сохраняется ID пользователя, который не меняется при смене email.
+func getNewToken(userId int64, name string, sessionId string) (string, error) {
+	secretKey, err := getJWTSecret()
 	if err != nil {
 		return "", err
 	}
 
-	return tokenString, nil
+	tokenId, err := generateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := models.Claims{
+		Username:  name,
+		SessionID: sessionId,
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenId,
+			Subject:   strconv.FormatInt(userId, 10),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(accessTokenTTL).Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secretKey)
 }
 
-// ParseToken проверяет и парсит JWT-токен
+// ParseToken проверяет подпись, алгоритм и срок действия JWT-токена.
+// Любая ошибка проверки возвращается вызывающему коду.
 func ParseToken(tokenString string) (*jwt.Token, error) {
-	jwt_secret := os.Getenv("JWT_SECRET")
-	var secretKey = []byte(jwt_secret)
+	secretKey, err := getJWTSecret()
+	if err != nil {
+		return nil, err
+	}
 
-	// Парсим токен
 	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Возвращаем ключ для проверки подписи
-		return []byte(secretKey), nil
+		// Принимаем только токены, подписанные нашим алгоритмом, иначе подпись можно подделать сменой alg
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("Неожиданный алгоритм подписи: %v", token.Header["alg"])
+		}
+		return secretKey, nil
 	})
 	if err != nil {
-		log.Fatal("Ошибка при парсинге токена:", err)
+		return nil, err
 	}
 
-	// Извлекаем claims
-	if claims, ok := token.Claims.(*models.Claims); ok && token.Valid {
-		// Получаем значение jti
-		// jti хранится в поле Id структуры StandardClaims
-		_ = claims // явно указываем, что переменная используется
-	} else {
-		log.Fatal("Неверный токен или claims")
+	claims, ok := token.Claims.(*models.Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("Неверный токен")
 	}
+	// Библиотека не проверяет срок действия, если он не указан
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("Не указан срок действия токена")
+	}
+	if _, err := strconv.ParseInt(claims.Subject, 10, 64); err != nil {
+		return nil, fmt.Errorf("Неверный subject токена")
+	}
+
+	return token, nil
+}
+
+// dummyPasswordHash - хеш для сверки пароля при входе с неизвестным email
+var dummyPasswordHash = getHashPassword("dummy-password")
+
+// sendTooManyLoginAttempts отвечает, что попытки входа временно запрещены
+func sendTooManyLoginAttempts(w http.ResponseWriter, wait time.Duration) {
+	sendRetryAfter(w, wait, "Слишком много попыток входа, повторите через %d сек.")
+}
 
-	return token, err
+// sendRetryAfter отвечает 429 с заголовком Retry-After. В message подставляется время ожидания в секундах.
+func sendRetryAfter(w http.ResponseWriter, wait time.Duration, message string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	SendJSONError(w, http.StatusTooManyRequests, fmt.Sprintf(message, seconds))
 }
 
 func getHashPassword(password string) string {
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+)
+
+// canManageUser проверяет, что администратор может изменять пользователя target.
+// Изменять себя через административные методы нельзя, а пользователей с правом управления ролями
+// может изменять только тот, у кого это право тоже есть.
+func canManageUser(ctx context.Context, auth models.UserView, target models.UserView) (error, bool) {
+	if target.ID == auth.ID {
+		return nil, false
+	}
+	if Can(auth, models.PermissionRolesManage) {
+		return nil, true
+	}
+
+	err, permissions := getRolePermissions(ctx, target.Role.ID)
+	if err != nil {
+		return err, false
+	}
+	return nil, !hasPermission(models.UserView{Role: models.Role{Permissions: permissions}}, models.PermissionRolesManage)
+}
+
+// getManagedUser получает пользователя из параметра id и проверяет право администратора на его изменение.
+// При ошибке отправляет ответ клиенту и возвращает false.
+func getManagedUser(w http.ResponseWriter, r *http.Request) (models.UserView, bool) {
+	ctx := r.Context()
+	auth := getAuthUser(r)
+	vars := mux.Vars(r)
+	paramId, _ := strconv.Atoi(vars["id"])
+
+	errorResponse, userView := getUserViewById(ctx, int64(paramId))
+	if errorResponse != nil {
+		sendDatabaseError(w, errorResponse, http.StatusNotFound, "Пользователь не найден")
+		return userView, false
+	}
+
+	errManage, allowed := canManageUser(ctx, *auth, userView)
+	if errManage != nil {
+		log.Println("Ошибка при проверке прав", errManage)
+		sendDatabaseError(w, errManage, http.StatusInternalServerError, "Ошибка при проверке прав")
+		return userView, false
+	}
+	if !allowed {
+		SendJSONError(w, http.StatusForbidden, "Недостаточно прав для изменения этого пользователя")
+		return userView, false
+	}
+
+	return userView, true
+}
+
+// Документация для метода AdminUpdateUser
+// @Summary Изменение пользователя администратором
+// @Description Изменение данных и роли пользователя. При смене email пользователь должен подтвердить новый адрес
+// @Tags Пользователи
+// @Param id path int true "ID пользователя"
+// @Param updateUser body models.AdminUpdateUserRequest true "Данные пользователя"
+// @Consumes application/json
+// @Produces application/json
+// @Success 200 {object} models.UserView
+// @Failure 400 Bad Request
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Security BearerAuth
+// @Router /api/users/{id} [put]
+func AdminUpdateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			auth := getAuthUser(r)
+			userView, ok := getManagedUser(w, r)
+			if !ok {
+				return
+			}
+
+			var userRequest models.AdminUpdateUserRequest
+			if err := json.NewDecoder(r.Body).Decode(&userRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			userRequest.ID = userView.ID
+
+			validate := validator.New()
+			validate.RegisterValidationCtx("email", isUniqueEmailFactory(userRequest.ID))
+			validate.RegisterValidationCtx("phone", isUniquePhoneFactory(userRequest.ID))
+			if err := validate.StructCtx(ctx, userRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			roleId := userView.Role.ID
+			if userRequest.RoleID != nil && *userRequest.RoleID != roleId {
+				errorRole, role := getOneRoleById(ctx, *userRequest.RoleID)
+				if errorRole != nil {
+					sendDatabaseError(w, errorRole, http.StatusBadRequest, "Роль не найдена")
+					return
+				}
+				if hasPermission(models.UserView{Role: role}, models.PermissionRolesManage) && !Can(*auth, models.PermissionRolesManage) {
+					SendJSONError(w, http.StatusForbidden, "Недостаточно прав для назначения этой роли")
+					return
+				}
+				roleId = role.ID
+			}
+
+			// Новый email требует повторного подтверждения, статус блокировки сохраняется.
+			// У заблокированного пользователя неподтвержденным становится статус, восстанавливаемый при разблокировке.
+			status := userView.Status
+			emailChanged := userRequest.Email != userView.Email
+			if emailChanged && status == models.UserStatusVerified {
+				status = models.UserStatusUnverified
+			}
+
+			_, err := database.Conn(ctx).Exec("UPDATE users SET name = $1, email = $2, phone = $3, city = $4, role_id = $5, status = $6, "+
+				"status_before_block = CASE WHEN $8 AND status_before_block IS NOT NULL THEN $9 ELSE status_before_block END, updated_at = now() WHERE id = $7",
+				userRequest.Name,
+				userRequest.Email,
+				userRequest.Phone,
+				userRequest.City,
+				roleId,
+				status,
+				userView.ID,
+				emailChanged,
+				models.UserStatusUnverified)
+			if err != nil {
+				log.Println("Ошибка при изменении пользователя", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при изменении пользователя")
+				return
+			}
+
+			writeAuditLog(r, &auth.ID, models.AuditEventUserUpdated, map[string]interface{}{
+				"user_id":       userView.ID,
+				"email_changed": emailChanged,
+				"role_id":       roleId,
+			})
+
+			if emailChanged && status == models.UserStatusUnverified {
+				if err := sendEmailVerification(ctx, userView.ID, userRequest.Name, userRequest.Email); err != nil {
+					log.Println("Ошибка при отправке письма для подтверждения email", err)
+				}
+			}
+
+			errorResponse, updatedUser := getUserViewById(ctx, userView.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(updatedUser)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// setUserBlocked блокирует или разблокирует пользователя.
+// При блокировке все сессии пользователя завершаются. При разблокировке восстанавливается статус,
+// который был до блокировки, поэтому неподтвержденный email не становится подтвержденным.
+func setUserBlocked(ctx context.Context, userId int64, blocked bool) (error, bool) {
+	var query string
+	if blocked {
+		query = fmt.Sprintf("UPDATE users SET status_before_block = status, status = %d, updated_at = now() WHERE id = $1 AND status <> %d",
+			models.UserStatusBlocked, models.UserStatusBlocked)
+	} else {
+		query = fmt.Sprintf("UPDATE users SET status = COALESCE(status_before_block, %d), status_before_block = NULL, updated_at = now() WHERE id = $1 AND status = %d",
+			models.UserStatusUnverified, models.UserStatusBlocked)
+	}
+
+	changed := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		result, err := tx.Exec(query, userId)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		if blocked {
+			if err := revokeUserSessions(tx, userId); err != nil {
+				return err
+			}
+		}
+		changed = true
+		return nil
+	})
+
+	return err, err == nil && changed
+}
+
+// blockUserHandler возвращает обработчик блокировки или разблокировки пользователя
+func blockUserHandler(blocked bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			auth := getAuthUser(r)
+			userView, ok := getManagedUser(w, r)
+			if !ok {
+				return
+			}
+
+			errBlock, changed := setUserBlocked(ctx, userView.ID, blocked)
+			if errBlock != nil {
+				log.Println("Ошибка при изменении статуса пользователя", errBlock)
+				sendDatabaseError(w, errBlock, http.StatusInternalServerError, "Ошибка при изменении статуса пользователя")
+				return
+			}
+			if !changed {
+				if blocked {
+					SendJSONError(w, http.StatusConflict, "Пользователь уже заблокирован")
+				} else {
+					SendJSONError(w, http.StatusConflict, "Пользователь не заблокирован")
+				}
+				return
+			}
+
+			event := models.AuditEventUserUnblocked
+			if blocked {
+				event = models.AuditEventUserBlocked
+			}
+			writeAuditLog(r, &auth.ID, event, map[string]interface{}{
+				"user_id":         userView.ID,
+				"previous_status": userView.Status,
+			})
+
+			errorResponse, updatedUser := getUserViewById(ctx, userView.ID)
+			if errorResponse != nil {
+				sendDatabaseError(w, errorResponse, http.StatusBadRequest, errorResponse.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(updatedUser)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода BlockUser
+// @Summary Блокировка пользователя
+// @Description Блокировка пользователя. Все сессии пользователя завершаются, войти он не сможет до разблокировки
+// @Tags Пользователи
+// @Param id path int true "ID пользователя"
+// @Produces application/json
+// @Success 200 {object} models.UserView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/users/{id}/block [post]
+func BlockUser() http.HandlerFunc {
+	return blockUserHandler(true)
+}
+
+// Документация для метода UnblockUser
+// @Summary Разблокировка пользователя
+// @Description Разблокировка пользователя. Восстанавливается статус подтверждения email, который был до блокировки
+// @Tags Пользователи
+// @Param id path int true "ID пользователя"
+// @Produces application/json
+// @Success 200 {object} models.UserView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/users/{id}/unblock [post]
+func UnblockUser() http.HandlerFunc {
+	return blockUserHandler(false)
+}
+
+// Документация для метода DeleteUser
+// @Summary Удаляет пользователя по ID
+// @Description Мягкое удаление пользователя: запись сохраняется с датой удаления, все сессии завершаются
+// @Tags Пользователи
+// @Param id path int true "ID пользователя"
+// @Success 204 No Content
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Security BearerAuth
+// @Router /api/users/{id} [delete]
+func DeleteUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			auth := getAuthUser(r)
+			userView, ok := getManagedUser(w, r)
+			if !ok {
+				return
+			}
+
+			err := database.WithTx(ctx, func(tx *database.Tx) error {
+				if err, _ := softDeleteRow(tx, "users", userView.ID, time.Now()); err != nil {
+					return err
+				}
+				return revokeUserSessions(tx, userView.ID)
+			})
+			if err != nil {
+				log.Println("Ошибка при удалении пользователя", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при удалении пользователя")
+				return
+			}
+
+			writeAuditLog(r, &auth.ID, models.AuditEventUserDeleted, map[string]interface{}{
+				"user_id": userView.ID,
+			})
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "PUT, DELETE, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// Документация для метода RestoreUser
+// @Summary Восстановление удаленного пользователя
+// @Description Восстановление пользователя, удаленного мягким удалением. Невозможно, если email или телефон уже заняты
+// @Tags Пользователи
+// @Param id path int true "ID пользователя"
+// @Produces application/json
+// @Success 200 {object} models.UserView
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 Not Found
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/users/{id}/restore [post]
+func RestoreUser() http.HandlerFunc {
+	return restoreHandler(
+		deletedIdFromPath,
+		restoreById("users"),
+		func(ctx context.Context, id int64) (error, interface{}) {
+			return getUserViewById(ctx, id)
+		},
+	)
+}
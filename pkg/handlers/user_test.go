@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"goland_api/pkg/models"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+func TestParseToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", testJWTSecret)
+
+	for _, forged := range forgedTokens(t) {
+		t.Run(forged.name, func(t *testing.T) {
+			if token, err := ParseToken(forged.token); err == nil {
+				t.Fatalf("ParseToken accepted forged token: %+v", token.Claims)
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		token, err := ParseToken(signTestToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), testClaims(7, "session")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subject := token.Claims.(*models.Claims).Subject; subject != "7" {
+			t.Fatalf("subject = %q, want %q", subject, "7")
+		}
+	})
+
+	t.Run("no secret", func(t *testing.T) {
+		token := signTestToken(t, jwt.SigningMethodHS256, []byte(""), testClaims(7, "session"))
+		t.Setenv("JWT_SECRET", "")
+		if _, err := ParseToken(token); err == nil {
+			t.Fatal("ParseToken accepted token without JWT_SECRET")
+		}
+	})
+}
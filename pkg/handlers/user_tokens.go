@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/services/loginguard"
+	"goland_api/pkg/services/mailer"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// Время жизни одноразовых токенов из писем
+const (
+	passwordResetTokenTTL     = time.Hour
+	emailVerificationTokenTTL = 48 * time.Hour
+)
+
+// Максимальное время отправки письма, которое отправляется после ответа на запрос
+const backgroundMailTimeout = time.Minute
+
+// backgroundMails - письма, которые отправляются после ответа на запрос
+var backgroundMails sync.WaitGroup
+
+// getAppURL возвращает адрес клиентского приложения, на который ведут ссылки из писем
+func getAppURL() string {
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		return appURL
+	}
+	return "http://localhost:3000"
+}
+
+// createUserToken выпускает одноразовый токен tokenType для пользователя.
+// Ранее выпущенные неиспользованные токены того же типа становятся недействительными.
+func createUserToken(ctx context.Context, userId int64, email string, tokenType string, ttl time.Duration) (error, string) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return err, ""
+	}
+
+	err = database.WithTx(ctx, func(tx *database.Tx) error {
+		_, err := tx.Exec("UPDATE user_tokens SET used_at = now() WHERE user_id = $1 AND type = $2 AND used_at IS NULL", userId, tokenType)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO user_tokens (user_id, type, token_hash, email, expires_at) VALUES ($1, $2, $3, $4, $5)",
+			userId, tokenType, hashToken(token), email, time.Now().Add(ttl))
+		return err
+	})
+	if err != nil {
+		return err, ""
+	}
+
+	return nil, token
+}
+
+// useUserToken помечает токен использованным в транзакции tx и возвращает пользователя и email, для которых он выпущен.
+// Возвращает false, если токен не найден, истек или уже использован.
+func useUserToken(tx *database.Tx, token string, tokenType string) (error, int64, string, bool) {
+	var userId int64
+	var email string
+	err := tx.QueryRow("UPDATE user_tokens SET used_at = now() WHERE token_hash = $1 AND type = $2 AND used_at IS NULL AND expires_at > now() RETURNING user_id, email",
+		hashToken(token), tokenType).Scan(&userId, &email)
+	if err == sql.ErrNoRows {
+		return nil, 0, "", false
+	}
+	if err != nil {
+		return err, 0, "", false
+	}
+	return nil, userId, email, true
+}
+
+// resetUserPassword устанавливает новый пароль по токену из письма и завершает все сессии пользователя.
+// Возвращает false, если токен недействителен.
+func resetUserPassword(ctx context.Context, token string, password string) (error, bool) {
+	reset := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		errToken, userId, email, ok := useUserToken(tx, token, models.UserTokenPasswordReset)
+		if errToken != nil || !ok {
+			return errToken
+		}
+
+		// Переход по ссылке из письма подтверждает владение email
+		_, err := tx.Exec("UPDATE users SET password = $1, status = CASE WHEN email = $2 AND status = $3 THEN $4 ELSE status END, updated_at = now() WHERE id = $5",
+			password,
+			email,
+			models.UserStatusUnverified,
+			models.UserStatusVerified,
+			userId)
+		if err != nil {
+			return err
+		}
+
+		// После смены пароля все открытые сессии завершаются
+		if err := revokeUserSessions(tx, userId); err != nil {
+			return err
+		}
+		reset = true
+		return nil
+	})
+
+	return err, err == nil && reset
+}
+
+// verifyUserEmail подтверждает email пользователя по токену из письма.
+// Возвращает false, если токен недействителен или email с тех пор изменился.
+func verifyUserEmail(ctx context.Context, token string) (error, bool) {
+	verified := false
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		errToken, userId, email, ok := useUserToken(tx, token, models.UserTokenEmailVerification)
+		if errToken != nil || !ok {
+			return errToken
+		}
+
+		// Ссылка подтверждает только тот email, на который была отправлена
+		result, err := tx.Exec("UPDATE users SET status = $1, updated_at = now() WHERE id = $2 AND email = $3 AND status = $4",
+			models.UserStatusVerified, userId, email, models.UserStatusUnverified)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+		verified = true
+		return nil
+	})
+
+	return err, err == nil && verified
+}
+
+// sendEmailVerification отправляет пользователю письмо со ссылкой для подтверждения email
+func sendEmailVerification(ctx context.Context, userId int64, name string, email string) error {
+	err, token := createUserToken(ctx, userId, email, models.UserTokenEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := getAppURL() + "/verify-email?token=" + url.QueryEscape(token)
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Подтверждение email",
+		Body: "Здравствуйте, " + name + "!\n\n" +
+			"Для подтверждения email перейдите по ссылке:\n" + link + "\n\n" +
+			fmt.Sprintf("Ссылка действительна %.0f ч.", emailVerificationTokenTTL.Hours()),
+	})
+}
+
+// sendPasswordReset отправляет пользователю письмо со ссылкой для сброса пароля
+func sendPasswordReset(ctx context.Context, userId int64, name string, email string) error {
+	err, token := createUserToken(ctx, userId, email, models.UserTokenPasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := getAppURL() + "/reset-password?token=" + url.QueryEscape(token)
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Восстановление пароля",
+		Body: "Здравствуйте, " + name + "!\n\n" +
+			"Для установки нового пароля перейдите по ссылке:\n" + link + "\n\n" +
+			fmt.Sprintf("Ссылка действительна %.0f ч. ", passwordResetTokenTTL.Hours()) +
+			"Если вы не запрашивали восстановление пароля, проигнорируйте это письмо.",
+	})
+}
+
+// sendPasswordResetInBackground ищет пользователя по email и отправляет ему письмо для сброса пароля после ответа на запрос.
+// Поиск и отправка выполняются только для зарегистрированного email, поэтому по времени ответа нельзя узнать, зарегистрирован ли он.
+func sendPasswordResetInBackground(ctx context.Context, email string) {
+	backgroundMails.Add(1)
+	go func() {
+		defer backgroundMails.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundMailTimeout)
+		defer cancel()
+
+		errorUser, userView := getUserViewByEmail(ctx, email)
+		if errorUser == sql.ErrNoRows {
+			return
+		}
+		if errorUser != nil {
+			log.Println("Ошибка при поиске пользователя", errorUser)
+			return
+		}
+		if err := sendPasswordReset(ctx, userView.ID, userView.Name, userView.Email); err != nil {
+			log.Println("Ошибка при отправке письма для восстановления пароля", err)
+		}
+	}()
+}
+
+// @Summary Запрос на восстановление пароля
+// @Description Отправка на email ссылки для установки нового пароля. Ответ не зависит от того, зарегистрирован ли email.
+// @Description Количество писем ограничено для одного email и для одного IP-адреса
+// @Tags Аутентификация
+// @Param forgotPassword body models.ForgotPasswordRequest true "Email пользователя"
+// @Accept  application/json
+// @Produce  application/json
+// @Success 202 "Accepted"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 429 {object} models.ErrorResponse "Too Many Requests"
+// @Router /api/auth/password/forgot [post]
+func ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var forgotRequest models.ForgotPasswordRequest
+			if err := json.NewDecoder(r.Body).Decode(&forgotRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(forgotRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			// Запросы ограничиваются и для незарегистрированных email, чтобы ограничение не выдавало регистрацию
+			wait, errGuard := loginguard.Default.ReservePasswordReset(ctx, forgotRequest.Email, getClientIP(r), time.Now())
+			if errGuard != nil {
+				log.Println("Ошибка при учете запроса на восстановление пароля", errGuard)
+				sendDatabaseError(w, errGuard, http.StatusInternalServerError, "Ошибка при восстановлении пароля")
+				return
+			}
+			if wait > 0 {
+				sendRetryAfter(w, wait, "Слишком много запросов на восстановление пароля, повторите через %d сек.")
+				return
+			}
+
+			// Чтобы по ответу и его времени нельзя было узнать, зарегистрирован ли email,
+			// ответ всегда одинаковый, а пользователь ищется и письмо отправляется после ответа
+			sendPasswordResetInBackground(ctx, forgotRequest.Email)
+
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Установка нового пароля
+// @Description Установка нового пароля по токену из письма. Все сессии пользователя завершаются
+// @Tags Аутентификация
+// @Param resetPassword body models.ResetPasswordRequest true "Токен и новый пароль"
+// @Accept  application/json
+// @Produce  application/json
+// @Success 204 No Content
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Router /api/auth/password/reset [post]
+func ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var resetRequest models.ResetPasswordRequest
+			if err := json.NewDecoder(r.Body).Decode(&resetRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(resetRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			errReset, ok := resetUserPassword(ctx, resetRequest.Token, getHashPassword(resetRequest.Password))
+			if errReset != nil {
+				log.Println("Ошибка при установке пароля", errReset)
+				sendDatabaseError(w, errReset, http.StatusInternalServerError, "Ошибка при установке пароля")
+				return
+			}
+			if !ok {
+				SendJSONError(w, http.StatusBadRequest, "Ссылка недействительна или устарела")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Подтверждение email
+// @Description Подтверждение email по токену из письма
+// @Tags Аутентификация
+// @Param verifyEmail body models.VerifyEmailRequest true "Токен из письма"
+// @Accept  application/json
+// @Produce  application/json
+// @Success 204 No Content
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Router /api/auth/email/verify [post]
+func VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var verifyRequest models.VerifyEmailRequest
+			if err := json.NewDecoder(r.Body).Decode(&verifyRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			validate := validator.New()
+			if err := validate.Struct(verifyRequest); err != nil {
+				SendJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			errVerify, ok := verifyUserEmail(ctx, verifyRequest.Token)
+			if errVerify != nil {
+				log.Println("Ошибка при подтверждении email", errVerify)
+				sendDatabaseError(w, errVerify, http.StatusInternalServerError, "Ошибка при подтверждении email")
+				return
+			}
+			if !ok {
+				SendJSONError(w, http.StatusBadRequest, "Ссылка недействительна или устарела")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// @Summary Повторная отправка письма для подтверждения email
+// @Description Повторная отправка ссылки для подтверждения email текущего пользователя
+// @Tags Аутентификация
+// @Produce  application/json
+// @Success 202 "Accepted"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "Conflict"
+// @Security BearerAuth
+// @Router /api/auth/email/resend [post]
+func ResendEmailVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method == http.MethodOptions {
+			// Устанавливаем заголовки для CORS
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+			// Отправляем успешный ответ
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			auth := getAuthUser(r)
+			if auth.Status != models.UserStatusUnverified {
+				SendJSONError(w, http.StatusConflict, "Email уже подтвержден")
+				return
+			}
+
+			if err := sendEmailVerification(ctx, auth.ID, auth.Name, auth.Email); err != nil {
+				log.Println("Ошибка при отправке письма для подтверждения email", err)
+				sendDatabaseError(w, err, http.StatusInternalServerError, "Ошибка при отправке письма")
+				return
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Если метод не поддерживается
+		w.Header().Set("Allow", "POST, OPTIONS")
+		SendJSONError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"goland_api/pkg/database/dbtest"
+	"goland_api/pkg/models"
+	"goland_api/pkg/services/loginguard"
+	"goland_api/pkg/services/mailer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingMailer отправляет письма в sent, но не раньше, чем закрыт release
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan mailer.Message
+}
+
+func (m *blockingMailer) Send(message mailer.Message) error {
+	<-m.release
+	m.sent <- message
+	return nil
+}
+
+// forgotPasswordDB отвечает на запросы восстановления пароля: зарегистрирован только registered
+func forgotPasswordDB(tb testing.TB, registered string) *dbtest.DB {
+	return dbtest.Open(tb, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.Contains(query, "FROM users u") && strings.Contains(query, "u.email = $1"):
+			if args[0] != registered {
+				return dbtest.Result{Columns: []string{"id"}}, nil
+			}
+			return dbtest.Result{Rows: [][]driver.Value{{
+				int64(1), "User 1", registered, nil, nil, nil, nil, int64(models.UserStatusVerified), time.Now(), int64(2), "user",
+			}}}, nil
+		case strings.HasPrefix(query, "UPDATE user_tokens"), strings.HasPrefix(query, "INSERT INTO user_tokens"):
+			return dbtest.Result{RowsAffected: 1}, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+}
+
+// useTestGuard подменяет ограничитель попыток новым с хранилищем в памяти
+func useTestGuard(tb testing.TB) *loginguard.Guard {
+	previous := loginguard.Default
+	loginguard.Default = loginguard.New(loginguard.NewMemoryStore())
+	tb.Cleanup(func() {
+		loginguard.Default = previous
+	})
+	return loginguard.Default
+}
+
+func forgotPassword(email string, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/password/forgot", strings.NewReader(`{"email": "`+email+`"}`))
+	r.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	ForgotPassword()(w, r)
+	return w
+}
+
+// Ответ не ждет отправки письма, поэтому по времени ответа нельзя узнать, зарегистрирован ли email
+func TestForgotPasswordSendsMailAfterResponse(t *testing.T) {
+	useTestGuard(t)
+	forgotPasswordDB(t, "user@example.com")
+	mail := &blockingMailer{release: make(chan struct{}), sent: make(chan mailer.Message, 1)}
+	previous := mailer.Default
+	mailer.Default = mail
+	t.Cleanup(func() {
+		backgroundMails.Wait()
+		mailer.Default = previous
+	})
+
+	for _, email := range []string{"user@example.com", "unknown@example.com"} {
+		if w := forgotPassword(email, "203.0.113.5:1234"); w.Code != http.StatusAccepted {
+			t.Fatalf("%s: status = %d, want %d, body = %s", email, w.Code, http.StatusAccepted, w.Body.String())
+		}
+	}
+
+	close(mail.release)
+	backgroundMails.Wait()
+	select {
+	case message := <-mail.sent:
+		if message.To != "user@example.com" {
+			t.Fatalf("mail sent to %s, want user@example.com", message.To)
+		}
+	default:
+		t.Fatal("mail was not sent")
+	}
+	if len(mail.sent) != 0 {
+		t.Fatal("mail sent to unknown email")
+	}
+}
+
+func TestForgotPasswordThrottling(t *testing.T) {
+	cases := []struct {
+		name   string
+		limit  func(guard *loginguard.Guard) int
+		email  func(i int) string
+		remote func(i int) string
+	}{
+		// Одинаково для зарегистрированного и незарегистрированного email
+		{"registered email", func(g *loginguard.Guard) int { return g.ResetAccount.FreeAttempts + 1 },
+			func(int) string { return "user@example.com" }, func(i int) string { return fmt.Sprintf("203.0.113.%d:1234", i+1) }},
+		{"unknown email", func(g *loginguard.Guard) int { return g.ResetAccount.FreeAttempts + 1 },
+			func(int) string { return "unknown@example.com" }, func(i int) string { return fmt.Sprintf("203.0.113.%d:1234", i+1) }},
+		{"ip", func(g *loginguard.Guard) int { return g.ResetIP.FreeAttempts + 1 },
+			func(i int) string { return fmt.Sprintf("user%d@example.com", i) }, func(int) string { return "203.0.113.5:1234" }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			guard := useTestGuard(t)
+			forgotPasswordDB(t, "user@example.com")
+			t.Cleanup(backgroundMails.Wait)
+
+			limit := tc.limit(guard)
+			for i := 0; i < limit; i++ {
+				if w := forgotPassword(tc.email(i), tc.remote(i)); w.Code != http.StatusAccepted {
+					t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, http.StatusAccepted)
+				}
+			}
+
+			w := forgotPassword(tc.email(limit), tc.remote(limit))
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Fatal("no Retry-After header")
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+// События журнала аудита
+const (
+	AuditEventLoginLocked   = "login.locked"   // Вход заблокирован после серии неудачных попыток
+	AuditEventUserUpdated   = "user.updated"   // Пользователь изменен администратором
+	AuditEventUserBlocked   = "user.blocked"   // Пользователь заблокирован
+	AuditEventUserUnblocked = "user.unblocked" // Пользователь разблокирован
+	AuditEventUserDeleted   = "user.deleted"   // Пользователь удален
+	AuditEventUserRoleSet   = "user.role_set"  // Пользователю назначена роль
+)
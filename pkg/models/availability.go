@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Статусы интервалов занятости площадки
+const (
+	AvailabilityFree   = "free"   // Свободно
+	AvailabilityBusy   = "busy"   // Занято арендой
+	AvailabilityClosed = "closed" // Площадка закрыта по расписанию
+)
+
+// AvailabilityInterval - интервал занятости площадки
+type AvailabilityInterval struct {
+	Start    time.Time `json:"start"`               // Начало интервала
+	End      time.Time `json:"end"`                 // Окончание интервала
+	Status   string    `json:"status"`              // Статус интервала
+	RentalID *int64    `json:"rental_id,omitempty"` // Аренда, занимающая интервал
+}
+
+// FieldAvailability - календарь свободных и занятых интервалов площадки
+type FieldAvailability struct {
+	FieldID   int64                  `json:"field_id"`  // Идентификатор площадки
+	Slug      string                 `json:"slug"`      // Slug площадки
+	From      time.Time              `json:"from"`      // Начало периода
+	To        time.Time              `json:"to"`        // Окончание периода
+	Slot      string                 `json:"slot"`      // Длительность слота
+	Intervals []AvailabilityInterval `json:"intervals"` // Интервалы
+}
@@ -2,6 +2,7 @@ package models
 
 type AddressRequest struct {
 	Query string `json:"query"`
+	Count int    `json:"count,omitempty"`
 }
 
 type Geo struct {
@@ -13,6 +13,8 @@ type Field struct {
 	City        string           `json:"city"`
 	Address     string           `json:"address"`
 	Location    *json.RawMessage `json:"location"`
+	Lat         *float64         `json:"lat"` // Широта
+	Lon         *float64         `json:"lon"` // Долгота
 	Square      *int             `json:"square"`
 	Info        *string          `json:"info"`
 	Places      int              `json:"places"`
@@ -38,6 +40,9 @@ type FieldView struct {
 	City        string           `json:"city"`
 	Address     string           `json:"address"`
 	Location    *json.RawMessage `json:"location" swaggertype:"string"`
+	Lat         *float64         `json:"lat"`                // Широта
+	Lon         *float64         `json:"lon"`                // Долгота
+	Distance    *float64         `json:"distance,omitempty"` // Расстояние до точки поиска, км
 	Square      *int             `json:"square"`
 	Info        *string          `json:"info"`
 	Places      int              `json:"places"`
@@ -60,6 +65,8 @@ type CreateFieldRequest struct {
 	City        string           `json:"city" validate:"required"`
 	Address     string           `json:"address" validate:"required"`
 	Location    *json.RawMessage `json:"location" swaggertype:"string"`
+	Lat         *float64         `json:"lat" validate:"omitempty,min=-90,max=90"`   // Широта, если не указана - определяется по адресу
+	Lon         *float64         `json:"lon" validate:"omitempty,min=-180,max=180"` // Долгота, если не указана - определяется по адресу
 	Square      *int             `json:"square"`
 	Info        *string          `json:"info"`
 	Places      int              `json:"places"`
@@ -80,6 +87,8 @@ type UpdateFieldRequest struct {
 	City        string           `json:"city" validate:"required"`
 	Address     string           `json:"address" validate:"required"`
 	Location    *json.RawMessage `json:"location" swaggertype:"string"`
+	Lat         *float64         `json:"lat" validate:"omitempty,min=-90,max=90"`   // Широта, если не указана - определяется по адресу
+	Lon         *float64         `json:"lon" validate:"omitempty,min=-180,max=180"` // Долгота, если не указана - определяется по адресу
 	Square      *int             `json:"square"`
 	Info        *string          `json:"info"`
 	Places      int              `json:"places"`
@@ -92,3 +101,24 @@ type UpdateFieldRequest struct {
 	Media       *json.RawMessage `json:"media" swaggertype:"string"`
 	Responsible UserView         `json:"responsible"` // Ответственный
 }
+
+// FieldSearchFilter - параметры фильтрации списка площадок
+type FieldSearchFilter struct {
+	Search      *string `json:"search"`       // Поиск по названию, описанию и адресу
+	City        *string `json:"city"`         // Город
+	ForDisabled *bool   `json:"for_disabled"` // Подходит для инвалидов
+	Parking     *bool   `json:"parking"`      // Наличие парковки
+	Toilet      *bool   `json:"toilet"`       // Наличие туалета
+	Dressing    *bool   `json:"dressing"`     // Наличие раздевалки
+	Display     *bool   `json:"display"`      // Наличие цифрового табло
+	MinPlaces   *int    `json:"min_places"`   // Минимальное количество мест
+	MinSquare   *int    `json:"min_square"`   // Минимальная площадь
+}
+
+// NearbyFieldsFilter - параметры поиска площадок рядом с точкой
+type NearbyFieldsFilter struct {
+	FieldSearchFilter
+	Lat      float64 `json:"lat"`       // Широта точки поиска
+	Lon      float64 `json:"lon"`       // Долгота точки поиска
+	RadiusKm float64 `json:"radius_km"` // Радиус поиска, км
+}
@@ -4,12 +4,59 @@ import(
 	"time"
 )
 
+// Назначения загружаемых файлов
+const (
+	MediaUsageLogo    = "logo"    // Логотип: только изображения
+	MediaUsageGallery = "gallery" // Галерея: изображения и видео
+)
+
+// Типы объектов, к которым привязываются медиафайлы
+const (
+	MediaEntityField = "field" // Площадка
+	MediaEntityTeam  = "team"  // Команда
+	MediaEntityUser  = "user"  // Пользователь
+)
+
 // Media - структура для медиа-файлов
 type Media struct {
 	ID      	int     	`json:"id"`
 	Name      	string    	`json:"name"`
-	Path      	string     	`json:"path"`
+	Path      	string     	`json:"path"`			// Ключ файла в хранилище
 	Ext 		string     	`json:"ext"`
+	MimeType 	string 		`json:"mime_type"`		// Тип содержимого, определенный по самому файлу
 	Size     	int64      	`json:"size"`
+	UserID 		*int64 		`json:"user_id"`		// Пользователь, загрузивший файл
 	CreatedAt 	time.Time 	`json:"created_at"`
-}
\ No newline at end of file
+	URL 		string 		`json:"url"`			// Ссылка для скачивания файла
+	Variants 	map[string]MediaVariant `json:"variants"`	// Производные изображения по имени размера
+}
+
+// MediaVariant - производное изображение: уменьшенная копия или копия в другом формате
+type MediaVariant struct {
+	Key 		string 		`json:"key"`			// Ключ файла в хранилище
+	MimeType 	string 		`json:"mime_type"`		// Тип содержимого
+	Width 		int 		`json:"width"`			// Ширина в пикселях
+	Height 		int 		`json:"height"`			// Высота в пикселях
+	Size 		int64 		`json:"size"`			// Размер
+	URL 		string 		`json:"url,omitempty"`	// Ссылка для скачивания файла
+}
+// DefaultUploadCompleteTimeout - время, за которое должна завершиться сборка загрузки по частям.
+// Захват загрузки старше этого времени считается снятым, например после перезапуска сервера.
+const DefaultUploadCompleteTimeout = 10 * time.Minute
+
+// UploadSession - загрузка файла по частям
+type UploadSession struct {
+	ID 			string 		`json:"id"`
+	Usage 		string 		`json:"usage"`				// Назначение файла: logo, gallery
+	FileName 	string 		`json:"file_name"`			// Имя файла у клиента
+	Size 		int64 		`json:"size"`				// Размер файла
+	Offset 		int64 		`json:"offset"`				// Количество полученных байт, с него продолжается загрузка
+	ExpiresAt 	time.Time 	`json:"expires_at"`			// Дата, после которой незавершенная загрузка удаляется
+	CreatedAt 	time.Time 	`json:"created_at"`
+}
+
+// CreateUploadSessionRequest - запрос на начало загрузки файла по частям
+type CreateUploadSessionRequest struct {
+	FileName 	string 		`json:"file_name" validate:"required,max=255"`	// Имя файла у клиента
+	Size 		int64 		`json:"size" validate:"required,min=1"`			// Размер файла
+}
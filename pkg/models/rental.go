@@ -4,6 +4,51 @@ import(
 	"time"
 )
 
+// Статусы аренды
+const (
+	RentalStatusPending   = 1 // Ожидает подтверждения
+	RentalStatusConfirmed = 2 // Подтверждена
+	RentalStatusRejected  = 3 // Отклонена
+	RentalStatusCompleted = 4 // Завершена
+	RentalStatusCancelled = 5 // Отменена
+	RentalStatusNoShow    = 6 // Команда не пришла
+)
+
+// RentalStatusNames - названия статусов аренды
+var RentalStatusNames = map[int]string{
+	RentalStatusPending:   "pending",
+	RentalStatusConfirmed: "confirmed",
+	RentalStatusRejected:  "rejected",
+	RentalStatusCompleted: "completed",
+	RentalStatusCancelled: "cancelled",
+	RentalStatusNoShow:    "no_show",
+}
+
+// RentalStatusTransitions - допустимые переходы между статусами аренды
+var RentalStatusTransitions = map[int][]int{
+	RentalStatusPending:   {RentalStatusConfirmed, RentalStatusRejected, RentalStatusCancelled},
+	RentalStatusConfirmed: {RentalStatusCompleted, RentalStatusCancelled, RentalStatusNoShow},
+}
+
+// Частота повторения аренды
+const (
+	RentalFrequencyWeekly   = "weekly"   // Каждую неделю
+	RentalFrequencyBiweekly = "biweekly" // Раз в две недели
+)
+
+// MaxRentalOccurrences - максимальное количество аренд в серии
+const MaxRentalOccurrences = 52
+
+// CanChangeRentalStatus проверяет допустимость перехода аренды из статуса from в статус to
+func CanChangeRentalStatus(from int, to int) bool {
+	for _, status := range RentalStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
 // Определяем структуру "Аренда"
 type Rental struct {
 	ID    		 int64   		`json:"id"`			   // Идентификатор
@@ -15,6 +60,7 @@ type Rental struct {
 	EndDate      time.Time 		`json:"end_date"`      // Дата завершения аренды
 	Duration     int       		`json:"duration"`      // Длительность аренды (например, в часах)
 	Status       int    		`json:"status"`        // Статус аренды
+	SeriesID     *int64    		`json:"series_id"`     // Серия повторяющихся аренд
 	CreatedAt    time.Time      `json:"created_at"`    // Дата создания
 	UpdatedAt    time.Time      `json:"updated_at"`    // Дата последнего обновления
 	DeletedAt 	 *time.Time 	`json:"deleted_at"`	   // Дата удаления
@@ -30,6 +76,8 @@ type RentalView struct {
 	EndDate      time.Time 		`json:"end_date"`      // Дата завершения аренды
 	Duration     int       		`json:"duration"`      // Длительность аренды (например, в часах)
 	Status       int    		`json:"status"`        // Статус аренды
+	StatusName   string    		`json:"status_name"`   // Название статуса аренды
+	SeriesID     *int64    		`json:"series_id"`     // Серия повторяющихся аренд
 	CreatedAt    time.Time      `json:"created_at"`    // Дата создания
 }
 
@@ -40,13 +88,54 @@ type CreateRentalRequest struct {
 	Comment      string    		`json:"comment"`       					   // Статус аренды
 	StartDate    string 		`json:"start_date" validate:"required"`    // Дата начала аренды
 	EndDate      string 		`json:"end_date" validate:"required"`      // Дата завершения аренды
+	Recurrence   *RentalRecurrence `json:"recurrence"`                  // Правило повторения аренды
+}
+
+// RentalRecurrence - правило повторения аренды
+type RentalRecurrence struct {
+	Frequency    string 		`json:"frequency" validate:"required,oneof=weekly biweekly"` // Частота повторения
+	Until        *string 		`json:"until"`                                                // Дата окончания серии (включительно)
+	Count        *int 			`json:"count" validate:"omitempty,min=1,max=52"`             // Количество повторений
+}
+
+// RentalSeriesView - серия повторяющихся аренд
+type RentalSeriesView struct {
+	ID    		 int64   		`json:"id"`			   // Идентификатор
+	Frequency    string 		`json:"frequency"`     // Частота повторения
+	Until        *time.Time 	`json:"until"`         // Дата окончания серии
+	Count        *int 			`json:"count"`         // Количество повторений
+	Rentals      []RentalView 	`json:"rentals"`       // Аренды серии
+	CreatedAt    time.Time      `json:"created_at"`    // Дата создания
 }
 
 type RentalSearchFilter struct {
-	Search 		*string   	`json:"search"` 		// Поисковый запрос
+	Search 		*string   	`json:"search"` 		// Поисковый запрос (комментарий, команда, площадка)
 	FieldId   	*[]int64 	`json:"field_ids"`   	// Фильтр по площадкам
 	TeamId   	*[]int64 	`json:"team_ids"`   	// Фильтр по командам
-	StartDate   *string 	`json:"start_date"`     // Дата начала аренды
-	EndDate     *string 	`json:"end_date"`       // Дата завершения аренды
-	Status      *int    	`json:"status"`         // Статус аренды
-}
\ No newline at end of file
+	StartDate   *string 	`json:"start_date"`     // Аренды, заканчивающиеся после даты
+	EndDate     *string 	`json:"end_date"`       // Аренды, начинающиеся до даты
+	Status      *[]int    	`json:"status"`         // Статусы аренды
+	Sort        string    	`json:"sort"`           // Сортировка
+}
+// RentalConflictResponse - ответ при пересечении аренды с уже существующими
+type RentalConflictResponse struct {
+	Code 		 int 			`json:"code"`						// Код ответа
+	Message 	 string 		`json:"message"`					// Сообщение
+	Conflicts 	 []RentalView 	`json:"conflicts"`					// Пересекающиеся аренды
+}
+
+// RentalStatusHistory - запись истории изменения статуса аренды
+type RentalStatusHistory struct {
+	ID    		 int64   		`json:"id"`			   // Идентификатор
+	RentalID     int64       	`json:"rental_id"`     // Идентификатор аренды
+	FromStatus   *int       	`json:"from_status"`   // Предыдущий статус
+	ToStatus     int       		`json:"to_status"`     // Новый статус
+	UserID       *int64       	`json:"user_id"`       // Пользователь, изменивший статус
+	Comment      *string    	`json:"comment"`       // Комментарий
+	CreatedAt    time.Time      `json:"created_at"`    // Дата изменения статуса
+}
+
+// ChangeRentalStatusRequest - запрос на изменение статуса аренды
+type ChangeRentalStatusRequest struct {
+	Comment      *string    	`json:"comment"`       // Комментарий (например, причина отказа)
+}
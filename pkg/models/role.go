@@ -2,6 +2,43 @@ package models
 
 // Role - структура для роли пользователя
 type Role struct {
-	ID      	int     	`json:"id"`
-	Name      	string    	`json:"name"`
-}
\ No newline at end of file
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions,omitempty"` // Права роли
+}
+
+// Permission - право на выполнение действия
+type Permission struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`        // Код права
+	Description string `json:"description"` // Описание
+}
+
+// Коды прав. Права с суффиксами .own и .any действуют на собственные и на любые объекты соответственно.
+const (
+	PermissionFieldsCreate   = "fields.create"
+	PermissionFieldsUpdate   = "fields.update"
+	PermissionFieldsDelete   = "fields.delete"
+	PermissionRentalsCreate  = "rentals.create"
+	PermissionRentalsApprove = "rentals.approve"
+	PermissionRentalsCancel  = "rentals.cancel"
+	PermissionRentalsDelete  = "rentals.delete"
+	PermissionTeamsCreate    = "teams.create"
+	PermissionTeamsUpdate    = "teams.update"
+	PermissionTeamsDelete    = "teams.delete"
+	PermissionUsersView      = "users.view"
+	PermissionUsersManage    = "users.manage"
+	PermissionRolesManage    = "roles.manage"
+	PermissionMediaDelete    = "media.delete"
+	PermissionMediaAttach    = "media.attach"
+)
+
+// UpdateRolePermissionsRequest - запрос на изменение прав роли
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required"` // Коды прав
+}
+
+// UpdateUserRoleRequest - запрос на назначение роли пользователю
+type UpdateUserRoleRequest struct {
+	RoleID int `json:"role_id" validate:"required"` // Идентификатор роли
+}
@@ -0,0 +1,30 @@
+package models
+
+// FieldScheduleDay - часы работы площадки в день недели
+type FieldScheduleDay struct {
+	Weekday   int    `json:"weekday" validate:"min=1,max=7"` // День недели (1 - понедельник, 7 - воскресенье)
+	OpenTime  string `json:"open_time" validate:"required"`  // Время открытия (15:04)
+	CloseTime string `json:"close_time" validate:"required"` // Время закрытия (15:04, допускается 24:00)
+}
+
+// FieldScheduleException - исключение из расписания (праздник, обслуживание)
+type FieldScheduleException struct {
+	Date      string  `json:"date" validate:"required"` // Дата (2006-01-02)
+	IsClosed  bool    `json:"is_closed"`                // Площадка закрыта весь день
+	OpenTime  *string `json:"open_time"`                // Время открытия, если площадка работает
+	CloseTime *string `json:"close_time"`               // Время закрытия, если площадка работает
+	Comment   *string `json:"comment"`                  // Комментарий
+}
+
+// FieldSchedule - расписание работы площадки
+type FieldSchedule struct {
+	FieldID    int64                    `json:"field_id"`   // Идентификатор площадки
+	Days       []FieldScheduleDay       `json:"days"`       // Часы работы по дням недели
+	Exceptions []FieldScheduleException `json:"exceptions"` // Исключения
+}
+
+// UpdateFieldScheduleRequest - запрос на замену расписания площадки
+type UpdateFieldScheduleRequest struct {
+	Days       []FieldScheduleDay       `json:"days" validate:"dive"`       // Часы работы по дням недели
+	Exceptions []FieldScheduleException `json:"exceptions" validate:"dive"` // Исключения
+}
@@ -12,7 +12,6 @@ type Team struct {
 	Description     	*string 	   		`json:"description"`          		// Описание
 	City            	string 	   	   		`json:"city"`           			// Город
 	UniformColor    	*string 	   		`json:"uniform_color"`           	// Цвет формы
-	ParticipantCount 	*int  		   		`json:"participant_count"`    		// Кол-во участников
 	Responsible     	int64          		`json:"responsible"`          		// Ответственный
 	DisabilityCategory 	*string				`json:"disability_category"`  		// Категория инвалидности
 	Logo            	*string    			`json:"logo"`                 		// Логотип
@@ -30,7 +29,7 @@ type TeamView struct {
 	Description     	*string 	   		`json:"description"`          		// Описание
 	City            	string 	   	   		`json:"city"`           			// Город
 	UniformColor    	*string 	   		`json:"uniform_color"`           	// Цвет формы
-	ParticipantCount 	*int  		   		`json:"participant_count"`    		// Кол-во участников
+	ParticipantCount 	int  		   		`json:"participant_count"`    		// Кол-во участников (по составу команды)
 	Responsible     	UserView          	`json:"responsible"`          	// Ответственный
 	DisabilityCategory 	*string				`json:"disability_category"`  		// Категория инвалидности
 	Logo            	*Media    			`json:"logo"`                 		// Логотип
@@ -45,7 +44,6 @@ type CreateTeamRequest struct {
 	Description     	*string 	   		`json:"description, omitempty"`     		// Описание
 	City            	string         		`json:"city" validate:"required"`   		// Город
 	UniformColor    	*string 	   		`json:"uniform_color"`           			// Цвет формы
-	ParticipantCount 	*int  		   		`json:"participant_count"`       			// Кол-во участников
 	Logo            	*string    			`json:"logo"`                  				// Логотип
 	Media           	*json.RawMessage    `json:"media" swaggertype:"string"`                   			// Медиа
 }
@@ -56,7 +54,6 @@ type UpdateTeamRequest struct {
 	Description     	*string 	   		`json:"description, omitempty"`         	// Описание
 	City            	string         		`json:"city" validate:"required"`       	// Город
 	UniformColor    	*string 	   		`json:"uniform_color"`           			// Цвет формы
-	ParticipantCount 	*int  		   		`json:"participant_count"`       			// Кол-во участников
 	DisabilityCategory  *string 	   		`json:"disability_category"`     			// Категория инвалидности
 	Logo            	*string    			`json:"logo"`                    			// Логотип
 	Media           	*json.RawMessage    `json:"media" swaggertype:"string"`                   			// Медиа
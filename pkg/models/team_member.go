@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// Роли участников команды
+const (
+	TeamRoleCaptain = "captain" // Капитан
+	TeamRolePlayer  = "player"  // Игрок
+	TeamRoleReserve = "reserve" // Запасной
+)
+
+// Статусы приглашений в команду
+const (
+	TeamInvitationPending   = 1 // Ожидает ответа
+	TeamInvitationAccepted  = 2 // Принято
+	TeamInvitationDeclined  = 3 // Отклонено
+	TeamInvitationExpired   = 4 // Истекло
+	TeamInvitationCancelled = 5 // Отозвано
+)
+
+// TeamInvitationStatusNames - текстовые названия статусов приглашений
+var TeamInvitationStatusNames = map[int]string{
+	TeamInvitationPending:   "pending",
+	TeamInvitationAccepted:  "accepted",
+	TeamInvitationDeclined:  "declined",
+	TeamInvitationExpired:   "expired",
+	TeamInvitationCancelled: "cancelled",
+}
+
+// TeamMemberView - участник команды
+type TeamMemberView struct {
+	ID        int64     `json:"id"`
+	TeamID    int64     `json:"team_id"`    // Идентификатор команды
+	User      UserView  `json:"user"`       // Пользователь
+	Role      string    `json:"role"`       // Роль в команде
+	CreatedAt time.Time `json:"created_at"` // Дата вступления
+}
+
+// UpdateTeamMemberRequest - запрос на изменение роли участника.
+// Назначение капитаном передает ему управление командой.
+type UpdateTeamMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=captain player reserve"` // Роль в команде
+}
+
+// TeamInvitationView - приглашение в команду
+type TeamInvitationView struct {
+	ID          int64      `json:"id"`
+	Team        TeamView   `json:"team"`         // Команда
+	Email       *string    `json:"email"`        // Email приглашенного
+	Phone       *string    `json:"phone"`        // Телефон приглашенного
+	InviteeID   *int64     `json:"invitee_id"`   // Приглашенный пользователь, найденный по телефону
+	Role        string     `json:"role"`         // Роль в команде после принятия
+	Status      int        `json:"status"`       // Статус
+	StatusName  string     `json:"status_name"`  // Название статуса
+	InvitedBy   *int64     `json:"invited_by"`   // Пригласивший пользователь
+	UserID      *int64     `json:"user_id"`      // Пользователь, ответивший на приглашение
+	ExpiresAt   time.Time  `json:"expires_at"`   // Срок действия
+	RespondedAt *time.Time `json:"responded_at"` // Дата ответа
+	CreatedAt   time.Time  `json:"created_at"`   // Дата создания
+}
+
+// CreateTeamInvitationRequest - запрос на приглашение пользователя в команду по email или телефону.
+// Приглашение по email может принять владелец подтвержденного email, приглашение по телефону
+// адресуется зарегистрированному пользователю с этим телефоном.
+type CreateTeamInvitationRequest struct {
+	Email *string `json:"email" validate:"omitempty,email"`               // Email приглашенного
+	Phone *string `json:"phone" validate:"omitempty,min=6,max=20"`        // Телефон приглашенного
+	Role  string  `json:"role" validate:"omitempty,oneof=player reserve"` // Роль в команде, по умолчанию player
+}
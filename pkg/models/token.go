@@ -0,0 +1,14 @@
+package models
+
+// TokenPairResponse - пара токенов, выдаваемая при входе и обновлении
+type TokenPairResponse struct {
+	AccessToken  string `json:"access_token"`  // JWT токен доступа
+	RefreshToken string `json:"refresh_token"` // Токен обновления
+	TokenType    string `json:"token_type"`    // Тип токена доступа
+	ExpiresIn    int64  `json:"expires_in"`    // Время жизни токена доступа, сек
+}
+
+// RefreshTokenRequest - запрос на обновление пары токенов
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"` // Токен обновления
+}
@@ -6,6 +6,13 @@ import(
 	jwt "github.com/golang-jwt/jwt"
 )
 
+// Статусы пользователя
+const (
+	UserStatusUnverified = 0 // Email не подтвержден
+	UserStatusVerified   = 1 // Email подтвержден
+	UserStatusBlocked    = 2 // Заблокирован администратором
+)
+
 type User struct {
 	ID    		int64    				`json:"id"`
 	Name  		string 				`json:"name"`						// ФИО
@@ -58,6 +65,24 @@ type LoginUserRequest struct {
 
 // Claims содержит информацию, которую мы хотим включить в токен
 type Claims struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid"` // Сессия, к которой привязан токен
 	jwt.StandardClaims
-}
\ No newline at end of file
+}
+
+// UserSearchFilter - параметры фильтрации списка пользователей
+type UserSearchFilter struct {
+	Search *string `json:"search"`  // Поиск по ФИО, email, телефону и городу
+	Status *int    `json:"status"`  // Статус
+	RoleID *int    `json:"role_id"` // Роль
+}
+
+// AdminUpdateUserRequest - запрос администратора на изменение пользователя
+type AdminUpdateUserRequest struct {
+	ID     int64   `json:"-"`
+	Name   string  `json:"name" validate:"required,min=3,max=128"`
+	Email  string  `json:"email" validate:"required,email"`
+	Phone  *string `json:"phone" validate:"omitempty,min=6,max=20,phone"`
+	City   *string `json:"city" validate:"omitempty,max=128"`
+	RoleID *int    `json:"role_id" validate:"omitempty"` // Новая роль, если требуется её изменить
+}
@@ -0,0 +1,23 @@
+package models
+
+// Назначение одноразовых токенов пользователя
+const (
+	UserTokenPasswordReset     = "password_reset"     // Сброс пароля
+	UserTokenEmailVerification = "email_verification" // Подтверждение email
+)
+
+// ForgotPasswordRequest - запрос на отправку ссылки для сброса пароля
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"` // Email пользователя
+}
+
+// ResetPasswordRequest - запрос на установку нового пароля по токену из письма
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`                  // Токен из письма
+	Password string `json:"password" validate:"required,min=4,max=128"` // Новый пароль
+}
+
+// VerifyEmailRequest - запрос на подтверждение email по токену из письма
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"` // Токен из письма
+}
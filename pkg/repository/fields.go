@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// FieldViewColumns - колонки таблицы fields, необходимые для заполнения FieldView
+const FieldViewColumns = "id, name, slug, description, city, address, location, lat, lon, square, info, " +
+	"COALESCE(places, 0), COALESCE(dressing, false), COALESCE(toilet, false), COALESCE(display, false), " +
+	"COALESCE(parking, false), COALESCE(for_disabled, false), logo, media, responsible_id, status, created_at"
+
+// FieldLoader собирает площадки из результатов запроса и подгружает связанные данные пакетно
+type FieldLoader struct {
+	views []models.FieldView
+	refs  []entityRefs
+}
+
+// Scan считывает площадку из строки результата.
+// Строка должна содержать колонки FieldViewColumns, следующие за ними колонки считываются в extra.
+func (l *FieldLoader) Scan(row RowScanner, extra ...interface{}) error {
+	var fieldView models.FieldView
+	var refs entityRefs
+	var location []byte
+
+	dest := []interface{}{
+		&fieldView.ID,
+		&fieldView.Name,
+		&fieldView.Slug,
+		&fieldView.Description,
+		&fieldView.City,
+		&fieldView.Address,
+		&location,
+		&fieldView.Lat,
+		&fieldView.Lon,
+		&fieldView.Square,
+		&fieldView.Info,
+		&fieldView.Places,
+		&fieldView.Dressing,
+		&fieldView.Toilet,
+		&fieldView.Display,
+		&fieldView.Parking,
+		&fieldView.ForDisabled,
+		&refs.logo,
+		&refs.media,
+		&refs.responsibleID,
+		&fieldView.Status,
+		&fieldView.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if len(location) > 0 {
+		rawLocation := json.RawMessage(location)
+		fieldView.Location = &rawLocation
+	}
+
+	l.views = append(l.views, fieldView)
+	l.refs = append(l.refs, refs)
+	return nil
+}
+
+// Load подгружает ответственных, логотипы и медиа всех считанных площадок
+// и возвращает площадки в порядке считывания
+func (l *FieldLoader) Load(ctx context.Context) (error, []models.FieldView) {
+	views := []models.FieldView{}
+	if len(l.views) == 0 {
+		return nil, views
+	}
+
+	err, users, medias := loadEntityRefs(ctx, l.refs)
+	if err != nil {
+		return err, views
+	}
+
+	for i := range l.views {
+		l.refs[i].resolve(users, medias, &l.views[i].Responsible, &l.views[i].Logo, &l.views[i].Media)
+	}
+	return nil, append(views, l.views...)
+}
+
+// loadOneField считывает и загружает одну площадку
+func loadOneField(ctx context.Context, row RowScanner) (error, models.FieldView) {
+	var loader FieldLoader
+	if err := loader.Scan(row); err != nil {
+		return err, models.FieldView{}
+	}
+	err, views := loader.Load(ctx)
+	if err != nil {
+		return err, models.FieldView{}
+	}
+	return nil, views[0]
+}
+
+// FieldViewById получает площадку по ID. Удаленные площадки не возвращаются.
+func FieldViewById(ctx context.Context, id int64) (error, models.FieldView) {
+	return loadOneField(ctx, database.Conn(ctx).QueryRow("SELECT "+FieldViewColumns+" FROM fields WHERE id = $1 AND deleted_at IS NULL", id))
+}
+
+// FieldViewBySlug получает площадку по slug. Удаленные площадки не возвращаются.
+func FieldViewBySlug(ctx context.Context, slug string) (error, models.FieldView) {
+	return loadOneField(ctx, database.Conn(ctx).QueryRow("SELECT "+FieldViewColumns+" FROM fields WHERE slug = $1 AND deleted_at IS NULL", slug))
+}
+
+// FieldViewsByIds получает площадки по списку идентификаторов.
+// Удаленные площадки не возвращаются.
+func FieldViewsByIds(ctx context.Context, ids []int64) (error, map[int64]models.FieldView) {
+	fields := map[int64]models.FieldView{}
+	if len(ids) == 0 {
+		return nil, fields
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT "+FieldViewColumns+" FROM fields WHERE id = ANY($1) AND deleted_at IS NULL", pq.Array(ids))
+	if err != nil {
+		return err, fields
+	}
+	defer rows.Close()
+
+	var loader FieldLoader
+	for rows.Next() {
+		if err := loader.Scan(rows); err != nil {
+			return err, fields
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err, fields
+	}
+
+	err, views := loader.Load(ctx)
+	for _, fieldView := range views {
+		fields[fieldView.ID] = fieldView
+	}
+	return err, fields
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"goland_api/pkg/services/storage"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// mediaColumns - колонки таблицы medias, необходимые для заполнения Media
+const mediaColumns = "id, name, path, ext, mime_type, size, variants, user_id, created_at"
+
+// ScanMedia считывает медиафайл из строки результата запроса с колонками mediaColumns
+func ScanMedia(row RowScanner) (error, models.Media) {
+	var media models.Media
+	var variants []byte
+	err := row.Scan(
+		&media.ID,
+		&media.Name,
+		&media.Path,
+		&media.Ext,
+		&media.MimeType,
+		&media.Size,
+		&variants,
+		&media.UserID,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		return err, media
+	}
+	return json.Unmarshal(variants, &media.Variants), media
+}
+
+// WithURL заполняет ссылки на файл медиафайла и его производные изображения.
+// Медиафайл без ссылки остается доступным через API, поэтому ошибка только записывается в журнал.
+func WithURL(ctx context.Context, media models.Media) models.Media {
+	url, err := storage.URL(ctx, media.Path)
+	if err != nil {
+		log.Println("Ошибка при получении ссылки на файл", media.Name, err)
+		return media
+	}
+	media.URL = url
+
+	for name, variant := range media.Variants {
+		if url, err := storage.URL(ctx, variant.Key); err == nil {
+			variant.URL = url
+			media.Variants[name] = variant
+		}
+	}
+	return media
+}
+
+// MediaKeys возвращает ключи файла и его производных изображений в хранилище
+func MediaKeys(media models.Media) []string {
+	keys := []string{media.Path}
+	for _, variant := range media.Variants {
+		keys = append(keys, variant.Key)
+	}
+	return keys
+}
+
+// MediaByName получает медиафайл по имени
+func MediaByName(ctx context.Context, name string) (error, models.Media) {
+	err, media := ScanMedia(database.Conn(ctx).QueryRow("SELECT "+mediaColumns+" FROM medias WHERE name = $1", name))
+	if err != nil {
+		return err, media
+	}
+	return nil, WithURL(ctx, media)
+}
+
+// MediaById получает медиафайл по ID
+func MediaById(ctx context.Context, id int64) (error, models.Media) {
+	err, media := ScanMedia(database.Conn(ctx).QueryRow("SELECT "+mediaColumns+" FROM medias WHERE id = $1", id))
+	if err != nil {
+		return err, media
+	}
+	return nil, WithURL(ctx, media)
+}
+
+// MediasByNames получает медиафайлы по именам одним запросом.
+// Ненайденные имена в результат не попадают.
+func MediasByNames(ctx context.Context, names []string) (error, map[string]models.Media) {
+	medias := map[string]models.Media{}
+	if len(names) == 0 {
+		return nil, medias
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT "+mediaColumns+" FROM medias WHERE name = ANY($1)", pq.Array(names))
+	if err != nil {
+		return err, medias
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err, media := ScanMedia(rows)
+		if err != nil {
+			return err, medias
+		}
+		medias[media.Name] = WithURL(ctx, media)
+	}
+
+	return rows.Err(), medias
+}
+
+// UnattachedMedias получает медиафайлы, которые ни к чему не привязаны с момента before.
+// Для файлов, которые никогда не были привязаны, учитывается дата загрузки.
+func UnattachedMedias(ctx context.Context, before time.Time) (error, []models.Media) {
+	medias := []models.Media{}
+	rows, err := database.Conn(ctx).Query("SELECT "+mediaColumns+" FROM medias m "+
+		"WHERE COALESCE(m.detached_at, m.created_at) < $1 "+
+		"AND NOT EXISTS (SELECT 1 FROM media_attachments a WHERE a.media_id = m.id) ORDER BY m.id", before)
+	if err != nil {
+		return err, medias
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err, media := ScanMedia(rows)
+		if err != nil {
+			return err, medias
+		}
+		medias = append(medias, media)
+	}
+
+	return rows.Err(), medias
+}
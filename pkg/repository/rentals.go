@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+	"log"
+)
+
+// RentalViewColumns - колонки таблицы rentals, необходимые для заполнения RentalView.
+// Запрос должен выбирать из rentals r.
+const RentalViewColumns = "r.id, r.field_id, r.team_id, r.user_id, r.comment, r.start_date, r.end_date, r.duration, r.status, r.series_id, r.created_at"
+
+// rentalRefs - ссылки аренды на площадку, команду и пользователя
+type rentalRefs struct {
+	fieldID sql.NullInt64
+	teamID  sql.NullInt64
+	userID  sql.NullInt64
+}
+
+// RentalLoader собирает аренды из результатов запроса и подгружает площадки, команды и пользователей пакетно
+type RentalLoader struct {
+	views []models.RentalView
+	refs  []rentalRefs
+}
+
+// Scan считывает аренду из строки результата с колонками RentalViewColumns
+func (l *RentalLoader) Scan(row RowScanner) error {
+	var rentalView models.RentalView
+	var refs rentalRefs
+
+	err := row.Scan(
+		&rentalView.ID,
+		&refs.fieldID,
+		&refs.teamID,
+		&refs.userID,
+		&rentalView.Comment,
+		&rentalView.StartDate,
+		&rentalView.EndDate,
+		&rentalView.Duration,
+		&rentalView.Status,
+		&rentalView.SeriesID,
+		&rentalView.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rentalView.StatusName = models.RentalStatusNames[rentalView.Status]
+
+	l.views = append(l.views, rentalView)
+	l.refs = append(l.refs, refs)
+	return nil
+}
+
+// Load подгружает площадки, команды и пользователей всех считанных аренд
+// и возвращает аренды в порядке считывания
+func (l *RentalLoader) Load(ctx context.Context) (error, []models.RentalView) {
+	views := []models.RentalView{}
+	if len(l.views) == 0 {
+		return nil, views
+	}
+
+	var fieldIds, teamIds, userIds idSet
+	for _, refs := range l.refs {
+		fieldIds.add(refs.fieldID.Int64)
+		teamIds.add(refs.teamID.Int64)
+		userIds.add(refs.userID.Int64)
+	}
+
+	err, fields := FieldViewsByIds(ctx, fieldIds.ids)
+	if err != nil {
+		return err, views
+	}
+	err, teams := TeamViewsByIds(ctx, teamIds.ids)
+	if err != nil {
+		return err, views
+	}
+	err, users := UserViewsByIds(ctx, userIds.ids)
+	if err != nil {
+		return err, views
+	}
+
+	for i, refs := range l.refs {
+		rentalView := &l.views[i]
+		if refs.fieldID.Valid {
+			if fieldView, ok := fields[refs.fieldID.Int64]; ok {
+				rentalView.Field = fieldView
+			} else {
+				log.Println("Площадка аренды не найдена", rentalView.ID, refs.fieldID.Int64)
+			}
+		}
+		if refs.teamID.Valid {
+			if teamView, ok := teams[refs.teamID.Int64]; ok {
+				rentalView.Team = teamView
+			} else {
+				log.Println("Команда аренды не найдена", rentalView.ID, refs.teamID.Int64)
+			}
+		}
+		if refs.userID.Valid {
+			if userView, ok := users[refs.userID.Int64]; ok {
+				rentalView.User = userView
+			} else {
+				log.Println("Пользователь аренды не найден", rentalView.ID, refs.userID.Int64)
+			}
+		}
+	}
+	return nil, append(views, l.views...)
+}
+
+// RentalViewById получает аренду по ID. Удаленные аренды не возвращаются.
+func RentalViewById(ctx context.Context, id int64) (error, models.RentalView) {
+	var loader RentalLoader
+	row := database.Conn(ctx).QueryRow("SELECT "+RentalViewColumns+" FROM rentals r WHERE r.id = $1 AND r.deleted_at IS NULL", id)
+	if err := loader.Scan(row); err != nil {
+		return err, models.RentalView{}
+	}
+	err, views := loader.Load(ctx)
+	if err != nil {
+		return err, models.RentalView{}
+	}
+	return nil, views[0]
+}
+
+// RentalViewsBySeries получает аренды серии в порядке начала. Удаленные аренды не возвращаются.
+func RentalViewsBySeries(ctx context.Context, seriesId int64) (error, []models.RentalView) {
+	rows, err := database.Conn(ctx).Query("SELECT "+RentalViewColumns+" FROM rentals r WHERE r.series_id = $1 AND r.deleted_at IS NULL ORDER BY r.start_date", seriesId)
+	if err != nil {
+		return err, []models.RentalView{}
+	}
+	defer rows.Close()
+
+	var loader RentalLoader
+	for rows.Next() {
+		if err := loader.Scan(rows); err != nil {
+			return err, []models.RentalView{}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err, []models.RentalView{}
+	}
+
+	return loader.Load(ctx)
+}
@@ -0,0 +1,117 @@
+// Package repository загружает представления сущностей для ответов API.
+//
+// Связанные данные (ответственные, логотипы и медиафайлы, площадки и команды аренд)
+// подгружаются пакетно, одним запросом на каждый тип, поэтому количество запросов
+// к базе данных не зависит от количества записей на странице.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"goland_api/pkg/models"
+	"log"
+)
+
+// RowScanner - строка результата запроса (*sql.Row или *sql.Rows)
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// idSet собирает уникальные идентификаторы в порядке добавления
+type idSet struct {
+	ids  []int64
+	seen map[int64]bool
+}
+
+// add добавляет идентификатор, нулевые и повторные идентификаторы пропускаются
+func (s *idSet) add(id int64) {
+	if id == 0 || s.seen[id] {
+		return
+	}
+	if s.seen == nil {
+		s.seen = map[int64]bool{}
+	}
+	s.seen[id] = true
+	s.ids = append(s.ids, id)
+}
+
+// entityRefs - ссылки площадки или команды на ответственного пользователя и медиафайлы
+type entityRefs struct {
+	responsibleID sql.NullInt64
+	logo          sql.NullString
+	media         sql.NullString
+}
+
+// mediaNames возвращает имена файлов из колонки media
+func (r entityRefs) mediaNames() []string {
+	if !r.media.Valid || len(r.media.String) == 0 {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(r.media.String), &names); err != nil {
+		log.Println("Ошибка при парсинге JSON:", err)
+	}
+	return names
+}
+
+// loadEntityRefs одним запросом на тип получает ответственных пользователей и медиафайлы для списка ссылок
+func loadEntityRefs(ctx context.Context, refs []entityRefs) (error, map[int64]models.UserView, map[string]models.Media) {
+	var userIds idSet
+	var names []string
+	for _, ref := range refs {
+		if ref.responsibleID.Valid {
+			userIds.add(ref.responsibleID.Int64)
+		}
+		if ref.logo.Valid && ref.logo.String != "" {
+			names = append(names, ref.logo.String)
+		}
+		names = append(names, ref.mediaNames()...)
+	}
+
+	err, users := UserViewsByIds(ctx, userIds.ids)
+	if err != nil {
+		return err, nil, nil
+	}
+	err, medias := MediasByNames(ctx, names)
+	if err != nil {
+		return err, nil, nil
+	}
+	return nil, users, medias
+}
+
+// resolve заполняет ответственного, логотип и медиа по загруженным данным.
+// Ненайденные пользователи и файлы пропускаются с записью в журнал.
+func (r entityRefs) resolve(users map[int64]models.UserView, medias map[string]models.Media,
+	responsible *models.UserView, logo **models.Media, media **[]models.Media) {
+	if r.responsibleID.Valid {
+		if userView, ok := users[r.responsibleID.Int64]; ok {
+			*responsible = userView
+		} else {
+			log.Println("Ответственный пользователь не найден", r.responsibleID.Int64)
+		}
+	}
+
+	if r.logo.Valid && r.logo.String != "" {
+		if logoFile, ok := medias[r.logo.String]; ok {
+			*logo = &logoFile
+		} else {
+			log.Println("Ошибка в Logo: файл не найден", r.logo.String)
+		}
+	}
+
+	if r.media.Valid && len(r.media.String) > 0 {
+		var mediaList []models.Media
+		for _, name := range r.mediaNames() {
+			if name == "" {
+				continue
+			}
+			if mediaFile, ok := medias[name]; ok {
+				mediaList = append(mediaList, mediaFile)
+			} else {
+				log.Println("Ошибка в Media: файл не найден", name)
+			}
+		}
+		*media = &mediaList
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// teamParticipantCountColumn - количество участников, вычисляемое по составу команды
+const teamParticipantCountColumn = "(SELECT COUNT(*) FROM team_members tm JOIN users u ON u.id = tm.user_id WHERE tm.team_id = teams.id AND u.deleted_at IS NULL)"
+
+// TeamViewColumns - колонки таблицы teams, необходимые для заполнения TeamView.
+// Запрос должен выбирать из teams без псевдонима.
+const TeamViewColumns = "id, name, description, city, uniform_color, " + teamParticipantCountColumn + ", responsible_id, logo, media, status, created_at"
+
+// TeamLoader собирает команды из результатов запроса и подгружает связанные данные пакетно
+type TeamLoader struct {
+	views []models.TeamView
+	refs  []entityRefs
+}
+
+// Scan считывает команду из строки результата с колонками TeamViewColumns
+func (l *TeamLoader) Scan(row RowScanner) error {
+	var teamView models.TeamView
+	var refs entityRefs
+
+	err := row.Scan(
+		&teamView.ID,
+		&teamView.Name,
+		&teamView.Description,
+		&teamView.City,
+		&teamView.UniformColor,
+		&teamView.ParticipantCount,
+		&refs.responsibleID,
+		&refs.logo,
+		&refs.media,
+		&teamView.Status,
+		&teamView.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	l.views = append(l.views, teamView)
+	l.refs = append(l.refs, refs)
+	return nil
+}
+
+// Load подгружает ответственных, логотипы и медиа всех считанных команд
+// и возвращает команды в порядке считывания
+func (l *TeamLoader) Load(ctx context.Context) (error, []models.TeamView) {
+	views := []models.TeamView{}
+	if len(l.views) == 0 {
+		return nil, views
+	}
+
+	err, users, medias := loadEntityRefs(ctx, l.refs)
+	if err != nil {
+		return err, views
+	}
+
+	for i := range l.views {
+		l.refs[i].resolve(users, medias, &l.views[i].Responsible, &l.views[i].Logo, &l.views[i].Media)
+	}
+	return nil, append(views, l.views...)
+}
+
+// TeamViewById получает команду по ID. Удаленные команды не возвращаются.
+func TeamViewById(ctx context.Context, id int64) (error, models.TeamView) {
+	var loader TeamLoader
+	row := database.Conn(ctx).QueryRow("SELECT "+TeamViewColumns+" FROM teams WHERE id = $1 AND deleted_at IS NULL", id)
+	if err := loader.Scan(row); err != nil {
+		return err, models.TeamView{}
+	}
+	err, views := loader.Load(ctx)
+	if err != nil {
+		return err, models.TeamView{}
+	}
+	return nil, views[0]
+}
+
+// TeamViewsByIds получает команды по списку идентификаторов.
+// Удаленные команды не возвращаются.
+func TeamViewsByIds(ctx context.Context, ids []int64) (error, map[int64]models.TeamView) {
+	teams := map[int64]models.TeamView{}
+	if len(ids) == 0 {
+		return nil, teams
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT "+TeamViewColumns+" FROM teams WHERE id = ANY($1) AND deleted_at IS NULL", pq.Array(ids))
+	if err != nil {
+		return err, teams
+	}
+	defer rows.Close()
+
+	var loader TeamLoader
+	for rows.Next() {
+		if err := loader.Scan(rows); err != nil {
+			return err, teams
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err, teams
+	}
+
+	err, views := loader.Load(ctx)
+	for _, teamView := range views {
+		teams[teamView.ID] = teamView
+	}
+	return err, teams
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"goland_api/pkg/database"
+	"goland_api/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// UserViewColumns - колонки запроса пользователя с ролью, необходимые для заполнения UserView.
+// Запрос должен выбирать из users u с присоединенной roles r.
+const UserViewColumns = "u.id, u.name, u.email, u.phone, u.city, u.logo, u.media, u.status, u.created_at, r.id, r.name"
+
+// ScanUserView читает пользователя из строки результата запроса с колонками UserViewColumns
+func ScanUserView(row RowScanner) (error, models.UserView) {
+	var userView models.UserView
+	err := row.Scan(
+		&userView.ID,
+		&userView.Name,
+		&userView.Email,
+		&userView.Phone,
+		&userView.City,
+		&userView.Logo,
+		&userView.Media,
+		&userView.Status,
+		&userView.CreatedAt,
+		&userView.Role.ID,
+		&userView.Role.Name,
+	)
+	return err, userView
+}
+
+// UserViewsByIds получает пользователей по списку идентификаторов одним запросом.
+// Удаленные пользователи не возвращаются.
+func UserViewsByIds(ctx context.Context, ids []int64) (error, map[int64]models.UserView) {
+	users := map[int64]models.UserView{}
+	if len(ids) == 0 {
+		return nil, users
+	}
+
+	rows, err := database.Conn(ctx).Query("SELECT "+UserViewColumns+" FROM users u "+
+		"join roles r on r.id = u.role_id "+
+		"WHERE u.id = ANY($1) AND u.deleted_at IS NULL", pq.Array(ids))
+	if err != nil {
+		return err, users
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err, userView := ScanUserView(rows)
+		if err != nil {
+			return err, users
+		}
+		users[userView.ID] = userView
+	}
+
+	return rows.Err(), users
+}
@@ -3,11 +3,13 @@ package dadata
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"goland_api/pkg/models"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 )
 
 // DadataResponse represents the actual structure of Dadata API response
@@ -86,3 +88,30 @@ func Suggest(requestBody []byte) (models.AddressResponse, error) {
 
 	return addressResponse, nil
 }
+
+// Geocode определяет координаты адреса по первой подсказке Dadata
+func Geocode(address string) (float64, float64, error) {
+	requestBody, err := json.Marshal(models.AddressRequest{Query: address, Count: 1})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	addressResponse, err := Suggest(requestBody)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, suggestion := range addressResponse.Suggestions {
+		if suggestion.Geo.Lat == "" || suggestion.Geo.Lon == "" {
+			continue
+		}
+		lat, errLat := strconv.ParseFloat(suggestion.Geo.Lat, 64)
+		lon, errLon := strconv.ParseFloat(suggestion.Geo.Lon, 64)
+		if errLat != nil || errLon != nil {
+			continue
+		}
+		return lat, lon, nil
+	}
+
+	return 0, 0, fmt.Errorf("не удалось определить координаты адреса: %s", address)
+}
@@ -0,0 +1,206 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chai2010/webp"
+	"github.com/disintegration/imaging"
+)
+
+// ErrTooLarge - изображение содержит слишком много пикселей для обработки
+var ErrTooLarge = errors.New("Изображение слишком большое")
+
+// Максимальное количество пикселей изображения, которое декодируется в память
+const maxPixels = 50_000_000
+
+// Variant - размер производного изображения
+type Variant struct {
+	Name    string // Имя варианта, например thumb
+	MaxSide int    // Наибольшая сторона изображения в пикселях
+}
+
+// Image - закодированное изображение
+type Image struct {
+	Data     []byte
+	MimeType string
+	Width    int
+	Height   int
+}
+
+// Variants - размеры производных изображений, настраиваются в Init
+var Variants = []Variant{
+	{Name: "thumb", MaxSide: 200},
+	{Name: "medium", MaxSide: 800},
+	{Name: "large", MaxSide: 1600},
+}
+
+// Quality - качество сжатия JPEG и WebP от 1 до 100
+var Quality = 85
+
+// WebPSuffix добавляется к имени варианта в формате WebP
+const WebPSuffix = "_webp"
+
+// Init настраивает размеры производных изображений по переменной окружения MEDIA_IMAGE_VARIANTS
+// в формате "thumb:200,medium:800,large:1600" и качество сжатия по переменной MEDIA_IMAGE_QUALITY.
+func Init() {
+	if value := os.Getenv("MEDIA_IMAGE_VARIANTS"); value != "" {
+		var variants []Variant
+		for _, item := range strings.Split(value, ",") {
+			parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+			if len(parts) != 2 {
+				log.Fatal("Invalid MEDIA_IMAGE_VARIANTS: ", value)
+			}
+			side, err := strconv.Atoi(parts[1])
+			if err != nil || side <= 0 || parts[0] == "" {
+				log.Fatal("Invalid MEDIA_IMAGE_VARIANTS: ", value)
+			}
+			variants = append(variants, Variant{Name: parts[0], MaxSide: side})
+		}
+		Variants = variants
+	}
+
+	if value := os.Getenv("MEDIA_IMAGE_QUALITY"); value != "" {
+		quality, err := strconv.Atoi(value)
+		if err != nil || quality < 1 || quality > 100 {
+			log.Fatal("Invalid MEDIA_IMAGE_QUALITY: ", value)
+		}
+		Quality = quality
+	}
+}
+
+// Process готовит загруженное изображение к хранению.
+// Возвращает оригинал без метаданных (EXIF, GPS и т.п.), повернутый согласно ориентации из EXIF,
+// и производные изображения для каждого размера из Variants в формате оригинала и в WebP.
+// Варианты, не меньшие оригинала, не создаются. Анимированный GIF сохраняется как есть,
+// варианты для него строятся по первому кадру.
+func Process(data []byte, mimeType string) (Image, map[string]Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, nil, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return Image{}, nil, ErrTooLarge
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return Image{}, nil, err
+	}
+	bounds := img.Bounds()
+
+	var original Image
+	switch mimeType {
+	case "image/jpeg", "image/png":
+		// Повторное кодирование отбрасывает все метаданные исходного файла
+		original, err = encode(img, mimeType)
+		if err != nil {
+			return Image{}, nil, err
+		}
+	case "image/webp":
+		stripped, err := stripWebPMetadata(data)
+		if err != nil {
+			return Image{}, nil, err
+		}
+		original = Image{Data: stripped, MimeType: mimeType, Width: bounds.Dx(), Height: bounds.Dy()}
+	default:
+		original = Image{Data: data, MimeType: mimeType, Width: bounds.Dx(), Height: bounds.Dy()}
+	}
+
+	// Варианты JPEG остаются в JPEG, остальные форматы сохраняются в PNG, чтобы не потерять прозрачность
+	variantType := "image/png"
+	if mimeType == "image/jpeg" {
+		variantType = "image/jpeg"
+	}
+
+	variants := map[string]Image{}
+	for _, variant := range Variants {
+		if bounds.Dx() <= variant.MaxSide && bounds.Dy() <= variant.MaxSide {
+			continue
+		}
+		resized := imaging.Fit(img, variant.MaxSide, variant.MaxSide, imaging.Lanczos)
+
+		encoded, err := encode(resized, variantType)
+		if err != nil {
+			return Image{}, nil, err
+		}
+		variants[variant.Name] = encoded
+
+		encoded, err = encode(resized, "image/webp")
+		if err != nil {
+			return Image{}, nil, err
+		}
+		variants[variant.Name+WebPSuffix] = encoded
+	}
+
+	return original, variants, nil
+}
+
+// encode кодирует изображение в формат mimeType
+func encode(img image.Image, mimeType string) (Image, error) {
+	var buf bytes.Buffer
+	var err error
+	switch mimeType {
+	case "image/jpeg":
+		err = imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(Quality))
+	case "image/png":
+		err = imaging.Encode(&buf, img, imaging.PNG)
+	case "image/webp":
+		err = webp.Encode(&buf, img, &webp.Options{Quality: float32(Quality)})
+	default:
+		err = fmt.Errorf("unsupported image type %s", mimeType)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	bounds := img.Bounds()
+	return Image{Data: buf.Bytes(), MimeType: mimeType, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// stripWebPMetadata удаляет из файла WebP блоки EXIF и XMP, не перекодируя изображение
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("invalid WebP file")
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		chunkType := string(data[pos : pos+4])
+		size := int(uint32(data[pos+4]) | uint32(data[pos+5])<<8 | uint32(data[pos+6])<<16 | uint32(data[pos+7])<<24)
+		// Размер блока выравнивается до четного
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		switch chunkType {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[pos:end]...)
+			if len(chunk) > 8 {
+				// Снимаем флаги наличия EXIF (0x08) и XMP (0x04)
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	riffSize := uint32(len(out) - 8)
+	out[4], out[5], out[6], out[7] = byte(riffSize), byte(riffSize>>8), byte(riffSize>>16), byte(riffSize>>24)
+	return out, nil
+}
@@ -0,0 +1,275 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/chai2010/webp"
+)
+
+// testImage возвращает непрозрачное изображение размером width x height
+func testImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// exifSegment возвращает сегмент APP1 с EXIF: ориентация 6 (поворот на 90°) и GPS-координаты
+func exifSegment() []byte {
+	var tiff bytes.Buffer
+	le := binary.LittleEndian
+	tiff.WriteString("II*\x00")
+	binary.Write(&tiff, le, uint32(8))
+	// IFD0: ориентация и ссылка на GPS IFD
+	binary.Write(&tiff, le, uint16(2))
+	binary.Write(&tiff, le, []uint16{0x0112, 3})
+	binary.Write(&tiff, le, []uint32{1, 6})
+	binary.Write(&tiff, le, []uint16{0x8825, 4})
+	binary.Write(&tiff, le, []uint32{1, 38})
+	binary.Write(&tiff, le, uint32(0))
+	// GPS IFD: северная широта
+	binary.Write(&tiff, le, uint16(1))
+	binary.Write(&tiff, le, []uint16{0x0001, 2})
+	binary.Write(&tiff, le, uint32(2))
+	tiff.WriteString("N\x00\x00\x00")
+	binary.Write(&tiff, le, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegMarkers возвращает маркеры сегментов JPEG до начала сжатых данных
+func jpegMarkers(t *testing.T, data []byte) []byte {
+	t.Helper()
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		t.Fatal("not a JPEG file")
+	}
+	var markers []byte
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			t.Fatalf("invalid JPEG segment at %d", pos)
+		}
+		marker := data[pos+1]
+		markers = append(markers, marker)
+		if marker == 0xDA {
+			break
+		}
+		pos += 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+	}
+	return markers
+}
+
+func TestProcessStripsJPEGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 30), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := append(append(append([]byte{}, buf.Bytes()[:2]...), exifSegment()...), buf.Bytes()[2:]...)
+	if !bytes.Contains(jpegMarkers(t, data), []byte{0xE1}) {
+		t.Fatal("test file has no APP1 segment")
+	}
+
+	original, _, err := Process(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(jpegMarkers(t, original.Data), []byte{0xE1}) {
+		t.Fatal("APP1 segment is kept")
+	}
+	if bytes.Contains(original.Data, []byte("Exif")) {
+		t.Fatal("EXIF data is kept")
+	}
+	// Ориентация из EXIF применяется к изображению
+	if original.Width != 30 || original.Height != 40 || original.MimeType != "image/jpeg" {
+		t.Fatalf("original = %s %dx%d, want image/jpeg 30x40", original.MimeType, original.Width, original.Height)
+	}
+}
+
+// webpChunk возвращает блок RIFF с выравниванием до четного размера
+func webpChunk(chunkType string, payload []byte) []byte {
+	chunk := append([]byte(chunkType), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// webpChunks возвращает типы блоков файла WebP и проверяет размер RIFF
+func webpChunks(t *testing.T, data []byte) []string {
+	t.Helper()
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		t.Fatal("not a WebP file")
+	}
+	if size := int(binary.LittleEndian.Uint32(data[4:8])); size != len(data)-8 {
+		t.Fatalf("RIFF size = %d, want %d", size, len(data)-8)
+	}
+	var chunks []string
+	for pos := 12; pos+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		chunks = append(chunks, string(data[pos:pos+4]))
+		pos += 8 + size + size%2
+	}
+	return chunks
+}
+
+func TestProcessStripsWebPMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := webp.Encode(&buf, testImage(40, 30), &webp.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	simple := buf.Bytes()
+
+	// Расширенный формат: VP8X с флагами EXIF и XMP, данные изображения, EXIF и XMP нечетной длины
+	vp8x := []byte{0x08 | 0x04, 0, 0, 0}
+	vp8x = append(vp8x, 39, 0, 0, 29, 0, 0)
+	body := []byte("WEBP")
+	body = append(body, webpChunk("VP8X", vp8x)...)
+	body = append(body, simple[12:]...)
+	body = append(body, webpChunk("EXIF", exifSegment()[10:])...)
+	body = append(body, webpChunk("XMP ", []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><gps>55.75,37.61</gps></x:xmpmeta>`))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	original, _, err := Process(data, "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := webpChunks(t, original.Data)
+	if len(chunks) != 2 || chunks[0] != "VP8X" || chunks[1] != string(simple[12:16]) {
+		t.Fatalf("chunks = %q, want VP8X and image data", chunks)
+	}
+	if flags := original.Data[20]; flags&(0x08|0x04) != 0 {
+		t.Fatalf("VP8X flags = %#x, EXIF and XMP flags are not cleared", flags)
+	}
+	config, err := webp.DecodeConfig(bytes.NewReader(original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 40 || config.Height != 30 {
+		t.Fatalf("decoded size = %dx%d, want 40x30", config.Width, config.Height)
+	}
+}
+
+func TestProcessVariants(t *testing.T) {
+	previous := Variants
+	Variants = []Variant{{Name: "small", MaxSide: 16}, {Name: "wide", MaxSide: 50}, {Name: "big", MaxSide: 200}}
+	t.Cleanup(func() {
+		Variants = previous
+	})
+
+	cases := []struct {
+		name        string
+		mimeType    string
+		variantType string
+	}{
+		{"png", "image/png", "image/png"},
+		{"jpeg", "image/jpeg", "image/jpeg"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var err error
+			if tc.mimeType == "image/png" {
+				err = png.Encode(&buf, testImage(64, 32))
+			} else {
+				err = jpeg.Encode(&buf, testImage(64, 32), nil)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			original, variants, err := Process(buf.Bytes(), tc.mimeType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if original.Width != 64 || original.Height != 32 {
+				t.Fatalf("original = %dx%d, want 64x32", original.Width, original.Height)
+			}
+
+			// Вариант big не меньше оригинала и не создается
+			want := map[string]struct {
+				mimeType      string
+				width, height int
+			}{
+				"small":              {tc.variantType, 16, 8},
+				"small" + WebPSuffix: {"image/webp", 16, 8},
+				"wide":               {tc.variantType, 50, 25},
+				"wide" + WebPSuffix:  {"image/webp", 50, 25},
+			}
+			if len(variants) != len(want) {
+				t.Fatalf("variants = %d, want %d", len(variants), len(want))
+			}
+			for name, expected := range want {
+				variant, ok := variants[name]
+				if !ok {
+					t.Fatalf("variant %s is missing", name)
+				}
+				config, format, err := image.DecodeConfig(bytes.NewReader(variant.Data))
+				if err != nil {
+					t.Fatalf("variant %s: %v", name, err)
+				}
+				if variant.MimeType != expected.mimeType || "image/"+format != expected.mimeType {
+					t.Fatalf("variant %s: type = %s (%s), want %s", name, variant.MimeType, format, expected.mimeType)
+				}
+				if variant.Width != expected.width || variant.Height != expected.height ||
+					config.Width != expected.width || config.Height != expected.height {
+					t.Fatalf("variant %s: size = %dx%d (%dx%d), want %dx%d", name,
+						variant.Width, variant.Height, config.Width, config.Height, expected.width, expected.height)
+				}
+			}
+		})
+	}
+}
+
+// pngHeader возвращает начало файла PNG с заголовком IHDR размером width x height без данных изображения
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+// Изображение, заголовок которого заявляет слишком много пикселей, не декодируется
+func TestProcessRejectsTooManyPixels(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		mimeType string
+	}{
+		{"png", pngHeader(10000, 10000), "image/png"},
+		{"gif", []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00"), "image/gif"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := Process(tc.data, tc.mimeType); !errors.Is(err, ErrTooLarge) {
+				t.Fatalf("err = %v, want ErrTooLarge", err)
+			}
+		})
+	}
+
+	// Заголовок в пределах ограничения проверяется декодированием
+	if _, _, err := Process(pngHeader(100, 100), "image/png"); err == nil || errors.Is(err, ErrTooLarge) {
+		t.Fatalf("err = %v, want decode error", err)
+	}
+}
@@ -0,0 +1,244 @@
+package loginguard
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Policy - правила ограничения неудачных попыток входа для одного счетчика
+type Policy struct {
+	FreeAttempts     int           // Количество попыток без задержки
+	BaseDelay        time.Duration // Задержка после первой попытки сверх бесплатных, далее удваивается
+	MaxDelay         time.Duration // Максимальная задержка
+	LockoutThreshold int           // Количество попыток, после которого счетчик блокируется
+	LockoutDuration  time.Duration // Длительность блокировки
+	ResetAfter       time.Duration // Через сколько после последней неудачной попытки счетчик обнуляется
+}
+
+// delay возвращает время, на которое блокируется вход после failures неудачных попыток
+func (p Policy) delay(failures int) time.Duration {
+	if p.LockoutThreshold > 0 && failures >= p.LockoutThreshold {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(failures-p.FreeAttempts-1)))
+	if delay <= 0 || delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// counter - состояние счетчика попыток входа
+type counter struct {
+	failures    int       // Количество попыток подряд без успешного входа
+	lastFailure time.Time // Дата последней попытки
+	lockedUntil time.Time // До какого момента попытки запрещены
+}
+
+// reserve учитывает попытку по счетчику c до проверки пароля и сразу запрещает следующие попытки
+// на время задержки, как если бы эта попытка оказалась неудачной.
+// Если попытки запрещены, счетчик не меняется и возвращается оставшееся время ожидания.
+func (p Policy) reserve(c *counter, now time.Time) time.Duration {
+	if c.lockedUntil.After(now) {
+		return c.lockedUntil.Sub(now)
+	}
+	if now.Sub(c.lastFailure) > p.ResetAfter {
+		c.failures = 0
+	}
+	c.failures++
+	c.lastFailure = now
+	if delay := p.delay(c.failures); delay > 0 {
+		c.lockedUntil = now.Add(delay)
+	}
+	return 0
+}
+
+// expiresAt возвращает момент, после которого счетчик c можно удалить:
+// блокировка снята и счетчик обнулится при следующей попытке
+func (p Policy) expiresAt(c counter) time.Time {
+	expiresAt := c.lastFailure.Add(p.ResetAfter)
+	if c.lockedUntil.After(expiresAt) {
+		return c.lockedUntil
+	}
+	return expiresAt
+}
+
+// release отменяет попытку, учтенную reserve, и задержку, которую она добавила
+func (p Policy) release(c *counter) {
+	if c.failures > 0 {
+		c.failures--
+	}
+	c.lockedUntil = time.Time{}
+	if delay := p.delay(c.failures); delay > 0 {
+		c.lockedUntil = c.lastFailure.Add(delay)
+	}
+}
+
+// Store - хранилище счетчиков попыток входа
+type Store interface {
+	// Reserve атомарно проверяет, разрешены ли попытки по счетчику key, и учитывает попытку по правилам policy.
+	// Если попытки запрещены, счетчик не меняется и возвращается оставшееся время ожидания.
+	Reserve(ctx context.Context, key string, now time.Time, policy Policy) (time.Duration, int, error)
+	// Release отменяет попытку, учтенную Reserve
+	Release(ctx context.Context, key string, policy Policy) error
+	// Reset удаляет счетчик key
+	Reset(ctx context.Context, key string) error
+}
+
+// Guard ограничивает попытки входа и запросы на восстановление пароля по учетной записи и по IP-адресу
+type Guard struct {
+	Store        Store
+	Account      Policy
+	IP           Policy
+	ResetAccount Policy // Запросы на восстановление пароля по email
+	ResetIP      Policy // Запросы на восстановление пароля по IP-адресу
+}
+
+// Result - результат учета попытки входа
+type Result struct {
+	RetryAfter  time.Duration // Если больше нуля, попытка запрещена и не учтена: через сколько можно повторить
+	Failures    int           // Количество неудачных попыток по учетной записи, если и эта попытка неудачна
+	Locked      bool          // Учетная запись будет заблокирована, если эта попытка неудачна
+	LockedUntil time.Time     // До какого момента учетная запись будет заблокирована
+}
+
+// Default - глобальный ограничитель попыток входа, настраивается в Init
+var Default = New(NewMemoryStore())
+
+// New создает ограничитель с правилами по умолчанию.
+// По учетной записи: 3 попытки без задержки, далее задержка от 1 секунды с удвоением,
+// после 10 попыток блокировка на 30 минут. По IP-адресу ограничения мягче, т.к. за одним адресом может быть много пользователей.
+// Письма для восстановления пароля: 2 письма на email без задержки, далее задержка от 1 минуты с удвоением до часа,
+// с одного IP-адреса - 10 запросов без задержки.
+func New(store Store) *Guard {
+	return &Guard{
+		Store: store,
+		Account: Policy{
+			FreeAttempts:     3,
+			BaseDelay:        time.Second,
+			MaxDelay:         5 * time.Minute,
+			LockoutThreshold: 10,
+			LockoutDuration:  30 * time.Minute,
+			ResetAfter:       time.Hour,
+		},
+		IP: Policy{
+			FreeAttempts:     20,
+			BaseDelay:        time.Second,
+			MaxDelay:         5 * time.Minute,
+			LockoutThreshold: 100,
+			LockoutDuration:  time.Hour,
+			ResetAfter:       time.Hour,
+		},
+		ResetAccount: Policy{
+			FreeAttempts: 2,
+			BaseDelay:    time.Minute,
+			MaxDelay:     time.Hour,
+			ResetAfter:   24 * time.Hour,
+		},
+		ResetIP: Policy{
+			FreeAttempts: 10,
+			BaseDelay:    time.Minute,
+			MaxDelay:     time.Hour,
+			ResetAfter:   time.Hour,
+		},
+	}
+}
+
+// Init выбирает хранилище счетчиков по переменной окружения LOGIN_GUARD_STORE:
+// postgres - таблица login_attempts (счетчики общие для всех экземпляров приложения), memory - память процесса (по умолчанию).
+func Init(db *sql.DB) {
+	switch os.Getenv("LOGIN_GUARD_STORE") {
+	case "postgres":
+		Default = New(NewPostgresStore(db))
+		log.Println("Счетчики попыток входа хранятся в Postgres")
+	default:
+		Default = New(NewMemoryStore())
+		log.Println("Счетчики попыток входа хранятся в памяти")
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func resetAccountKey(email string) string {
+	return "reset-" + accountKey(email)
+}
+
+func resetIPKey(ip string) string {
+	return "reset-" + ipKey(ip)
+}
+
+// reserve учитывает попытку по счетчику учетной записи, затем по счетчику IP-адреса.
+// Если попытка запрещена по IP-адресу, она не учитывается и по учетной записи.
+func (g *Guard) reserve(ctx context.Context, account string, accountPolicy Policy, ip string, ipPolicy Policy, now time.Time) (time.Duration, int, error) {
+	wait, failures, err := g.Store.Reserve(ctx, account, now, accountPolicy)
+	if err != nil || wait > 0 {
+		return wait, failures, err
+	}
+
+	wait, _, err = g.Store.Reserve(ctx, ip, now, ipPolicy)
+	if err != nil || wait > 0 {
+		if errRelease := g.Store.Release(ctx, account, accountPolicy); errRelease != nil && err == nil {
+			err = errRelease
+		}
+		return wait, failures, err
+	}
+	return 0, failures, nil
+}
+
+// Reserve учитывает попытку входа до проверки пароля. Счетчики учетной записи и IP-адреса
+// проверяются и увеличиваются хранилищем атомарно, поэтому параллельные запросы не обходят задержку.
+// Учтенная попытка считается неудачной, пока не отменена вызовом Succeed или Release.
+func (g *Guard) Reserve(ctx context.Context, email string, ip string, now time.Time) (Result, error) {
+	var result Result
+
+	wait, failures, err := g.reserve(ctx, accountKey(email), g.Account, ipKey(ip), g.IP, now)
+	if err != nil || wait > 0 {
+		result.RetryAfter = wait
+		return result, err
+	}
+
+	result.Failures = failures
+	result.Locked = failures == g.Account.LockoutThreshold
+	if result.Locked {
+		result.LockedUntil = now.Add(g.Account.LockoutDuration)
+	}
+	return result, nil
+}
+
+// Release отменяет попытку, учтенную Reserve, если пароль не удалось проверить
+func (g *Guard) Release(ctx context.Context, email string, ip string) error {
+	if err := g.Store.Release(ctx, accountKey(email), g.Account); err != nil {
+		return err
+	}
+	return g.Store.Release(ctx, ipKey(ip), g.IP)
+}
+
+// Succeed сбрасывает счетчик учетной записи после успешного входа и отменяет попытку по IP-адресу.
+// Остальные попытки по IP-адресу не сбрасываются, иначе их можно обнулять входом в собственную учетную запись.
+func (g *Guard) Succeed(ctx context.Context, email string, ip string) error {
+	if err := g.Store.Reset(ctx, accountKey(email)); err != nil {
+		return err
+	}
+	return g.Store.Release(ctx, ipKey(ip), g.IP)
+}
+
+// ReservePasswordReset учитывает запрос на восстановление пароля для email с IP-адреса ip.
+// Запросы считаются по email, а не по пользователю, поэтому ограничение не выдает, зарегистрирован ли email.
+// Если запрос запрещен, возвращается время, через которое его можно повторить.
+func (g *Guard) ReservePasswordReset(ctx context.Context, email string, ip string, now time.Time) (time.Duration, error) {
+	wait, _, err := g.reserve(ctx, resetAccountKey(email), g.ResetAccount, resetIPKey(ip), g.ResetIP, now)
+	return wait, err
+}
@@ -0,0 +1,112 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Параллельные попытки входа не должны проходить мимо задержки
+func TestReserveConcurrentAttempts(t *testing.T) {
+	guard := New(NewMemoryStore())
+	ctx := context.Background()
+	now := time.Now()
+
+	var admitted int64
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := guard.Reserve(ctx, "user@example.com", "203.0.113.5", now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.RetryAfter == 0 {
+				atomic.AddInt64(&admitted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Попытки без задержки и первая попытка, после которой задержка начинает действовать
+	if want := int64(guard.Account.FreeAttempts + 1); admitted != want {
+		t.Fatalf("admitted = %d, want %d", admitted, want)
+	}
+}
+
+func TestSucceedReleasesAttempt(t *testing.T) {
+	guard := New(NewMemoryStore())
+	ctx := context.Background()
+	now := time.Now()
+
+	// Успешные входы с одного адреса не накапливают задержку
+	for i := 0; i < guard.IP.FreeAttempts*2; i++ {
+		result, err := guard.Reserve(ctx, "user@example.com", "203.0.113.5", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RetryAfter > 0 {
+			t.Fatalf("attempt %d: retry after %s", i+1, result.RetryAfter)
+		}
+		if err := guard.Succeed(ctx, "user@example.com", "203.0.113.5"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReserveLocksAccount(t *testing.T) {
+	guard := New(NewMemoryStore())
+	ctx := context.Background()
+	now := time.Now()
+
+	var result Result
+	for failures := 1; failures <= guard.Account.LockoutThreshold; failures++ {
+		var err error
+		// Каждая следующая попытка - после окончания задержки предыдущей
+		result, err = guard.Reserve(ctx, "user@example.com", "203.0.113.5", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RetryAfter > 0 {
+			t.Fatalf("attempt %d: retry after %s", failures, result.RetryAfter)
+		}
+		now = now.Add(guard.Account.MaxDelay)
+	}
+	if !result.Locked || result.Failures != guard.Account.LockoutThreshold {
+		t.Fatalf("result = %+v, want lockout after %d failures", result, guard.Account.LockoutThreshold)
+	}
+
+	result, err := guard.Reserve(ctx, "user@example.com", "203.0.113.5", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := guard.Account.LockoutDuration - guard.Account.MaxDelay; result.RetryAfter != want {
+		t.Fatalf("retry after %s, want %s", result.RetryAfter, want)
+	}
+}
+
+// Запросы на восстановление пароля не задерживают вход и наоборот
+func TestPasswordResetCountersAreSeparate(t *testing.T) {
+	guard := New(NewMemoryStore())
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i <= guard.ResetAccount.FreeAttempts; i++ {
+		wait, err := guard.ReservePasswordReset(ctx, "user@example.com", "203.0.113.5", now)
+		if err != nil || wait > 0 {
+			t.Fatalf("reset %d: wait = %s, err = %v", i+1, wait, err)
+		}
+	}
+	if wait, _ := guard.ReservePasswordReset(ctx, "USER@example.com ", "203.0.113.6", now); wait == 0 {
+		t.Fatal("reset is not throttled by email")
+	}
+
+	result, err := guard.Reserve(ctx, "user@example.com", "203.0.113.5", now)
+	if err != nil || result.RetryAfter > 0 {
+		t.Fatalf("login: retry after %s, err = %v", result.RetryAfter, err)
+	}
+}
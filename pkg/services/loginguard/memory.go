@@ -0,0 +1,76 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	counter
+	resetAfter time.Duration
+}
+
+// MemoryStore хранит счетчики в памяти процесса.
+// Подходит для одного экземпляра приложения, при перезапуске счетчики теряются.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	cleaned time.Time
+}
+
+// NewMemoryStore создает хранилище счетчиков в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+// cleanup удаляет устаревшие счетчики, чтобы память не росла от перебора адресов
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.cleaned) < time.Minute {
+		return
+	}
+	s.cleaned = now
+	for key, entry := range s.entries {
+		if now.After(entry.lockedUntil) && now.Sub(entry.lastFailure) > entry.resetAfter {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Reserve проверяет счетчик key и учитывает попытку
+func (s *MemoryStore) Reserve(ctx context.Context, key string, now time.Time, policy Policy) (time.Duration, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+	entry.resetAfter = policy.ResetAfter
+	wait := policy.reserve(&entry.counter, now)
+
+	return wait, entry.failures, nil
+}
+
+// Release отменяет попытку по счетчику key
+func (s *MemoryStore) Release(ctx context.Context, key string, policy Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.entries[key]; exists {
+		policy.release(&entry.counter)
+	}
+	return nil
+}
+
+// Reset удаляет счетчик key
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
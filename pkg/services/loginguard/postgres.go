@@ -0,0 +1,105 @@
+package loginguard
+
+import (
+	"context"
+	"database/sql"
+	"goland_api/pkg/database"
+	"log"
+	"sync"
+	"time"
+)
+
+// PostgresStore хранит счетчики в таблице login_attempts.
+// Счетчики общие для всех экземпляров приложения и переживают перезапуск.
+type PostgresStore struct {
+	db      *sql.DB
+	mu      sync.Mutex
+	cleaned time.Time
+}
+
+// NewPostgresStore создает хранилище счетчиков в Postgres
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// cleanup удаляет устаревшие счетчики не чаще раза в минуту, чтобы таблица не росла от перебора адресов
+func (s *PostgresStore) cleanup(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.cleaned) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.cleaned = now
+	s.mu.Unlock()
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE expires_at < $1", now); err != nil {
+		log.Println("Ошибка при удалении устаревших счетчиков попыток входа", err)
+	}
+}
+
+// update изменяет счетчик key функцией fn в транзакции. Строка счетчика блокируется,
+// поэтому параллельные запросы по одному счетчику выполняются по очереди.
+// Срок хранения счетчика пересчитывается по правилам policy.
+func (s *PostgresStore) update(ctx context.Context, key string, now time.Time, policy Policy, fn func(c *counter)) (counter, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var c counter
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return c, err
+	}
+	// После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO login_attempts (key, failures, last_failure_at, expires_at) VALUES ($1, 0, $2, $3) "+
+		"ON CONFLICT (key) DO NOTHING", key, now, now.Add(policy.ResetAfter))
+	if err != nil {
+		return c, err
+	}
+
+	var lockedUntil sql.NullTime
+	err = tx.QueryRowContext(ctx, "SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1 FOR UPDATE", key).
+		Scan(&c.failures, &c.lastFailure, &lockedUntil)
+	if err != nil {
+		return c, err
+	}
+	c.lockedUntil = lockedUntil.Time
+
+	fn(&c)
+
+	lockedUntil = sql.NullTime{Time: c.lockedUntil, Valid: !c.lockedUntil.IsZero()}
+	_, err = tx.ExecContext(ctx, "UPDATE login_attempts SET failures = $1, last_failure_at = $2, locked_until = $3, expires_at = $4 WHERE key = $5",
+		c.failures, c.lastFailure, lockedUntil, policy.expiresAt(c), key)
+	if err != nil {
+		return c, err
+	}
+	return c, tx.Commit()
+}
+
+// Reserve проверяет счетчик key и учитывает попытку
+func (s *PostgresStore) Reserve(ctx context.Context, key string, now time.Time, policy Policy) (time.Duration, int, error) {
+	s.cleanup(ctx, now)
+
+	var wait time.Duration
+	c, err := s.update(ctx, key, now, policy, func(c *counter) {
+		wait = policy.reserve(c, now)
+	})
+	return wait, c.failures, err
+}
+
+// Release отменяет попытку по счетчику key
+func (s *PostgresStore) Release(ctx context.Context, key string, policy Policy) error {
+	_, err := s.update(ctx, key, time.Now(), policy, policy.release)
+	return err
+}
+
+// Reset удаляет счетчик key
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE key = $1", key)
+	return err
+}
@@ -0,0 +1,59 @@
+package loginguard
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"goland_api/pkg/database"
+	"goland_api/pkg/database/dbtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Устаревшие счетчики удаляются не чаще раза в минуту, срок хранения зависит от правил счетчика
+func TestPostgresStoreDeletesExpiredCounters(t *testing.T) {
+	policy := Policy{FreeAttempts: 1, BaseDelay: time.Hour, MaxDelay: 2 * time.Hour, ResetAfter: 30 * time.Minute}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	var deletes []time.Time
+	var expiresAt []time.Time
+	var failures int64
+	lastFailure := now
+	var lockedUntil driver.Value
+	dbtest.Open(t, func(query string, args []driver.Value) (dbtest.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "DELETE FROM login_attempts WHERE expires_at"):
+			deletes = append(deletes, args[0].(time.Time))
+			return dbtest.Result{}, nil
+		case strings.HasPrefix(query, "INSERT INTO login_attempts"):
+			return dbtest.Result{}, nil
+		case strings.HasPrefix(query, "SELECT failures"):
+			return dbtest.Result{Rows: [][]driver.Value{{failures, lastFailure, lockedUntil}}}, nil
+		case strings.HasPrefix(query, "UPDATE login_attempts"):
+			failures, lastFailure, lockedUntil = args[0].(int64), args[1].(time.Time), args[2]
+			expiresAt = append(expiresAt, args[3].(time.Time))
+			return dbtest.Result{RowsAffected: 1}, nil
+		}
+		return dbtest.Result{}, fmt.Errorf("unexpected query: %s", query)
+	})
+	store := NewPostgresStore(database.DB)
+	ctx := context.Background()
+
+	for _, at := range []time.Time{now, now.Add(30 * time.Second), now.Add(2 * time.Minute)} {
+		if _, _, err := store.Reserve(ctx, "ip:203.0.113.5", at, policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(deletes) != 2 || !deletes[0].Equal(now) || !deletes[1].Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("cleanups at %v, want at %v and %v", deletes, now, now.Add(2*time.Minute))
+	}
+	// Без задержки счетчик хранится ResetAfter после попытки, с задержкой - до ее окончания
+	want := []time.Time{now.Add(policy.ResetAfter), now.Add(30 * time.Second).Add(policy.BaseDelay)}
+	for i, expected := range want {
+		if !expiresAt[i].Equal(expected) {
+			t.Fatalf("expires_at[%d] = %s, want %s", i, expiresAt[i], expected)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"log"
+	"os"
+)
+
+// Message - письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer - способ отправки писем
+type Mailer interface {
+	Send(message Message) error
+}
+
+// Default - глобальный отправитель писем, настраивается в Init
+var Default Mailer = NewLogMailer("")
+
+// Init выбирает способ отправки писем по переменной окружения MAIL_DRIVER:
+// smtp - отправка через SMTP-сервер, log - запись писем в журнал или в файл MAIL_LOG_FILE (по умолчанию).
+func Init() {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		Default = NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USER"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+		log.Println("Письма отправляются через SMTP", os.Getenv("SMTP_HOST"))
+	default:
+		Default = NewLogMailer(os.Getenv("MAIL_LOG_FILE"))
+		log.Println("Письма записываются в журнал")
+	}
+}
+
+// Send отправляет письмо через глобального отправителя
+func Send(message Message) error {
+	return Default.Send(message)
+}
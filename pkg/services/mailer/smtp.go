@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer отправляет письма через SMTP-сервер
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer создает отправителя писем через SMTP.
+// Если username не указан, письма отправляются без авторизации.
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send отправляет письмо
+func (m *SMTPMailer) Send(message Message) error {
+	if m.host == "" || m.from == "" {
+		return fmt.Errorf("Не заданы SMTP_HOST или MAIL_FROM")
+	}
+	// Защита от подстановки заголовков через адрес или тему
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("Недопустимые символы в адресе или теме письма")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	headers := []string{
+		"From: " + m.from,
+		"To: " + message.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	return smtp.SendMail(net.JoinHostPort(m.host, m.port), auth, m.from, []string{message.To}, []byte(body))
+}
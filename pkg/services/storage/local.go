@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Каталог локального хранилища по умолчанию
+const defaultLocalRoot = "./public/upload"
+
+// Адрес, по которому API отдает файлы локального хранилища
+const defaultLocalURL = "/api/media"
+
+// LocalStorage хранит файлы в каталоге на диске.
+// Файлы отдаются через API, поэтому ссылки на них не ограничены по времени.
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage создает хранилище в каталоге root, ссылки на файлы строятся от baseURL
+func NewLocalStorage(root string, baseURL string) *LocalStorage {
+	return &LocalStorage{root: root, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// init создает каталог хранилища, если его нет
+func (s *LocalStorage) init() error {
+	return os.MkdirAll(s.root, 0755)
+}
+
+// path возвращает путь к файлу key. Ключ не может выходить за пределы каталога хранилища.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+// Put сохраняет файл. Файл записывается во временный файл и переименовывается после записи,
+// поэтому прерванная загрузка не оставляет недописанных файлов.
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get открывает файл для чтения
+func (s *LocalStorage) Get(ctx context.Context, key string) (Object, ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, ObjectInfo{}, err
+	}
+	return file, s.info(key, stat), nil
+}
+
+// Delete удаляет файл
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Stat возвращает сведения о файле
+func (s *LocalStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return s.info(key, stat), nil
+}
+
+// SignedURL возвращает ссылку на файл в API. ttl не используется.
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.baseURL + "/" + key, nil
+}
+
+// info собирает сведения о файле. Тип содержимого определяется по расширению ключа.
+func (s *LocalStorage) info(key string, stat os.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     stat.ModTime(),
+	}
+}
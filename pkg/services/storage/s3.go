@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Регион по умолчанию, его же использует MinIO
+const defaultS3Region = "us-east-1"
+
+// S3Config - параметры подключения к S3-совместимому хранилищу
+type S3Config struct {
+	Endpoint  string // Адрес сервера без схемы, например minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Storage хранит файлы в бакете S3-совместимого хранилища (AWS S3, MinIO и т.п.)
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage подключается к хранилищу и создает бакет, если его нет
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required")
+	}
+	// С известным регионом подписанные ссылки формируются без запроса к серверу
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Storage{client: client, bucket: config.Bucket}, nil
+}
+
+// Put сохраняет файл
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get открывает файл для чтения. Содержимое загружается по мере чтения.
+func (s *S3Storage) Get(ctx context.Context, key string) (Object, ObjectInfo, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, s.error(err)
+	}
+
+	// GetObject не обращается к серверу, отсутствие файла выясняется при первом запросе
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, ObjectInfo{}, s.error(err)
+	}
+	return object, s.info(stat), nil
+}
+
+// Delete удаляет файл
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Stat возвращает сведения о файле
+func (s *S3Storage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, s.error(err)
+	}
+	return s.info(stat), nil
+}
+
+// SignedURL возвращает подписанную ссылку на скачивание файла напрямую из хранилища
+func (s *S3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// error заменяет ответ хранилища об отсутствии файла на ErrNotFound
+func (s *S3Storage) error(err error) error {
+	if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// info преобразует сведения о файле из ответа хранилища
+func (s *S3Storage) info(stat minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         stat.Key,
+		Size:        stat.Size,
+		ContentType: stat.ContentType,
+		ModTime:     stat.LastModified,
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNotFound - файл не найден в хранилище
+var ErrNotFound = errors.New("Файл не найден")
+
+// ObjectInfo - сведения о файле в хранилище
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Object - содержимое файла, открытое для чтения
+type Object interface {
+	io.ReadSeekCloser
+}
+
+// Storage - хранилище файлов. Файлы адресуются ключом, уникальным в пределах хранилища.
+type Storage interface {
+	// Put сохраняет файл key из body. size - размер файла или -1, если он неизвестен.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get открывает файл key для чтения
+	Get(ctx context.Context, key string) (Object, ObjectInfo, error)
+	// Delete удаляет файл key. Удаление отсутствующего файла не считается ошибкой.
+	Delete(ctx context.Context, key string) error
+	// Stat возвращает сведения о файле key
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// SignedURL возвращает ссылку для скачивания файла key, действующую не меньше ttl
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Время действия ссылок на файлы по умолчанию
+const defaultURLTTL = time.Hour
+
+// URLTTL - время действия ссылок на файлы, которые возвращает URL
+var URLTTL = defaultURLTTL
+
+// Default - глобальное хранилище файлов, настраивается в Init
+var Default Storage = NewLocalStorage(defaultLocalRoot, defaultLocalURL)
+
+// Init выбирает хранилище по переменной окружения STORAGE_DRIVER:
+// s3 - S3-совместимое хранилище (S3_ENDPOINT, S3_BUCKET и др.), local - каталог STORAGE_LOCAL_ROOT (по умолчанию).
+// Время действия ссылок на файлы задается переменной STORAGE_URL_TTL.
+func Init() {
+	if value := os.Getenv("STORAGE_URL_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			log.Fatal("Invalid STORAGE_URL_TTL: ", value)
+		}
+		URLTTL = ttl
+	}
+
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+		if err != nil {
+			log.Fatal("Failed to init S3 storage: ", err)
+		}
+		Default = s3
+		log.Println("Файлы хранятся в S3", os.Getenv("S3_ENDPOINT"), os.Getenv("S3_BUCKET"))
+	default:
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = defaultLocalRoot
+		}
+		local := NewLocalStorage(root, defaultLocalURL)
+		if err := local.init(); err != nil {
+			log.Fatal("Failed to init local storage: ", err)
+		}
+		Default = local
+		log.Println("Файлы хранятся в каталоге", root)
+	}
+}
+
+// URL возвращает ссылку на файл key в глобальном хранилище
+func URL(ctx context.Context, key string) (string, error) {
+	return Default.SignedURL(ctx, key, URLTTL)
+}
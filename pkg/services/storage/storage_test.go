@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRoundTrip проверяет, что файл key сохраняется, читается, описывается и удаляется
+func testRoundTrip(t *testing.T, s Storage, key string) {
+	t.Helper()
+	ctx := context.Background()
+	content := []byte("content of " + key)
+
+	if err := s.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+		t.Fatal("put: ", err)
+	}
+
+	object, info, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatal("get: ", err)
+	}
+	read, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		t.Fatal("read: ", err)
+	}
+	if !bytes.Equal(read, content) {
+		t.Fatalf("get = %q, want %q", read, content)
+	}
+	if info.Size != int64(len(content)) {
+		t.Fatalf("get size = %d, want %d", info.Size, len(content))
+	}
+
+	stat, err := s.Stat(ctx, key)
+	if err != nil {
+		t.Fatal("stat: ", err)
+	}
+	if stat.Size != int64(len(content)) {
+		t.Fatalf("stat size = %d, want %d", stat.Size, len(content))
+	}
+
+	if _, err := s.SignedURL(ctx, key, time.Minute); err != nil {
+		t.Fatal("signed url: ", err)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal("delete: ", err)
+	}
+	if _, err := s.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("stat after delete: err = %v, want ErrNotFound", err)
+	}
+	if _, _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get after delete: err = %v, want ErrNotFound", err)
+	}
+	// Повторное удаление не считается ошибкой
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal("delete missing: ", err)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "public", "upload")
+	s := NewLocalStorage(root, "/api/media/")
+	if err := s.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"image.png", "2026/10/image.png", "../../etc/passwd"} {
+		t.Run(key, func(t *testing.T) {
+			testRoundTrip(t, s, key)
+		})
+	}
+
+	// Тип содержимого определяется по расширению ключа, файлы отдаются через API
+	ctx := context.Background()
+	if err := s.Put(ctx, "2026/10/image.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := s.Stat(ctx, "2026/10/image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.ContentType != "image/png" {
+		t.Fatalf("content type = %s, want image/png", stat.ContentType)
+	}
+	url, err := s.SignedURL(ctx, "2026/10/image.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "/api/media/2026/10/image.png" {
+		t.Fatalf("url = %s, want /api/media/2026/10/image.png", url)
+	}
+}
+
+// Ключ не выходит за пределы каталога хранилища
+func TestLocalStorageKeepsKeysInsideRoot(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "public", "upload")
+	s := NewLocalStorage(root, "/api/media")
+	if err := s.init(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "../../etc/passwd", strings.NewReader("root"), 4, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "etc", "passwd")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file written outside of root: err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "etc", "passwd")); err != nil {
+		t.Fatal("file is not written inside root: ", err)
+	}
+
+	// Ключ, указывающий на сам каталог хранилища
+	for _, key := range []string{"", "/", "..", "../.."} {
+		if err := s.Put(ctx, key, strings.NewReader("root"), 4, "text/plain"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("put %q: err = %v, want ErrNotFound", key, err)
+		}
+		if err := s.Delete(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("delete %q: err = %v, want ErrNotFound", key, err)
+		}
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Fatal("root is removed: ", err)
+	}
+}
+
+// Проверка S3Storage на реальном хранилище (например, MinIO из docker-compose).
+// Выполняется, если задана переменная S3_ENDPOINT; бакет S3_BUCKET создается при необходимости.
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		bucket = "storage-test"
+	}
+	s, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    bucket,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := "storage-test/" + time.Now().Format("20060102150405.000000000") + "/"
+	for _, key := range []string{prefix + "image.png", prefix + "2026/10/image.png"} {
+		t.Run(key, func(t *testing.T) {
+			testRoundTrip(t, s, key)
+		})
+	}
+
+	// По подписанной ссылке файл скачивается без авторизации
+	ctx := context.Background()
+	key := prefix + "signed.png"
+	if err := s.Put(ctx, key, strings.NewReader("signed"), 6, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Delete(ctx, key)
+
+	url, err := s.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || string(body) != "signed" {
+		t.Fatalf("signed url: status = %d, body = %q", response.StatusCode, body)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "image/png" {
+		t.Fatalf("content type = %s, want image/png", contentType)
+	}
+}